@@ -0,0 +1,19 @@
+# Pause writes to a target which keeps failing, instead of every batch running its own retry loop against it.
+# Each target gets its own breaker. Once open, writes wait for 'open_duration_ms', then a single probe batch is sent:
+# the breaker closes if it succeeds, and opens again if it fails.
+circuit_breaker {
+  # Whether to use circuit breakers (default: false)
+  enabled = true
+
+  # Fraction of failed writes within the window which opens the breaker (default: 0.5)
+  failure_rate = 0.8
+
+  # Minimum number of writes within the window before the failure rate is considered (default: 10)
+  min_requests = 20
+
+  # Period over which writes are counted, in milliseconds (default: 60000)
+  window_ms = 30000
+
+  # How long the breaker stays open before sending a probe batch, in milliseconds (default: 30000)
+  open_duration_ms = 10000
+}
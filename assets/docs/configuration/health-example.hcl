@@ -0,0 +1,21 @@
+# Serve liveness and readiness probes over HTTP, e.g. for Kubernetes.
+# /healthz fails when the app needs restarting. /readyz fails until targets are open,
+# while webhook monitoring is alerting on a setup error, and when the pipeline has been idle for too long.
+health {
+  # Serve the health endpoints (default: false)
+  enabled = true
+
+  # Address to listen on (default: ":8080")
+  address = ":9000"
+
+  # Fail readiness when no message was delivered or acked for this long, in milliseconds (default: 0, disabled)
+  activity_window_ms = 300000
+
+  # Serve the pprof profiling endpoints under /debug/pprof/, apart from the health endpoints.
+  # The `--profile` flag enables this too (default: false)
+  enable_profiling = true
+
+  # Address to serve the profiling endpoints on, which must not clash with address.
+  # Profiles expose the memory of the app, so keep it reachable locally only (default: "localhost:6060")
+  profiling_address = "localhost:6061"
+}
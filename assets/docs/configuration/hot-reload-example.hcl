@@ -0,0 +1,10 @@
+# Reload the transformations without restarting the process, on SIGHUP or when the config file changes.
+# Only changes to `transform` blocks and the JavaScript files they reference can be reloaded,
+# a change to any other block is rejected and the running configuration is kept.
+hot_reload {
+  # Watch for and apply changes to the transformations (default: false)
+  enabled = true
+
+  # How often to check the config file and scripts for changes, in milliseconds. 0 only reloads on SIGHUP (default: 5000)
+  watch_interval_ms = 10000
+}
@@ -0,0 +1,23 @@
+stats_receiver {
+  use "otlp" {
+    # OTLP/HTTP metrics endpoint of the collector (default: "http://localhost:4318/v1/metrics")
+    endpoint     = "https://otel-collector.acme.com:4318/v1/metrics"
+
+    # Headers to send with every export request (default: {})
+    headers      = {
+      "Authorization" = "Bearer myToken"
+    }
+
+    # Service name reported on the metrics (default: "snowbridge")
+    service_name = "event-forwarding"
+
+    # Escaped JSON string with tags to add as attributes on every metric (default: "{}")
+    tags         = "{\"aKey\": \"aValue\"}"
+
+    # Timeout (seconds) for each export (default: 10)
+    timeout_sec  = 5
+  }
+
+  # Aggregation time window (seconds) for metrics being collected, metrics are exported once per window (default: 60)
+  buffer_sec  = 20
+}
@@ -0,0 +1,18 @@
+stats_receiver {
+  use "prometheus" {
+    # Address the scrape endpoint listens on (default: ":9090")
+    address   = "0.0.0.0:9090"
+
+    # Path the metrics are served on (default: "/metrics")
+    path      = "/metrics"
+
+    # Prefix added to every metric name (default: "snowbridge")
+    namespace = "snowbridge"
+
+    # Escaped JSON string with tags to add as constant labels on every metric (default: "{}")
+    tags      = "{\"aKey\": \"aValue\"}"
+  }
+
+  # Aggregation time window (seconds) for metrics being collected (default: 60)
+  buffer_sec  = 20
+}
@@ -117,6 +117,33 @@ retry {
   }
 }
 
+# Spill batches to disk when a target is still failing after retries, and replay them once it recovers
+spill {
+  path = "/var/lib/snowbridge/spill"
+}
+
+# Serve liveness and readiness probes on /healthz and /readyz
+health {
+  enabled = true
+}
+
+# Export a trace of one in every hundred messages to a local OpenTelemetry collector
+tracing {
+  enabled      = true
+  sample_ratio = 0.01
+}
+
+# Reload changes to the transformations without a restart
+hot_reload {
+  enabled = true
+}
+
+# Wait up to 30 seconds for in-flight batches on shutdown, then spill what is left to disk
+shutdown {
+  drain_timeout_ms = 30000
+  policy           = "spill"
+}
+
 metrics {
   # Optional toggle for E2E latency (difference between Snowplow collector timestamp and target write timestamp)
   enable_e2e_latency = true
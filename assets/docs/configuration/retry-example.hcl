@@ -8,6 +8,16 @@ retry {
 
     # Whether to send the data to invalid after max retries (default: false)
     invalid_after_max = true
+
+    # How the delay grows between retries: "fixed", "exponential" or "exponential_jitter" (default: "exponential")
+    # "exponential_jitter" waits a random delay up to the exponential one, which spreads out retries across replicas
+    backoff = "exponential_jitter"
+
+    # Maximum delay between two retries, 0 means no cap (default: 0)
+    max_delay_ms = 60000
+
+    # Stop retrying once this much time has passed since the first retry, 0 means no deadline (default: 0)
+    deadline_ms = 300000
   }
   setup {
     # Initial delay (before first retry) for setup errors (default: 20000)
@@ -22,5 +32,8 @@ retry {
 
     # Maximum number of retries for throttle errors (default: 5)
     max_attempts = 3
+
+    # Throttled requests are retried at the same pace
+    backoff = "fixed"
   }
 }
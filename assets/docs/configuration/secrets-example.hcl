@@ -0,0 +1,21 @@
+# Secrets can be read from files, such as Docker or Kubernetes secrets, with `file`.
+# Trailing line breaks are removed.
+#
+# Secrets can also be read with `secret`, from a reference in one of the forms:
+#   aws-sm://<secret name or ARN>                   - AWS Secrets Manager
+#   gcp-sm://projects/<project>/secrets/<secret>    - GCP Secret Manager, the latest version unless one is given
+#   file://<path>                                   - a file, as with `file`
+# Add `#<key>` to an aws-sm or gcp-sm reference to read a key of a secret holding a JSON object.
+# File paths are taken as they are, `#` included.
+#
+# Values read from secrets are never printed or logged, and OAuth2 credentials are read again when they are rejected.
+target {
+  use "http" {
+    url                  = "https://acme.com/webhook"
+    oauth2_client_id     = "snowbridge"
+    oauth2_client_secret = file(env.CLIENT_SECRET_FILE)
+    # Or read a key of a JSON secret, e.g. secret("aws-sm://snowbridge/oauth2#refresh_token")
+    oauth2_refresh_token = secret("file://${env.REFRESH_TOKEN_FILE}")
+    oauth2_token_url     = "https://acme.com/oauth2/token"
+  }
+}
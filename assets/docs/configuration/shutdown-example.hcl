@@ -0,0 +1,12 @@
+# Control how data is drained when the app is asked to shut down, e.g. on deploy.
+# Messages still in flight when the app quits are redelivered by the source, which results in duplicates.
+shutdown {
+  # How long to wait for buffered and in-flight batches once shutdown starts, in milliseconds (default: 5000)
+  drain_timeout_ms = 60000
+
+  # What to do with data which isn't written yet (default: "flush"):
+  # - "flush": keep writing buffered and in-flight batches until the drain timeout
+  # - "nack": stop writing, nack buffered batches straight away and in-flight ones left at the drain timeout, so the source redelivers them quickly
+  # - "spill": write buffered batches, and in-flight ones left at the drain timeout, to the spill log and ack them. Requires `spill.path` to be set.
+  policy = "nack"
+}
@@ -0,0 +1,45 @@
+# Extended configuration for the generator as a source (all options)
+# The generator emits synthetic messages, to benchmark transformations and targets
+
+source {
+  use "generator" {
+    # Payload to generate: 'enriched' for Snowplow enriched events in TSV, 'json' to render
+    # json_template, or 'file' to replay the lines of file (default: enriched)
+    format = "enriched"
+
+    # Requested throughput, 0 to generate as fast as messages are consumed (default: 1000)
+    messages_per_second = 5000
+
+    # Number of messages after which the source stops, 0 to never stop (default: 0)
+    count = 1000000
+
+    # Seed of the random values, to generate the same payloads on every run (default: 0, random)
+    seed = 42
+
+    # How often to log the achieved against the requested throughput (default: 10)
+    report_interval_sec = 5
+
+    # Templates use Go templating, with the functions uuid, randInt min max, randString length,
+    # randChoice options... and now
+
+    # App IDs picked from at random for every enriched event (default: ["generator"])
+    app_ids = ["website", "mobile-app"]
+
+    # Self-describing JSON templates of the contexts, each of which is attached to half the enriched events
+    contexts = [
+      "{\"schema\":\"iglu:com.acme/user/jsonschema/1-0-0\",\"data\":{\"id\":\"{{ uuid }}\",\"plan\":\"{{ randChoice \"free\" \"pro\" }}\"}}"
+    ]
+
+    # Self-describing JSON templates of the unstruct events, one of which is picked for every enriched event.
+    # Page views are generated when empty
+    unstruct_events = [
+      "{\"schema\":\"iglu:com.acme/purchase/jsonschema/1-0-0\",\"data\":{\"orderId\":\"{{ uuid }}\",\"total\":{{ randInt 1 500 }}}}"
+    ]
+
+    # Template of the payloads of the 'json' format
+    json_template = "{\"id\":\"{{ uuid }}\",\"createdAt\":\"{{ now }}\",\"value\":{{ randInt 0 1000 }}}"
+
+    # Sample file of the 'file' format, replayed line by line in a loop
+    file = "release_test/input.txt"
+  }
+}
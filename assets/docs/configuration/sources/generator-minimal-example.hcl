@@ -0,0 +1,6 @@
+# Minimal configuration for the generator as a source (only required options)
+# Generates Snowplow enriched events at 1000 messages per second until stopped
+
+source {
+  use "generator" {}
+}
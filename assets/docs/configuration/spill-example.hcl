@@ -0,0 +1,15 @@
+# Spill batches to disk instead of crashing when a target is still failing after retries.
+# Spilled data is acked at the source, and replayed into the target once it accepts writes again.
+spill {
+  # Directory where spilled batches are written, one sub-directory per target. Spilling is disabled if not set.
+  path = "/var/lib/snowbridge/spill"
+
+  # Maximum size of spilled data per target, in bytes. Once reached, failures are unrecoverable again (default: 1073741824)
+  max_bytes = 536870912
+
+  # Optional. Base64 encoded AES key (16, 24 or 32 bytes) used to encrypt spilled data at rest
+  encryption_key = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+  # How often to try replaying spilled data into the target, in milliseconds (default: 10000)
+  drain_interval_ms = 30000
+}
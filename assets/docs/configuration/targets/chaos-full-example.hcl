@@ -0,0 +1,36 @@
+# Extended configuration for Chaos as a target (all options)
+# The chaos target wraps another target, and injects failures into its writes to test retries and alerting.
+# It must not be used in production.
+
+target {
+  use "chaos" {
+    # Target to wrap, configured as it would be on its own. Batching is configured on the wrapped target.
+    target {
+      use "http" {
+        url = "https://acme.com/x"
+      }
+    }
+
+    # Milliseconds of latency added to a write (default: 0)
+    latency_ms                  = 500
+    # Probability of adding latency to a write (default: 0)
+    latency_probability         = 0.1
+
+    # Probabilities of failing a whole batch without writing it to the wrapped target, with
+    # a transient error, a throttle error, a setup error or a fatal error.
+    # They must not add up to more than 1 (default: 0)
+    transient_error_probability = 0.05
+    throttle_error_probability  = 0.02
+    setup_error_probability     = 0.01
+    fatal_error_probability     = 0.001
+
+    # Probability of failing about half of a batch with a transient error (default: 0)
+    partial_failure_probability = 0.05
+
+    # Probability of each message being returned as invalid (default: 0)
+    invalid_probability         = 0.01
+
+    # Seed making the injected failures reproducible, they differ on every run when not set (default: 0)
+    seed                        = 42
+  }
+}
@@ -0,0 +1,12 @@
+# Minimal configuration for Chaos as a target (only required options)
+# The chaos target wraps another target, and injects failures into its writes to test retries and alerting.
+# It must not be used in production.
+
+target {
+  use "chaos" {
+    # Target to wrap, configured as it would be on its own
+    target {
+      use "stdout" {}
+    }
+  }
+}
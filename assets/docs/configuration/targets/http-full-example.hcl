@@ -13,6 +13,21 @@ target {
       max_concurrent_batches = 2
       # Milliseconds between flushes of messages (default: 200)
       flush_period_millis    = 100
+      # Adjust concurrency between min_concurrent_batches and max_concurrent_batches, based on request latency and throttling (default: false)
+      adaptive_concurrency   = true
+      # Concurrency to start from, and never go below, when adaptive_concurrency is enabled (default: 1)
+      min_concurrent_batches = 1
+      # Shard messages into this many lanes by partition key, each with at most one batch in flight, so that messages
+      # with the same partition key are delivered in order. A failing batch only holds up its own lane. (default: 0, disabled)
+      # Order isn't guaranteed for messages spilled to disk, nor within a batch the target accepts only partially.
+      ordered_lanes          = 16
+      # Optional. Caps the throughput of the target, writes wait rather than error once the limit is reached
+      rate_limit {
+        # Maximum messages written per second (default: no limit)
+        messages_per_second = 500
+        # Maximum bytes of message data written per second (default: no limit)
+        bytes_per_second    = 1048576
+      }
     }
     # URL endpoint
     url                        = "https://acme.com/x"
@@ -77,6 +92,15 @@ target {
     # Optional. Used in combination with `include_timing_headers` to precalculate rejection timestamp. 
     rejection_threshold_in_millis = 100
 
+    # Optional. When a response matches a throttle rule, the retry delay it suggests is used instead of the configured throttle backoff.
+    # The standard `Retry-After` and `X-RateLimit-Reset` headers are always honoured; values may be seconds, a unix timestamp or an HTTP date.
+    # Suggested delays are capped by the throttle `max_delay_ms`, or by 5 minutes when it isn't set.
+    # This names an additional header carrying the hint, which takes precedence over the standard ones.
+    retry_after_header = "X-Backoff-Seconds"
+
+    # Optional. Dot-separated path to a retry hint in a JSON response body, e.g. {"error": {"retry_after": 30}}. Takes precedence over headers.
+    retry_after_body_path = "error.retry_after"
+
     # Optional HTTP response rules which are used to match HTTP response code/body and categorize it as either invalid data or target setup error.
     # Rules are evaluated in order as declared. First matching rule determines the error type.
     response_rules {
@@ -0,0 +1,22 @@
+# Every transformed message is written to each of the configured targets.
+# A message is only acked once it was written to all of them.
+
+target {
+  use "kafka" {
+    # Kafka broker connectinon string
+    brokers    = "my-kafka-connection-string"
+
+    # Kafka topic name
+    topic_name = "snowplow-enriched-good"
+  }
+}
+
+target {
+  # Name identifying the target in logs and metrics, it must be unique across targets (default: name of the target used)
+  name = "events_api"
+
+  use "http" {
+    # URL endpoint
+    url = "https://acme.com/x"
+  }
+}
@@ -0,0 +1,21 @@
+# Export a trace of every message to an OpenTelemetry collector, with spans for the source pull,
+# each transformation, batching and the target request.
+# A W3C traceparent received by the http source, or in kafka record headers, becomes the parent of the message's trace.
+tracing {
+  # Export traces (default: false)
+  enabled      = true
+
+  # OTLP/HTTP traces endpoint of the collector (default: "http://localhost:4318/v1/traces")
+  endpoint     = "https://otel-collector.acme.com:4318/v1/traces"
+
+  # Headers to send with every export request (default: {})
+  headers      = {
+    "Authorization" = "Bearer myToken"
+  }
+
+  # Service name reported on the traces (default: "snowbridge")
+  service_name = "event-forwarding"
+
+  # Fraction of messages without an inbound trace parent which are traced (default: 1)
+  sample_ratio = 0.1
+}
@@ -16,5 +16,9 @@ JQEOT
     # Optional, may be used when the input is a Snowplow enriched TSV. 
     # This will transform the data so that the root '.' JQ field contains JSON object representation of the event - with keys as returned by the Snowplow Analytics SDK.
     snowplow_mode = true
+
+    # Optional. JQ command run on the input data to pick the target the result is written to.
+    # The output must be a string matching a target 'name', or null to write to every target.
+    route_command = ".app_id"
   }
 }
@@ -0,0 +1,13 @@
+transform {
+  use "jqRoute" {
+    # Full JQ command which will be used to pick the target for input data. The output must be a string matching a target 'name', or null to write to every target.
+    jq_command = "if .event_name == \"page_view\" then \"pageviews\" else \"events\" end"
+
+    # Optional. Timeout for execution of the script, in milliseconds.
+    timeout_ms = 800
+
+    # Optional, may be used when the input is a Snowplow enriched TSV. 
+    # This will transform the data so that the root '.' JQ  field contains JSON object representation of the event - with keys as returned by the Snowplow Analytics SDK.
+    snowplow_mode = true
+  }
+}
@@ -0,0 +1,6 @@
+transform {
+  use "jqRoute" {
+    # Full JQ command which will be used to pick the target for input data. The output must be a string matching a target 'name', or null to write to every target.
+    jq_command = ".app_id"
+  }
+}
@@ -0,0 +1,9 @@
+function main(x) {
+    var data = JSON.parse(x.Data);
+
+    if (data.event_name == "page_view") {
+      x.Route = "pageviews";
+    }
+
+    return x;
+  }
@@ -0,0 +1,8 @@
+target {
+  use "kafka" {}
+}
+
+target {
+  name = "api"
+  use "http" {}
+}
@@ -15,17 +15,17 @@ import (
 	"context"
 	"os"
 	"os/signal"
-	"sync"
 	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 
-	// pprof imported for the side effect of registering its HTTP handlers
-	_ "net/http/pprof"
-
 	"github.com/snowplow/snowbridge/v5/cmd"
 	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/health"
+	"github.com/snowplow/snowbridge/v5/pkg/monitoring"
+	"github.com/snowplow/snowbridge/v5/pkg/observer"
+	"github.com/snowplow/snowbridge/v5/pkg/router"
 	"github.com/snowplow/snowbridge/v5/pkg/source/sourceconfig"
 	"github.com/snowplow/snowbridge/v5/pkg/target/targetconfig"
 	"github.com/snowplow/snowbridge/v5/pkg/telemetry"
@@ -34,9 +34,7 @@ import (
 
 // RunApp runs application
 func RunApp(cfg *config.Config, supportedTransformations []config.ConfigurationPair) error {
-
-	// Create channels
-	invalidChannel := make(chan *invalidMessages)
+	logConfig(cfg, supportedTransformations)
 
 	// First thing is to spin up webhookMonitoring, so we can start alerting as soon as possible
 	webhookMonitoring, alertChan, err := cfg.GetWebhookMonitoring(cmd.AppName, cmd.AppVersion)
@@ -48,6 +46,11 @@ func RunApp(cfg *config.Config, supportedTransformations []config.ConfigurationP
 		webhookMonitoring.Start()
 	}
 
+	// The admin servers start last, fail before anything else does if they would clash
+	if err := cfg.Data.Health.Validate(); err != nil {
+		return err
+	}
+
 	tags, err := cfg.GetTags()
 	if err != nil {
 		return err
@@ -60,17 +63,27 @@ func RunApp(cfg *config.Config, supportedTransformations []config.ConfigurationP
 	obs.Start()
 	defer obs.Stop()
 
-	source, sourceOutput, err := sourceconfig.GetSource(cfg, obs)
+	tracer, err := cfg.GetTracer(cmd.AppVersion)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err := tracer.Stop(); err != nil {
+			log.WithError(err).Error("Failed to export remaining traces")
+		}
+	}()
 
-	transformer, transformationOutput, err := transformconfig.GetTransformer(cfg, supportedTransformations, sourceOutput, obs)
+	source, err := sourceconfig.NewSource(cfg, obs)
 	if err != nil {
 		return err
 	}
 
-	target, err := targetconfig.GetTarget(cfg.Data.Target, cfg.Decoder)
+	transformation, err := transformconfig.GetTransformations(cfg, supportedTransformations)
+	if err != nil {
+		return err
+	}
+
+	targets, err := targetconfig.GetTargets(cfg.Data.Targets, cfg.Decoder)
 	if err != nil {
 		return err
 	}
@@ -91,65 +104,84 @@ func RunApp(cfg *config.Config, supportedTransformations []config.ConfigurationP
 		return err
 	}
 
+	workerPool := 0
+	if cfg.Data.Transform != nil {
+		workerPool = cfg.Data.Transform.WorkerPool
+	}
+
+	pipeline, err := router.NewPipeline(&router.PipelineComponents{
+		Source:         source,
+		Transformation: transformation,
+		WorkerPool:     workerPool,
+		Targets:        targets,
+		FilterTarget:   filterTarget,
+		FailureTarget:  failureTarget,
+		FailureParser:  failureParser,
+		Observer:       obs,
+		Metrics:        router.WithTracing(obs, tracer),
+		AlertChannel:   alertChan,
+		Retry:          cfg.Data.Retry,
+		Spill:          cfg.Data.Spill,
+		Shutdown:       cfg.Data.Shutdown,
+		CircuitBreaker: cfg.Data.CircuitBreaker,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.Data.HotReload.Enabled {
+		configReloader, err := newReloader(os.Getenv("SNOWBRIDGE_CONFIG_FILE"), cfg, supportedTransformations, pipeline.Transformer())
+		if err != nil {
+			return err
+		}
+		pipeline.AddBackground(configReloader.Start)
+	}
+
 	stopTelemetry := telemetry.InitTelemetryWithCollector(cfg)
 	defer stopTelemetry()
 
-	// Build context that is then passed to the source.
-	// Listed OS signals cancel context.
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-
-	// Create Router to orchestrate data flow from transformation to targets
-	router := &Router{
-		transformationOutput: transformationOutput,
-		invalidChannel:       invalidChannel,
-		AlertChannel:         alertChan,
-		cancel:               cancel,
-
-		// Targets (all use targetiface.Target)
-		Target:        target,
-		FilterTarget:  filterTarget,
-		FailureTarget: failureTarget,
-
-		FailureParser: failureParser,
-		metrics:       obs,
-		maxTargetSize: target.GetBatchingConfig().MaxMessageBytes,
-		retryConfig:   cfg.Data.Retry,
-	}
-
-	var wg sync.WaitGroup
-
-	// Start all async components.
-	// If any of them quits naturally, without any error, cancel context to signal we should shut down application.
-	runAsync := func(start func()) {
-		wg.Go(func() {
-			defer cancel()
-			start()
-		})
-	}
-
-	runAsync(func() { source.Start(ctx) })
-	runAsync(transformer.Start)
-	runAsync(router.Start)
-
-	// Wait for context cancellation, might be caused by:
-	// - OS signal
-	// - Component calling cancel() due to fatal error
-	// - Component quits naturally
-	<-ctx.Done()
-
-	log.Info("Starting graceful shutdown. Waiting for app to complete shutdown...")
-	done := make(chan struct{})
-	go func() {
-		wg.Wait()
-		close(done)
-	}()
+	healthServer, err := startHealthServer(cfg.Data.Health, obs, pipeline.Router(), webhookMonitoring)
+	if err != nil {
+		return err
+	}
+	if healthServer != nil {
+		defer healthServer.Stop()
+	}
+
+	if cfg.Data.Health.EnableProfiling {
+		profilingServer := health.NewProfilingServer(cfg.Data.Health.ProfilingAddress)
+		if err := profilingServer.Start(); err != nil {
+			return err
+		}
+		defer profilingServer.Stop()
+	}
 
-	select {
-	case <-done:
-		log.Info("App shutdown completed successfully")
-	case <-time.After(5 * time.Second):
-		log.Warn("Shutdown timed out after 5 seconds, forcing quit...")
+	// Listed OS signals cancel the context the pipeline runs in, starting its graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return pipeline.Run(ctx)
+}
+
+// startHealthServer serves the liveness and readiness probes of the app.
+// It returns nil if health endpoints are not enabled.
+func startHealthServer(cfg *config.HealthConfig, obs *observer.Observer, router *router.Router, webhookMonitoring *monitoring.WebhookMonitoring) (*health.Server, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	server := health.NewServer(cfg.Address)
+
+	server.AddLivenessCheck("observer", obs.Running)
+	server.AddReadinessCheck("targets", router.TargetsOpen)
+	if webhookMonitoring != nil {
+		server.AddReadinessCheck("monitoring", webhookMonitoring.Status)
+	}
+	if cfg.ActivityWindowMs > 0 {
+		server.AddReadinessCheck("activity", health.IdleCheck(time.Duration(cfg.ActivityWindowMs)*time.Millisecond, router.LastActivity))
 	}
 
-	return err
+	if err := server.Start(); err != nil {
+		return nil, err
+	}
+	return server, nil
 }
@@ -0,0 +1,99 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+// PrintConfig writes the effective config read from a config file to out, as HCL or JSON.
+// The defaults of the config and of every component are filled in, and sensitive values are redacted.
+// The default config is printed when no file is given. Secrets are never resolved, as they would only be redacted.
+func PrintConfig(filename string, supportedTransformations []config.ConfigurationPair, format string, out io.Writer) error {
+	if filename == "" {
+		return printConfig(config.NewDefaultConfig(), supportedTransformations, format, out)
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.NewOfflineHclConfig(src, filename)
+	if err != nil {
+		return err
+	}
+
+	return printConfig(cfg, supportedTransformations, format, out)
+}
+
+// printConfig writes the effective config to out, decoding the config of every component to fill in its defaults
+func printConfig(cfg *config.Config, supportedTransformations []config.ConfigurationPair, format string, out io.Writer) error {
+	source, err := sourceconfig.DecodeSourceConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	targets := make([]any, 0, len(cfg.Data.Targets))
+	for _, targetCfg := range cfg.Data.Targets {
+		target, err := targetconfig.DecodeTargetConfig(targetCfg, cfg.Decoder)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+
+	failureTarget, err := targetconfig.DecodeTargetConfig(cfg.Data.FailureTarget, cfg.Decoder)
+	if err != nil {
+		return err
+	}
+
+	filterTarget, err := targetconfig.DecodeTargetConfig(cfg.Data.FilterTarget, cfg.Decoder)
+	if err != nil {
+		return err
+	}
+
+	transformations, err := transformconfig.DecodeTransformationConfigs(cfg, supportedTransformations)
+	if err != nil {
+		return err
+	}
+
+	return cfg.Print(out, format, &config.ComponentConfigs{
+		Source:          source,
+		Targets:         targets,
+		FailureTarget:   failureTarget,
+		FilterTarget:    filterTarget,
+		Transformations: transformations,
+	})
+}
+
+// logConfig logs the effective config at debug level, with sensitive values redacted
+func logConfig(cfg *config.Config, supportedTransformations []config.ConfigurationPair) {
+	if !log.IsLevelEnabled(log.DebugLevel) {
+		return
+	}
+
+	var printed strings.Builder
+	if err := printConfig(cfg, supportedTransformations, config.PrintFormatHCL, &printed); err != nil {
+		log.WithError(err).Debug("Failed to print config")
+		return
+	}
+	log.Debugf("Config:\n%s", printed.String())
+}
@@ -0,0 +1,129 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+const printTestConfig = `
+source {
+  use "kafka" {
+    brokers         = "localhost:9092"
+    topic_name      = "input"
+    consumer_name   = "snowbridge"
+    offsets_initial = -2
+    enable_sasl     = true
+    sasl_password   = "hunter2"
+  }
+}
+
+transform {
+  use "jq" {
+    jq_command = ".a"
+  }
+}
+
+target {
+  use "http" {
+    url                  = "https://example.com"
+    oauth2_client_secret = "s3cret"
+  }
+}
+`
+
+func TestPrintConfig(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, printTestConfig, "")
+
+	var out bytes.Buffer
+	assert.NoError(PrintConfig(filename, transformconfig.SupportedTransformations, config.PrintFormatHCL, &out))
+
+	printed := out.String()
+	assert.NotContains(printed, "hunter2")
+	assert.NotContains(printed, "s3cret")
+	assert.Regexp(`sasl_password\s+= "REDACTED"`, printed)
+	assert.Regexp(`oauth2_client_secret\s+= "REDACTED"`, printed)
+
+	// Defaults of each component are filled in
+	assert.Regexp(`assignor\s+= "range"`, printed)
+	assert.Regexp(`use "jq" \{\s+jq_command\s+= ".a"\s+timeout_ms\s+= 100\s`, printed)
+	assert.Regexp(`request_timeout_in_millis\s+= 5000`, printed)
+	assert.Contains(printed, `use "silent" {`)
+}
+
+func TestPrintConfig_Secrets(t *testing.T) {
+	assert := assert.New(t)
+
+	// Printing never calls a secret manager, values from secrets are redacted whatever they are
+	filename := writeConfigFixture(t, `
+target {
+  use "http" {
+    url = secret("aws-sm://prod/webhook#url")
+  }
+}
+`, "")
+
+	var out bytes.Buffer
+	assert.NoError(PrintConfig(filename, transformconfig.SupportedTransformations, config.PrintFormatHCL, &out))
+	assert.NotContains(out.String(), "aws-sm://")
+	assert.Regexp(`url\s+= "REDACTED"`, out.String())
+}
+
+func TestPrintConfig_JSON(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, printTestConfig, "")
+
+	var out bytes.Buffer
+	assert.NoError(PrintConfig(filename, transformconfig.SupportedTransformations, config.PrintFormatJSON, &out))
+
+	var printed struct {
+		Transform struct {
+			Use []map[string]map[string]any `json:"use"`
+		} `json:"transform"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+	if assert.Len(printed.Transform.Use, 1) {
+		assert.Equal(".a", printed.Transform.Use[0]["jq"]["jq_command"])
+	}
+}
+
+func TestPrintConfig_Default(t *testing.T) {
+	assert := assert.New(t)
+	t.Setenv("SNOWBRIDGE_CONFIG_FILE", "")
+
+	var out bytes.Buffer
+	assert.NoError(PrintConfig("", transformconfig.SupportedTransformations, config.PrintFormatHCL, &out))
+	assert.Contains(out.String(), "source {\n  use \"stdin\" {\n")
+	assert.Regexp(`max_batch_bytes\s+= 1048576`, out.String())
+}
+
+func TestPrintConfig_UnknownTransformation(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, `
+transform {
+  use "fake_transformation" {}
+}
+`, "")
+
+	var out bytes.Buffer
+	err := PrintConfig(filename, transformconfig.SupportedTransformations, config.PrintFormatHCL, &out)
+	assert.EqualError(err, `could not interpret transformation configuration for "fake_transformation"`)
+}
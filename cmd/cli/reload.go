@@ -0,0 +1,257 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+	transformer "github.com/snowplow/snowbridge/v5/pkg/transform/transformer"
+)
+
+// reloadableBlock is the only top level block whose changes are applied without a restart
+const reloadableBlock = "transform"
+
+// reloader rebuilds the transformations and swaps them into the running transformer
+// when asked to with SIGHUP, or when the config file or one of its scripts changes.
+// A reload which fails, or which changes anything other than the transformations, is rejected and the running config is kept.
+type reloader struct {
+	filename                 string
+	supportedTransformations []config.ConfigurationPair
+	transformer              *transformer.Transformer
+	watchInterval            time.Duration
+
+	// The config currently running, and the fingerprint of the files it was loaded from
+	src         []byte
+	cfg         *config.Config
+	watched     []string
+	fingerprint [sha256.Size]byte
+}
+
+// newReloader creates a reloader for the config read from filename
+func newReloader(filename string, cfg *config.Config, supportedTransformations []config.ConfigurationPair, t *transformer.Transformer) (*reloader, error) {
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reloader{
+		filename:                 filename,
+		supportedTransformations: supportedTransformations,
+		transformer:              t,
+		watchInterval:            time.Duration(cfg.Data.HotReload.WatchIntervalMs) * time.Millisecond,
+		src:                      src,
+		cfg:                      cfg,
+	}
+	if err := r.watch(cfg); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Start reloads on SIGHUP, and on changes to the watched files when a watch interval is set, until the context is cancelled
+func (r *reloader) Start(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	if r.watchInterval > 0 {
+		ticker := time.NewTicker(r.watchInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Info("Received SIGHUP, reloading transformations")
+			r.reloadAndLog()
+		case <-tick:
+			fingerprint, err := r.fingerprintFiles()
+			if err != nil {
+				log.WithError(err).Warn("Failed to check the config files for changes")
+				continue
+			}
+			if fingerprint != r.fingerprint {
+				log.Info("Config changed, reloading transformations")
+				// Don't retry a broken change on every tick, wait for the next one
+				r.fingerprint = fingerprint
+				r.reloadAndLog()
+			}
+		}
+	}
+}
+
+// reloadAndLog reloads, logging why the reload failed if it did
+func (r *reloader) reloadAndLog() {
+	if err := r.reload(); err != nil {
+		log.WithError(err).Error("Failed to reload transformations, keeping the running config")
+		return
+	}
+	log.Info("Transformations reloaded")
+}
+
+// reload reads the config file again, and swaps in its transformations if nothing else has changed
+func (r *reloader) reload() error {
+	src, err := os.ReadFile(r.filename)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.NewHclConfig(src, r.filename)
+	if err != nil {
+		return err
+	}
+
+	if err := checkHotSwappable(r.filename, r.src, src); err != nil {
+		return err
+	}
+	if workerPool(cfg) != workerPool(r.cfg) {
+		return fmt.Errorf("changing transform.worker_pool requires a restart")
+	}
+
+	transformFunc, err := transformconfig.GetTransformations(cfg, r.supportedTransformations)
+	if err != nil {
+		return err
+	}
+
+	r.transformer.SetTransformFunction(transformFunc)
+	r.src = src
+	r.cfg = cfg
+	return r.watch(cfg)
+}
+
+// watch records the files the config was loaded from, and their fingerprint
+func (r *reloader) watch(cfg *config.Config) error {
+	scripts, err := transformconfig.ScriptPaths(cfg)
+	if err != nil {
+		return err
+	}
+	r.watched = append([]string{r.filename}, scripts...)
+
+	fingerprint, err := r.fingerprintFiles()
+	if err != nil {
+		return err
+	}
+	r.fingerprint = fingerprint
+	return nil
+}
+
+// fingerprintFiles hashes the contents of the watched files
+func (r *reloader) fingerprintFiles() ([sha256.Size]byte, error) {
+	h := sha256.New()
+	for _, path := range r.watched {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		h.Write([]byte(path))
+		h.Write(contents)
+	}
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], h.Sum(nil))
+	return fingerprint, nil
+}
+
+// workerPool returns the configured transformation worker pool size
+func workerPool(cfg *config.Config) int {
+	if cfg.Data.Transform == nil {
+		return 0
+	}
+	return cfg.Data.Transform.WorkerPool
+}
+
+// checkHotSwappable returns an error naming the first top level attribute or block, other than transformations,
+// which differs between two versions of a config file. Comments and formatting are ignored.
+func checkHotSwappable(filename string, oldSrc []byte, newSrc []byte) error {
+	oldItems, diags := topLevelItems(filename, oldSrc)
+	if diags.HasErrors() {
+		return diags
+	}
+	newItems, diags := topLevelItems(filename, newSrc)
+	if diags.HasErrors() {
+		return diags
+	}
+
+	names := make([]string, 0, len(oldItems)+len(newItems))
+	for name := range oldItems {
+		names = append(names, name)
+	}
+	for name := range newItems {
+		if _, ok := oldItems[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		if name == reloadableBlock {
+			continue
+		}
+		if !slices.Equal(oldItems[name], newItems[name]) {
+			return fmt.Errorf("changes to '%s' can't be applied without a restart, only changes to '%s' blocks can be reloaded", name, reloadableBlock)
+		}
+	}
+	return nil
+}
+
+// topLevelItems returns the normalised source of the top level attributes and blocks of a config file, keyed by name.
+// Blocks can be repeated, so each name maps to the source of all the blocks with that name.
+func topLevelItems(filename string, src []byte) (map[string][]string, hcl.Diagnostics) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body := file.Body.(*hclsyntax.Body)
+
+	items := make(map[string][]string)
+	for name, attr := range body.Attributes {
+		items[name] = []string{normaliseSource(attr.SrcRange.SliceBytes(src))}
+	}
+	for _, block := range body.Blocks {
+		items[block.Type] = append(items[block.Type], normaliseSource(block.Range().SliceBytes(src)))
+	}
+	return items, nil
+}
+
+// normaliseSource drops the comments and formatting of a snippet of HCL
+func normaliseSource(src []byte) string {
+	tokens, _ := hclsyntax.LexConfig(src, "", hcl.InitialPos)
+
+	var normalised []string
+	for _, token := range tokens {
+		switch token.Type {
+		case hclsyntax.TokenComment, hclsyntax.TokenNewline, hclsyntax.TokenEOF:
+			continue
+		}
+		normalised = append(normalised, string(bytes.TrimSpace(token.Bytes)))
+	}
+	return strings.Join(normalised, " ")
+}
@@ -0,0 +1,214 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+	transformer "github.com/snowplow/snowbridge/v5/pkg/transform/transformer"
+)
+
+const reloadTestConfig = `
+# Writes to stdout
+target {
+  use "stdout" {}
+}
+
+transform {
+  use "js" {
+    script_path = "%s"
+  }
+}
+`
+
+// reloadFixture runs a transformer whose transformations are loaded from a config file and a script in a temp dir
+type reloadFixture struct {
+	configPath string
+	scriptPath string
+	reloader   *reloader
+	input      chan *models.Message
+	output     chan *models.TransformationResult
+}
+
+func newReloadFixture(t *testing.T, watchIntervalMs int) *reloadFixture {
+	t.Helper()
+	dir := t.TempDir()
+	f := &reloadFixture{
+		configPath: filepath.Join(dir, "config.hcl"),
+		scriptPath: filepath.Join(dir, "script.js"),
+		input:      make(chan *models.Message),
+		output:     make(chan *models.TransformationResult),
+	}
+	f.writeScript(t, "first")
+	f.writeConfig(t, reloadTestConfig)
+
+	src, err := os.ReadFile(f.configPath)
+	require.NoError(t, err)
+	cfg, err := config.NewHclConfig(src, f.configPath)
+	require.NoError(t, err)
+	cfg.Data.HotReload.WatchIntervalMs = watchIntervalMs
+
+	transformFunc, err := transformconfig.GetTransformations(cfg, transformconfig.SupportedTransformations)
+	require.NoError(t, err)
+	tr := transformer.NewTransformer(transformFunc, f.input, f.output, nil, 1)
+	go tr.Start()
+	t.Cleanup(func() { close(f.input) })
+
+	f.reloader, err = newReloader(f.configPath, cfg, transformconfig.SupportedTransformations, tr)
+	require.NoError(t, err)
+	return f
+}
+
+func (f *reloadFixture) writeScript(t *testing.T, data string) {
+	t.Helper()
+	script := `function main(x) { x.Data = "` + data + `"; return x; }`
+	require.NoError(t, os.WriteFile(f.scriptPath, []byte(script), 0o644))
+}
+
+func (f *reloadFixture) writeConfig(t *testing.T, template string) {
+	t.Helper()
+	contents := fmt.Sprintf(template, filepath.ToSlash(f.scriptPath))
+	require.NoError(t, os.WriteFile(f.configPath, []byte(contents), 0o644))
+}
+
+// transform runs a message through the transformer and returns its data
+func (f *reloadFixture) transform(t *testing.T) string {
+	t.Helper()
+	f.input <- &models.Message{Data: []byte("input"), PartitionKey: "pk"}
+	result := <-f.output
+	require.NotNil(t, result.Transformed)
+	return string(result.Transformed.Data)
+}
+
+func TestReloader_Reload(t *testing.T) {
+	assert := assert.New(t)
+	f := newReloadFixture(t, 0)
+	assert.Equal("first", f.transform(t))
+
+	// Script changes are picked up
+	f.writeScript(t, "second")
+	assert.Nil(f.reloader.reload())
+	assert.Equal("second", f.transform(t))
+
+	// Comments and formatting outside the transformations don't prevent a reload
+	f.writeConfig(t, "# A new comment\n"+reloadTestConfig+"\n\n")
+	assert.Nil(f.reloader.reload())
+
+	// Transformations can be added
+	f.writeConfig(t, `
+# Writes to stdout
+target {
+  use "stdout" {}
+}
+
+transform {
+  use "js" {
+    script_path = "%s"
+  }
+  use "base64Encode" {}
+}
+`)
+	assert.Nil(f.reloader.reload())
+	assert.Equal("c2Vjb25k", f.transform(t))
+}
+
+func TestReloader_RejectsUnsafeChanges(t *testing.T) {
+	assert := assert.New(t)
+	f := newReloadFixture(t, 0)
+
+	testCases := map[string]struct {
+		config        string
+		expectedError string
+	}{
+		"target": {
+			config:        "target {\n  use \"stdout\" {\n    data_only_output = true\n  }\n}\ntransform {\n  use \"js\" {\n    script_path = \"%s\"\n  }\n}\n",
+			expectedError: "changes to 'target' can't be applied without a restart, only changes to 'transform' blocks can be reloaded",
+		},
+		"new attribute": {
+			config:        "log_level = \"debug\"\n" + reloadTestConfig,
+			expectedError: "changes to 'log_level' can't be applied without a restart, only changes to 'transform' blocks can be reloaded",
+		},
+		"worker pool": {
+			config:        "target {\n  use \"stdout\" {}\n}\ntransform {\n  worker_pool = 3\n  use \"js\" {\n    script_path = \"%s\"\n  }\n}\n",
+			expectedError: "changing transform.worker_pool requires a restart",
+		},
+		"invalid HCL": {
+			config:        "target {\n" + reloadTestConfig,
+			expectedError: "config.hcl:",
+		},
+		"invalid transformation": {
+			config:        "target {\n  use \"stdout\" {}\n}\ntransform {\n  use \"notATransformation\" {}\n  use \"js\" {\n    script_path = \"%s\"\n  }\n}\n",
+			expectedError: "could not interpret transformation configuration for \"notATransformation\"",
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			f.writeConfig(t, tt.config)
+			err := f.reloader.reload()
+			if assert.NotNil(err) {
+				assert.Contains(err.Error(), tt.expectedError)
+			}
+
+			// The running transformations are kept
+			assert.Equal("first", f.transform(t))
+		})
+	}
+}
+
+func TestReloader_Watch(t *testing.T) {
+	assert := assert.New(t)
+	f := newReloadFixture(t, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		f.reloader.Start(ctx)
+		close(done)
+	}()
+
+	f.writeScript(t, "watched")
+	assert.Eventually(func() bool { return f.transform(t) == "watched" }, 2*time.Second, 20*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reloader didn't stop")
+	}
+}
+
+func TestCheckHotSwappable(t *testing.T) {
+	assert := assert.New(t)
+
+	base := []byte("target {\n  use \"stdout\" {}\n}\n")
+	assert.Nil(checkHotSwappable("c.hcl", base, []byte("// comment\ntarget {\n    use   \"stdout\"   {}\n}\ntransform {\n  use \"base64Encode\" {}\n}\n")))
+
+	err := checkHotSwappable("c.hcl", base, []byte("target {\n  use \"stdout\" {}\n}\ntarget {\n  use \"stdout\" {}\n}\n"))
+	if assert.NotNil(err) {
+		assert.Equal("changes to 'target' can't be applied without a restart, only changes to 'transform' blocks can be reloaded", err.Error())
+	}
+
+	err = checkHotSwappable("c.hcl", base, []byte("{"))
+	assert.NotNil(err)
+}
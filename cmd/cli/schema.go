@@ -0,0 +1,52 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+// PrintSchema writes a JSON Schema of the whole configuration to out, covering every source, target and supported transformation.
+func PrintSchema(supportedTransformations []config.ConfigurationPair, out io.Writer) error {
+	transformations, err := transformconfig.TransformationDefaults(supportedTransformations)
+	if err != nil {
+		return err
+	}
+
+	sources, err := sourceconfig.SourceDefaults()
+	if err != nil {
+		return err
+	}
+
+	schema, err := config.Schema(&config.ComponentDefaults{
+		Sources:         sources,
+		Targets:         targetconfig.TargetDefaults(),
+		Transformations: transformations,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+func TestPrintSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	require.NoError(t, PrintSchema(transformconfig.SupportedTransformations, &out))
+
+	var schema struct {
+		Properties map[string]struct {
+			Type       string         `json:"type"`
+			Properties map[string]any `json:"properties"`
+			Items      struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &schema))
+
+	sources := schema.Properties["source"].Properties["use"].(map[string]any)["properties"].(map[string]any)
+	assert.Contains(sources, "stdin")
+	assert.Contains(sources, "kafka")
+	kafka := sources["kafka"].(map[string]any)
+	assert.Contains(kafka["required"], "brokers")
+
+	targets := schema.Properties["target"].Items.Properties["use"].(map[string]any)["properties"].(map[string]any)
+	for _, name := range []string{"stdout", "kafka", "pubsub", "kinesis", "http", "sqs", "eventhub", "silent"} {
+		assert.Contains(targets, name)
+	}
+	failureTargets := schema.Properties["failure_target"].Properties["use"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(len(targets), len(failureTargets))
+
+	transformations := schema.Properties["transform"].Properties["use"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	assert.Len(transformations, len(transformconfig.SupportedTransformations))
+	assert.Contains(transformations, "jq")
+}
@@ -0,0 +1,164 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	jd "github.com/josephburnett/jd/v2"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+// Outcomes of running a message through the transformations
+const (
+	transformOutcomeTransformed = "transformed"
+	transformOutcomeFiltered    = "filtered"
+	transformOutcomeFailed      = "failed"
+)
+
+// maxTransformInputLine is the longest input line, large enough for enriched events with big contexts
+const maxTransformInputLine = 10 * 1024 * 1024
+
+// transformOutput describes what the transformations made of one input message
+type transformOutput struct {
+	Input        string                `json:"input"`
+	Outcome      string                `json:"outcome"`
+	Data         string                `json:"data"`
+	PartitionKey string                `json:"partition_key"`
+	Route        string                `json:"route,omitempty"` // empty when the message goes to every target
+	HTTPHeaders  map[string]string     `json:"http_headers,omitempty"`
+	Error        *transformErrorOutput `json:"error,omitempty"`
+}
+
+// transformErrorOutput describes why a message failed to transform
+type transformErrorOutput struct {
+	Code        string `json:"code,omitempty"`
+	SafeMessage string `json:"safe_message,omitempty"`
+	Message     string `json:"message"`
+}
+
+// RunTransform runs each line read from input through the transformations configured in a config file,
+// and writes what they made of every message to out as a JSON array.
+// When expectFile is set, the output is also compared to the JSON it holds, and any difference fails the run.
+func RunTransform(filename string, supportedTransformations []config.ConfigurationPair, input io.Reader, out io.Writer, expectFile string) error {
+	if filename == "" {
+		return errors.New("no config file to take transformations from, pass one with --config or set SNOWBRIDGE_CONFIG_FILE")
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.NewHclConfig(src, filename)
+	if err != nil {
+		return err
+	}
+
+	transformFunc, err := transformconfig.GetTransformations(cfg, supportedTransformations)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]transformOutput, 0)
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxTransformInputLine)
+	for scanner.Scan() {
+		now := time.Now().UTC()
+		message := &models.Message{
+			Data:        []byte(scanner.Text()),
+			TimeCreated: now,
+			TimePulled:  now,
+		}
+		outputs = append(outputs, describeTransformation(scanner.Text(), transformFunc(message)))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	rendered, err := json.MarshalIndent(outputs, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, string(rendered))
+
+	if expectFile == "" {
+		return nil
+	}
+	return compareTransformOutput(expectFile, rendered, out)
+}
+
+// describeTransformation returns the outcome of transforming a message, along with the message it produced
+func describeTransformation(input string, result *models.TransformationResult) transformOutput {
+	var outcome string
+	var message *models.Message
+	switch {
+	case result.Transformed != nil:
+		outcome, message = transformOutcomeTransformed, result.Transformed
+	case result.Filtered != nil:
+		outcome, message = transformOutcomeFiltered, result.Filtered
+	default:
+		outcome, message = transformOutcomeFailed, result.Invalid
+	}
+
+	output := transformOutput{
+		Input:        input,
+		Outcome:      outcome,
+		Data:         string(message.Data),
+		PartitionKey: message.PartitionKey,
+		Route:        message.Route,
+		HTTPHeaders:  message.HTTPHeaders,
+	}
+
+	if err := message.GetError(); err != nil {
+		output.Error = &transformErrorOutput{Message: err.Error()}
+
+		var transformationErr *models.TransformationError
+		if errors.As(err, &transformationErr) {
+			output.Error.Code = transformationErr.Code()
+			output.Error.SafeMessage = transformationErr.SanitisedError()
+		}
+	}
+	return output
+}
+
+// compareTransformOutput diffs the output against the JSON expected in a golden file, writing the differences to out
+func compareTransformOutput(expectFile string, rendered []byte, out io.Writer) error {
+	expectedSrc, err := os.ReadFile(expectFile)
+	if err != nil {
+		return err
+	}
+
+	expected, err := jd.ReadJsonString(string(expectedSrc))
+	if err != nil {
+		return fmt.Errorf("failed to read expected output from %s: %w", expectFile, err)
+	}
+	actual, err := jd.ReadJsonString(string(rendered))
+	if err != nil {
+		return err
+	}
+
+	diff := expected.Diff(actual).Render()
+	if diff == "" {
+		return nil
+	}
+	fmt.Fprintf(out, "Output doesn't match %s:\n%s", expectFile, diff)
+	return fmt.Errorf("output doesn't match %s", expectFile)
+}
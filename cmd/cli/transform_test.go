@@ -0,0 +1,145 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+const transformTestConfig = `
+transform {
+  use "jq" {
+    jq_command = "{id: .id}"
+  }
+
+  use "js" {
+    script_path = env.SCRIPT_PATH
+  }
+}
+`
+
+const transformTestScript = `
+function main(x) {
+  if (JSON.parse(x.Data).id == 2) {
+    return { FilterOut: true };
+  }
+  return { Data: x.Data, PartitionKey: "pk", Route: "primary", HTTPHeaders: { "X-Id": "1" } };
+}
+`
+
+const transformTestInput = `{"id":1,"extra":true}
+{"id":2}
+not json
+`
+
+const transformTestExpected = `[
+  {
+    "input": "{\"id\":1,\"extra\":true}",
+    "outcome": "transformed",
+    "data": "{\"id\":1}",
+    "partition_key": "pk",
+    "route": "primary",
+    "http_headers": {
+      "X-Id": "1"
+    }
+  },
+  {
+    "input": "{\"id\":2}",
+    "outcome": "filtered",
+    "data": "{\"id\":2}",
+    "partition_key": ""
+  },
+  {
+    "input": "not json",
+    "outcome": "failed",
+    "data": "not json",
+    "partition_key": "",
+    "error": {
+      "code": "TransformationError",
+      "safe_message": "failed to prepare expected JQ input",
+      "message": "failed to prepare expected JQ input: invalid character 'o' in literal null (expecting 'u')"
+    }
+  }
+]
+`
+
+func TestRunTransform(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, transformTestConfig, transformTestScript)
+
+	var out bytes.Buffer
+	err := RunTransform(filename, transformconfig.SupportedTransformations, strings.NewReader(transformTestInput), &out, "")
+	assert.NoError(err)
+	assert.Equal(transformTestExpected, out.String())
+}
+
+func TestRunTransform_LongLine(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, transformTestConfig, transformTestScript)
+
+	// Lines longer than the default scanner limit of 64 KiB are read whole
+	input := `{"id":1,"extra":"` + strings.Repeat("x", 200*1024) + `"}`
+	var out bytes.Buffer
+	err := RunTransform(filename, transformconfig.SupportedTransformations, strings.NewReader(input), &out, "")
+	assert.NoError(err)
+	assert.Contains(out.String(), `"data": "{\"id\":1}"`)
+}
+
+func TestRunTransform_Expect(t *testing.T) {
+	filename := writeConfigFixture(t, transformTestConfig, transformTestScript)
+	expectFile := filepath.Join(t.TempDir(), "expected.json")
+
+	t.Run("matching", func(t *testing.T) {
+		assert := assert.New(t)
+		require.NoError(t, os.WriteFile(expectFile, []byte(transformTestExpected), 0o644))
+
+		var out bytes.Buffer
+		err := RunTransform(filename, transformconfig.SupportedTransformations, strings.NewReader(transformTestInput), &out, expectFile)
+		assert.NoError(err)
+		assert.Equal(transformTestExpected, out.String())
+	})
+
+	t.Run("different", func(t *testing.T) {
+		assert := assert.New(t)
+		require.NoError(t, os.WriteFile(expectFile, []byte(strings.Replace(transformTestExpected, `"pk"`, `"other"`, 1)), 0o644))
+
+		var out bytes.Buffer
+		err := RunTransform(filename, transformconfig.SupportedTransformations, strings.NewReader(transformTestInput), &out, expectFile)
+		assert.EqualError(err, "output doesn't match "+expectFile)
+		assert.Contains(out.String(), "Output doesn't match "+expectFile+":\n@ [0,\"partition_key\"]\n- \"other\"\n+ \"pk\"\n")
+	})
+}
+
+func TestRunTransform_InvalidConfig(t *testing.T) {
+	assert := assert.New(t)
+	filename := writeConfigFixture(t, `
+transform {
+  use "js" {
+    script_path = env.SCRIPT_PATH
+  }
+}
+`, `function notMain(x) { return x; }`)
+
+	var out bytes.Buffer
+	err := RunTransform(filename, transformconfig.SupportedTransformations, strings.NewReader(transformTestInput), &out, "")
+	assert.ErrorContains(err, "error smoke testing JS function")
+	assert.Empty(out.String())
+}
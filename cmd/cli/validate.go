@@ -0,0 +1,112 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/snowplow/snowbridge/v5/cmd"
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+// ValidateConfig checks that every component of a config file can be decoded and built, without connecting to anything:
+// the source and targets are decoded but not created, while transformations are compiled and smoke tested.
+// Every problem found is written to out, HCL diagnostics along with the position and snippet of the config at fault.
+func ValidateConfig(filename string, supportedTransformations []config.ConfigurationPair, out io.Writer) error {
+	if filename == "" {
+		return errors.New("no config file to validate, pass one as an argument or set SNOWBRIDGE_CONFIG_FILE")
+	}
+
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	// Parse separately to keep hold of the file, so diagnostics can show the config they point to
+	parser := hclparse.NewParser()
+	diagWriter := hcl.NewDiagnosticTextWriter(out, parser.Files(), 0, false)
+	if _, diags := parser.ParseHCL(src, filename); diags.HasErrors() {
+		_ = diagWriter.WriteDiagnostics(diags)
+		return fmt.Errorf("config file %s is invalid", filename)
+	}
+
+	// Secret references are checked, but secret managers are not called
+	cfg, err := config.NewOfflineHclConfig(src, filename)
+	if err != nil {
+		writeValidationError(out, diagWriter, "config", err)
+		return fmt.Errorf("config file %s is invalid", filename)
+	}
+
+	checks := []struct {
+		component string
+		check     func() error
+	}{
+		{"source", func() error { return sourceconfig.ValidateSource(cfg) }},
+		{"transform", func() error {
+			_, err := transformconfig.GetTransformations(cfg, supportedTransformations)
+			return err
+		}},
+		{"target", func() error { return targetconfig.ValidateTargets(cfg.Data.Targets, cfg.Decoder) }},
+		{"filter_target", func() error { return targetconfig.ValidateTarget(cfg.Data.FilterTarget, cfg.Decoder) }},
+		{"failure_target", func() error { return targetconfig.ValidateTarget(cfg.Data.FailureTarget, cfg.Decoder) }},
+		{"failure_parser", func() error {
+			// The size limit doesn't affect whether the failure format is valid
+			_, err := cfg.GetFailureParser(0, cmd.AppName, cmd.AppVersion)
+			return err
+		}},
+		{"stats_receiver", cfg.ValidateStatsReceiver},
+		{"retry", cfg.Data.Retry.Validate},
+		{"spill", cfg.Data.Spill.Validate},
+		{"shutdown", func() error { return cfg.Data.Shutdown.Validate(cfg.Data.Spill) }},
+		{"circuit_breaker", cfg.Data.CircuitBreaker.Validate},
+		{"health", cfg.Data.Health.Validate},
+		{"tracing", func() error {
+			if !cfg.Data.Tracing.Enabled {
+				return nil
+			}
+			return cfg.Data.Tracing.Validate()
+		}},
+	}
+
+	var invalid bool
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			writeValidationError(out, diagWriter, c.component, err)
+			invalid = true
+		}
+	}
+	if invalid {
+		return fmt.Errorf("config file %s is invalid", filename)
+	}
+
+	fmt.Fprintf(out, "Config file %s is valid\n", filename)
+	return nil
+}
+
+// writeValidationError writes HCL diagnostics with the config they point to, and any other error on its own
+func writeValidationError(out io.Writer, diagWriter hcl.DiagnosticWriter, component string, err error) {
+	var diags hcl.Diagnostics
+	if errors.As(err, &diags) {
+		_ = diagWriter.WriteDiagnostics(diags)
+		return
+	}
+	fmt.Fprintf(out, "Error: Invalid %s configuration\n\n  %s\n\n", component, err)
+}
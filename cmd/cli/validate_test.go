@@ -0,0 +1,169 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/transform/transformconfig"
+)
+
+// writeConfigFixture writes a config file, along with a script it can load from script.js
+func writeConfigFixture(t *testing.T, configSrc string, script string) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "script.js"), []byte(script), 0o644))
+	t.Setenv("SCRIPT_PATH", filepath.Join(dir, "script.js"))
+
+	filename := filepath.Join(dir, "config.hcl")
+	require.NoError(t, os.WriteFile(filename, []byte(configSrc), 0o644))
+	return filename
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	// Nothing is listening on these, validating must not connect to them
+	filename := writeConfigFixture(t, `
+source {
+  use "kafka" {
+    brokers         = "localhost:1"
+    topic_name      = "input"
+    consumer_name   = "snowbridge"
+    offsets_initial = -2
+  }
+}
+
+transform {
+  use "js" {
+    script_path = env.SCRIPT_PATH
+  }
+}
+
+target {
+  use "kafka" {
+    brokers    = "localhost:1"
+    topic_name = "output"
+  }
+}
+`, `function main(x) { return x; }`)
+
+	var out bytes.Buffer
+	assert.NoError(ValidateConfig(filename, transformconfig.SupportedTransformations, &out))
+	assert.Equal("Config file "+filename+" is valid\n", out.String())
+}
+
+func TestValidateConfig_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	filename := writeConfigFixture(t, `
+source {
+  use "stdin" {
+    concurrent_writes = "many"
+  }
+}
+
+transform {
+  use "js" {
+    script_path = env.SCRIPT_PATH
+  }
+}
+
+target {
+  use "stdout" {
+    batching {
+      max_message_bytes = 100
+      max_batch_bytes   = 10
+    }
+  }
+}
+
+failure_parser {
+  format = "unknown"
+}
+
+stats_receiver {
+  use "statsd" {
+    endpoint = "localhost:8125"
+  }
+}
+
+spill {
+  path              = "/tmp/snowbridge-spill"
+  drain_interval_ms = 0
+}
+`, `function notMain(x) { return x; }`)
+
+	var out bytes.Buffer
+	err := ValidateConfig(filename, transformconfig.SupportedTransformations, &out)
+	assert.EqualError(err, "config file "+filename+" is invalid")
+
+	// Every problem is reported, with the position of those found while decoding
+	assert.Contains(out.String(), "on "+filename+" line 4, in source:")
+	assert.Contains(out.String(), `An argument named "concurrent_writes" is not expected here.`)
+	assert.Contains(out.String(), "Error: Invalid transform configuration")
+	assert.Contains(out.String(), "Error: Invalid target configuration\n\n  stdout target has invalid batching configuration: max_message_bytes (100) must not be greater than max_batch_bytes (10)")
+	assert.Contains(out.String(), "Error: Invalid failure_parser configuration")
+	assert.Contains(out.String(), `An argument named "endpoint" is not expected here.`)
+	assert.Contains(out.String(), "Error: Invalid spill configuration\n\n  invalid spill drain_interval_ms 0, must be greater than 0")
+}
+
+func TestValidateConfig_SyntaxError(t *testing.T) {
+	assert := assert.New(t)
+
+	filename := writeConfigFixture(t, "target {\n  use \"stdout\" {\n}\n", "")
+
+	var out bytes.Buffer
+	err := ValidateConfig(filename, transformconfig.SupportedTransformations, &out)
+	assert.EqualError(err, "config file "+filename+" is invalid")
+	assert.Contains(out.String(), "Error: Unclosed configuration block")
+	assert.Contains(out.String(), "on "+filename+" line 1, in target:")
+}
+
+func TestValidateConfig_NoFile(t *testing.T) {
+	assert := assert.New(t)
+
+	var out bytes.Buffer
+	err := ValidateConfig("", transformconfig.SupportedTransformations, &out)
+	assert.EqualError(err, "no config file to validate, pass one as an argument or set SNOWBRIDGE_CONFIG_FILE")
+	assert.Empty(out.String())
+}
+
+func TestValidateConfig_Secrets(t *testing.T) {
+	assert := assert.New(t)
+
+	// No secret manager is called, only the references are checked
+	configSrc := `
+target {
+  use "kafka" {
+    brokers       = "localhost:1"
+    topic_name    = "output"
+    sasl_password = secret(env.SECRET_REF)
+  }
+}
+`
+	t.Setenv("SECRET_REF", "aws-sm://prod/kafka#password")
+	var out bytes.Buffer
+	assert.NoError(ValidateConfig(writeConfigFixture(t, configSrc, ""), transformconfig.SupportedTransformations, &out))
+
+	t.Setenv("SECRET_REF", "vault://prod/kafka")
+	filename := writeConfigFixture(t, configSrc, "")
+	out.Reset()
+	assert.EqualError(ValidateConfig(filename, transformconfig.SupportedTransformations, &out), "config file "+filename+" is invalid")
+	assert.Contains(out.String(), "on "+filename+" line 6, in target:")
+	assert.Contains(out.String(), `Call to function "secret" failed: unsupported secret reference scheme "vault"`)
+}
@@ -86,7 +86,6 @@ func Init() (*config.Config, bool, error) {
 		return nil, sentryEnabled, fmt.Errorf("supported log levels are 'debug, info, warning, error, fatal, panic'; provided %s", cfg.Data.LogLevel)
 	}
 
-	log.Debugf("Config: %+v", cfg)
 	return cfg, sentryEnabled, nil
 }
 
@@ -12,7 +12,6 @@
 package main
 
 import (
-	"net/http"
 	"os"
 	"time"
 
@@ -26,10 +25,8 @@ import (
 )
 
 func main() {
-	config, sentryEnabled, err := cmd.Init()
-	if err != nil {
-		exitWithError(err, sentryEnabled)
-	}
+	var sentryEnabled bool
+
 	app := cli.NewApp()
 	app.Name = cmd.AppName
 	app.Usage = cmd.AppUsage
@@ -50,22 +47,102 @@ func main() {
 	app.Flags = []cli.Flag{
 		cli.BoolFlag{
 			Name:  "profile, p",
-			Usage: "Enable application profiling endpoint on port 8080",
+			Usage: "Enable application profiling endpoints, served on health.profiling_address (default: localhost:6060)",
 		},
 	}
 
 	app.Action = func(c *cli.Context) error {
-		profile := c.Bool("profile")
-		if profile {
-			go func() {
-				if err := http.ListenAndServe("localhost:8080", nil); err != nil {
-					log.WithError(err).Fatal("failed to start up the server")
-				}
-			}()
+		config, enabled, err := cmd.Init()
+		sentryEnabled = enabled
+		if err != nil {
+			return err
+		}
+		if c.Bool("profile") {
+			config.Data.Health.EnableProfiling = true
 		}
 		return snowbridge_cli.RunApp(config, transformconfig.SupportedTransformations)
 	}
 
+	app.Commands = []cli.Command{
+		{
+			Name:      "validate",
+			Usage:     "Check that a config file is valid, without connecting to any source or target",
+			ArgsUsage: "[config file, defaults to SNOWBRIDGE_CONFIG_FILE]",
+			Action: func(c *cli.Context) error {
+				filename := c.Args().First()
+				if filename == "" {
+					filename = os.Getenv("SNOWBRIDGE_CONFIG_FILE")
+				}
+				return snowbridge_cli.ValidateConfig(filename, transformconfig.SupportedTransformations, os.Stdout)
+			},
+		},
+		{
+			Name:      "transform",
+			Usage:     "Run the configured transformations on each line of a file, or of stdin, and print the results",
+			ArgsUsage: "[input file, defaults to stdin]",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:   "config, c",
+					Usage:  "Config file to take the transformations from",
+					EnvVar: "SNOWBRIDGE_CONFIG_FILE",
+				},
+				cli.StringFlag{
+					Name:  "expect, e",
+					Usage: "JSON file holding the expected output, the command fails when the output differs from it",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				input := os.Stdin
+				if filename := c.Args().First(); filename != "" && filename != "-" {
+					f, err := os.Open(filename)
+					if err != nil {
+						return err
+					}
+					defer func() {
+						if err := f.Close(); err != nil {
+							log.WithError(err).Error("Failed to close input file")
+						}
+					}()
+					input = f
+				}
+				return snowbridge_cli.RunTransform(c.String("config"), transformconfig.SupportedTransformations, input, os.Stdout, c.String("expect"))
+			},
+		},
+	}
+
+	app.Commands = append(app.Commands, cli.Command{
+		Name:  "config",
+		Usage: "Inspect the configuration",
+		Subcommands: []cli.Command{
+			{
+				Name:  "print",
+				Usage: "Print the effective configuration, with all defaults filled in and secrets redacted",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:   "config, c",
+						Usage:  "Config file to print, the default config is printed when not set",
+						EnvVar: "SNOWBRIDGE_CONFIG_FILE",
+					},
+					cli.StringFlag{
+						Name:  "format, f",
+						Usage: "Output format, 'hcl' or 'json'",
+						Value: "hcl",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return snowbridge_cli.PrintConfig(c.String("config"), transformconfig.SupportedTransformations, c.String("format"), os.Stdout)
+				},
+			},
+			{
+				Name:  "schema",
+				Usage: "Print a JSON Schema of the configuration, covering every source, target and transformation",
+				Action: func(c *cli.Context) error {
+					return snowbridge_cli.PrintSchema(transformconfig.SupportedTransformations, os.Stdout)
+				},
+			},
+		},
+	})
+
 	app.ExitErrHandler = func(context *cli.Context, err error) {
 		if err != nil {
 			exitWithError(err, sentryEnabled)
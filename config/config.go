@@ -13,6 +13,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
@@ -27,8 +28,11 @@ import (
 	"github.com/snowplow/snowbridge/v5/pkg/common"
 	"github.com/snowplow/snowbridge/v5/pkg/monitoring"
 	"github.com/snowplow/snowbridge/v5/pkg/observer"
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
+	"github.com/snowplow/snowbridge/v5/pkg/spill"
 	"github.com/snowplow/snowbridge/v5/pkg/statsreceiver"
 	"github.com/snowplow/snowbridge/v5/pkg/statsreceiver/statsreceiveriface"
+	"github.com/snowplow/snowbridge/v5/pkg/tracing"
 )
 
 // ConfigurationPair allows modular packages to define their own configuration and function to interpret the configuration.
@@ -47,21 +51,27 @@ type Config struct {
 
 // ConfigurationData for holding all configuration options
 type ConfigurationData struct {
-	Source           *component        `hcl:"source,block"`
-	Target           *TargetConfig     `hcl:"target,block"`
-	FailureTarget    *TargetConfig     `hcl:"failure_target,block"`
-	FilterTarget     *TargetConfig     `hcl:"filter_target,block"`
-	FailureParser    *failureParser    `hcl:"failure_parser,block"`
-	Sentry           *sentryConfig     `hcl:"sentry,block"`
-	StatsReceiver    *statsConfig      `hcl:"stats_receiver,block"`
-	Transform        *TransformConfig  `hcl:"transform,block"`
-	LogLevel         string            `hcl:"log_level,optional"`
-	UserProvidedID   string            `hcl:"user_provided_id,optional"`
-	DisableTelemetry bool              `hcl:"disable_telemetry,optional"`
-	License          *licenseConfig    `hcl:"license,block"`
-	Retry            *RetryConfig      `hcl:"retry,block"`
-	Metrics          *metricsConfig    `hcl:"metrics,block"`
-	Monitoring       *monitoringConfig `hcl:"monitoring,block"`
+	Source           *component            `hcl:"source,block"`
+	Targets          []*TargetConfig       `hcl:"target,block"`
+	FailureTarget    *TargetConfig         `hcl:"failure_target,block"`
+	FilterTarget     *TargetConfig         `hcl:"filter_target,block"`
+	FailureParser    *failureParser        `hcl:"failure_parser,block"`
+	Sentry           *sentryConfig         `hcl:"sentry,block"`
+	StatsReceiver    *statsConfig          `hcl:"stats_receiver,block"`
+	Transform        *TransformConfig      `hcl:"transform,block"`
+	LogLevel         string                `hcl:"log_level,optional"`
+	UserProvidedID   string                `hcl:"user_provided_id,optional"`
+	DisableTelemetry bool                  `hcl:"disable_telemetry,optional"`
+	License          *licenseConfig        `hcl:"license,block"`
+	Retry            *RetryConfig          `hcl:"retry,block"`
+	Spill            *SpillConfig          `hcl:"spill,block"`
+	CircuitBreaker   *CircuitBreakerConfig `hcl:"circuit_breaker,block"`
+	Shutdown         *ShutdownConfig       `hcl:"shutdown,block"`
+	Health           *HealthConfig         `hcl:"health,block"`
+	Tracing          *TracingConfig        `hcl:"tracing,block"`
+	HotReload        *HotReloadConfig      `hcl:"hot_reload,block"`
+	Metrics          *metricsConfig        `hcl:"metrics,block"`
+	Monitoring       *monitoringConfig     `hcl:"monitoring,block"`
 }
 
 // component is a type to abstract over configuration blocks.
@@ -82,8 +92,10 @@ type use struct {
 }
 
 // TargetConfig is handled in the target package.
+// Name is optional and defaults to the name of the target used, it must be unique when multiple targets are configured.
 type TargetConfig struct {
-	Target *use `hcl:"use,block"`
+	Name   string `hcl:"name,optional"`
+	Target *use   `hcl:"use,block"`
 }
 
 // failureParser holds configuration for failure handling.
@@ -93,7 +105,7 @@ type failureParser struct {
 
 // sentryConfig configures the Sentry error tracker.
 type sentryConfig struct {
-	Dsn   string `hcl:"dsn"`
+	Dsn   string `hcl:"dsn" sensitive:"true"`
 	Tags  string `hcl:"tags,optional"`
 	Debug bool   `hcl:"debug,optional"`
 }
@@ -112,12 +124,196 @@ type licenseConfig struct {
 	Accept bool `hcl:"accept,optional"`
 }
 
+// Backoff modes for retry delays
+const (
+	// BackoffFixed waits delay_ms between every retry
+	BackoffFixed = "fixed"
+	// BackoffExponential doubles the delay after every retry
+	BackoffExponential = "exponential"
+	// BackoffExponentialJitter waits a random delay up to the exponential one, so that replicas don't retry in lockstep
+	BackoffExponentialJitter = "exponential_jitter"
+)
+
 type RetryConfig struct {
 	Transient *TransientRetryConfig `hcl:"transient,block"`
 	Setup     *SetupRetryConfig     `hcl:"setup,block"`
 	Throttle  *ThrottleRetryConfig  `hcl:"throttle,block"`
 }
 
+// SpillConfig configures spilling batches to disk when a target stays unavailable after retries.
+// Spilling is disabled unless a path is set.
+type SpillConfig struct {
+	Path            string `hcl:"path,optional"`
+	MaxBytes        int64  `hcl:"max_bytes,optional"`
+	EncryptionKey   string `hcl:"encryption_key,optional" sensitive:"true"`
+	DrainIntervalMs int    `hcl:"drain_interval_ms,optional"`
+}
+
+// Validate checks the settings of enabled spilling, including that the encryption key decodes to an AES key
+func (c *SpillConfig) Validate() error {
+	if c == nil || c.Path == "" {
+		return nil
+	}
+	if c.MaxBytes <= 0 {
+		return fmt.Errorf("invalid spill max_bytes %d, must be greater than 0", c.MaxBytes)
+	}
+	if c.DrainIntervalMs <= 0 {
+		return fmt.Errorf("invalid spill drain_interval_ms %d, must be greater than 0", c.DrainIntervalMs)
+	}
+	_, err := spill.NewCipher(c.EncryptionKey)
+	return err
+}
+
+// CircuitBreakerConfig configures the circuit breaker which pauses writes to a target that keeps failing.
+// Each target gets its own breaker.
+type CircuitBreakerConfig struct {
+	Enabled        bool    `hcl:"enabled,optional"`
+	FailureRate    float64 `hcl:"failure_rate,optional"`
+	MinRequests    int     `hcl:"min_requests,optional"`
+	WindowMs       int     `hcl:"window_ms,optional"`
+	OpenDurationMs int     `hcl:"open_duration_ms,optional"`
+}
+
+// Validate checks the settings of an enabled circuit breaker
+func (c *CircuitBreakerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.FailureRate <= 0 || c.FailureRate > 1 {
+		return fmt.Errorf("invalid circuit_breaker failure_rate %v, must be greater than 0 and at most 1", c.FailureRate)
+	}
+	return nil
+}
+
+// HealthConfig configures the admin HTTP server serving liveness and readiness probes,
+// and the separate server serving the profiling endpoints
+type HealthConfig struct {
+	Enabled          bool   `hcl:"enabled,optional"`
+	Address          string `hcl:"address,optional"`
+	ActivityWindowMs int    `hcl:"activity_window_ms,optional"`
+	EnableProfiling  bool   `hcl:"enable_profiling,optional"`
+	ProfilingAddress string `hcl:"profiling_address,optional"`
+}
+
+// Validate checks that the health and profiling servers, when both are enabled, don't listen on the same address
+func (c *HealthConfig) Validate() error {
+	if !c.Enabled || !c.EnableProfiling {
+		return nil
+	}
+	healthHost, healthPort, err := net.SplitHostPort(c.Address)
+	if err != nil {
+		return fmt.Errorf("invalid health address %q: %w", c.Address, err)
+	}
+	profilingHost, profilingPort, err := net.SplitHostPort(c.ProfilingAddress)
+	if err != nil {
+		return fmt.Errorf("invalid health profiling_address %q: %w", c.ProfilingAddress, err)
+	}
+	if healthPort != profilingPort || healthPort == "0" {
+		return nil
+	}
+	if isWildcardHost(healthHost) || isWildcardHost(profilingHost) || loopbackHost(healthHost) == loopbackHost(profilingHost) {
+		return fmt.Errorf("health address %q and profiling_address %q clash, the profiling endpoints need a port of their own", c.Address, c.ProfilingAddress)
+	}
+	return nil
+}
+
+// isWildcardHost reports whether a listener on host listens on every interface
+func isWildcardHost(host string) bool {
+	return host == "" || host == "0.0.0.0" || host == "::"
+}
+
+// loopbackHost spells the IPv4 loopback address the same way, whether it is given as a name or an IP
+func loopbackHost(host string) string {
+	if host == "localhost" {
+		return "127.0.0.1"
+	}
+	return host
+}
+
+// TracingConfig configures exporting a trace of every message to an OpenTelemetry collector over OTLP/HTTP
+type TracingConfig struct {
+	Enabled     bool              `hcl:"enabled,optional"`
+	Endpoint    string            `hcl:"endpoint,optional"`
+	Headers     map[string]string `hcl:"headers,optional" sensitive:"true"`
+	ServiceName string            `hcl:"service_name,optional"`
+	SampleRatio float64           `hcl:"sample_ratio,optional"`
+}
+
+// Validate checks the tracing sample ratio
+func (c *TracingConfig) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("invalid tracing.sample_ratio; expected a value between 0 and 1 and got %v", c.SampleRatio)
+	}
+	return nil
+}
+
+// HotReloadConfig configures applying changes to the transformations without restarting the app,
+// on SIGHUP and, when a watch interval is set, whenever the config file or a script it uses changes
+type HotReloadConfig struct {
+	Enabled         bool `hcl:"enabled,optional"`
+	WatchIntervalMs int  `hcl:"watch_interval_ms,optional"`
+}
+
+// Shutdown policies decide what happens to data which isn't written yet once shutdown starts
+const (
+	// ShutdownPolicyFlush keeps writing buffered and in-flight batches until the drain timeout
+	ShutdownPolicyFlush = "flush"
+	// ShutdownPolicyNack stops writing and nacks buffered batches, and nacks in-flight ones left at the drain timeout
+	ShutdownPolicyNack = "nack"
+	// ShutdownPolicySpill writes buffered batches, and in-flight ones left at the drain timeout, to the spill log
+	ShutdownPolicySpill = "spill"
+)
+
+// ShutdownConfig configures how the app drains data when it is asked to shut down
+type ShutdownConfig struct {
+	DrainTimeoutMs int    `hcl:"drain_timeout_ms,optional"`
+	Policy         string `hcl:"policy,optional"`
+}
+
+// Validate checks the shutdown policy, which can only spill when spilling is enabled
+func (c *ShutdownConfig) Validate(spill *SpillConfig) error {
+	if c.DrainTimeoutMs <= 0 {
+		return fmt.Errorf("invalid shutdown drain_timeout_ms %d, must be greater than 0", c.DrainTimeoutMs)
+	}
+	switch c.Policy {
+	case ShutdownPolicyFlush, ShutdownPolicyNack:
+	case ShutdownPolicySpill:
+		if spill == nil || spill.Path == "" {
+			return errors.New("shutdown policy 'spill' requires a spill path to be configured")
+		}
+	default:
+		return fmt.Errorf("invalid shutdown policy; expected one of '%s', '%s', '%s' and got '%s'", ShutdownPolicyFlush, ShutdownPolicyNack, ShutdownPolicySpill, c.Policy)
+	}
+	return nil
+}
+
+// Validate checks the backoff settings of every retry class
+func (c *RetryConfig) Validate() error {
+	classes := []struct {
+		name     string
+		backoff  string
+		maxDelay int
+		deadline int
+	}{
+		{"transient", c.Transient.Backoff, c.Transient.MaxDelay, c.Transient.Deadline},
+		{"setup", c.Setup.Backoff, c.Setup.MaxDelay, c.Setup.Deadline},
+		{"throttle", c.Throttle.Backoff, c.Throttle.MaxDelay, c.Throttle.Deadline},
+	}
+
+	for _, class := range classes {
+		switch class.backoff {
+		// An empty mode is left by a retry block which doesn't set backoff, and means exponential
+		case "", BackoffFixed, BackoffExponential, BackoffExponentialJitter:
+		default:
+			return fmt.Errorf("invalid retry.%s.backoff; expected one of '%s', '%s', '%s' and got '%s'", class.name, BackoffFixed, BackoffExponential, BackoffExponentialJitter, class.backoff)
+		}
+		if class.maxDelay < 0 || class.deadline < 0 {
+			return fmt.Errorf("invalid retry.%s configuration: max_delay_ms and deadline_ms must not be negative", class.name)
+		}
+	}
+	return nil
+}
+
 type metricsConfig struct {
 	E2ELatencyEnabled            bool `hcl:"enable_e2e_latency,optional"`
 	KinsumerMemoryMetricsEnabled bool `hcl:"enable_kinsumer_memory_metrics,optional"`
@@ -140,28 +336,43 @@ type metadataReporterConfig struct {
 }
 
 type TransientRetryConfig struct {
-	Delay           int  `hcl:"delay_ms,optional"`
-	MaxAttempts     int  `hcl:"max_attempts,optional"`
-	InvalidAfterMax bool `hcl:"invalid_after_max,optional"` // default: false
+	Delay           int    `hcl:"delay_ms,optional"`
+	MaxAttempts     int    `hcl:"max_attempts,optional"`
+	InvalidAfterMax bool   `hcl:"invalid_after_max,optional"` // default: false
+	Backoff         string `hcl:"backoff,optional"`
+	MaxDelay        int    `hcl:"max_delay_ms,optional"`
+	Deadline        int    `hcl:"deadline_ms,optional"` // default: 0, no deadline
 }
 
 type SetupRetryConfig struct {
-	Delay           int  `hcl:"delay_ms,optional"`
-	MaxAttempts     int  `hcl:"max_attempts,optional"`
-	InvalidAfterMax bool `hcl:"invalid_after_max,optional"` // default: false
+	Delay           int    `hcl:"delay_ms,optional"`
+	MaxAttempts     int    `hcl:"max_attempts,optional"`
+	InvalidAfterMax bool   `hcl:"invalid_after_max,optional"` // default: false
+	Backoff         string `hcl:"backoff,optional"`
+	MaxDelay        int    `hcl:"max_delay_ms,optional"`
+	Deadline        int    `hcl:"deadline_ms,optional"` // default: 0, no deadline
 }
 
 type ThrottleRetryConfig struct {
-	Delay           int  `hcl:"delay_ms,optional"`
-	MaxAttempts     int  `hcl:"max_attempts,optional"`
-	InvalidAfterMax bool `hcl:"invalid_after_max,optional"` // default: false
+	Delay           int    `hcl:"delay_ms,optional"`
+	MaxAttempts     int    `hcl:"max_attempts,optional"`
+	InvalidAfterMax bool   `hcl:"invalid_after_max,optional"` // default: false
+	Backoff         string `hcl:"backoff,optional"`
+	MaxDelay        int    `hcl:"max_delay_ms,optional"`
+	Deadline        int    `hcl:"deadline_ms,optional"` // default: 0, no deadline
+}
+
+// DefaultConfigurationData returns the configuration used when no config file is given,
+// which holds the default of every setting. A new copy is returned on each call.
+func DefaultConfigurationData() *ConfigurationData {
+	return defaultConfigData()
 }
 
 // defaultConfigData returns the initial main configuration target.
 func defaultConfigData() *ConfigurationData {
 	return &ConfigurationData{
 		Source:        &component{&use{Name: "stdin"}},
-		Target:        &TargetConfig{Target: &use{Name: "stdout"}},
+		Targets:       []*TargetConfig{{Target: &use{Name: "stdout"}}},
 		FailureTarget: &TargetConfig{Target: &use{Name: "stdout"}},
 		FilterTarget:  &TargetConfig{Target: &use{Name: "silent"}},
 		FailureParser: &failureParser{
@@ -187,16 +398,50 @@ func defaultConfigData() *ConfigurationData {
 			Transient: &TransientRetryConfig{
 				Delay:       1000,
 				MaxAttempts: 5,
+				Backoff:     BackoffExponential,
 			},
 			Setup: &SetupRetryConfig{
 				Delay:       20000,
 				MaxAttempts: 5,
+				Backoff:     BackoffExponential,
 			},
 			Throttle: &ThrottleRetryConfig{
 				Delay:       10000,
 				MaxAttempts: 5,
+				Backoff:     BackoffExponential,
 			},
 		},
+		Spill: &SpillConfig{
+			MaxBytes:        1073741824, // 1 GiB
+			DrainIntervalMs: 10000,
+		},
+		Shutdown: &ShutdownConfig{
+			DrainTimeoutMs: 5000,
+			Policy:         ShutdownPolicyFlush,
+		},
+		Health: &HealthConfig{
+			Enabled:          false,
+			Address:          ":8080",
+			ProfilingAddress: "localhost:6060",
+		},
+		HotReload: &HotReloadConfig{
+			Enabled:         false,
+			WatchIntervalMs: 5000,
+		},
+		Tracing: &TracingConfig{
+			Enabled:     false,
+			Endpoint:    "http://localhost:4318/v1/traces",
+			Headers:     map[string]string{},
+			ServiceName: "snowbridge",
+			SampleRatio: 1,
+		},
+		CircuitBreaker: &CircuitBreakerConfig{
+			Enabled:        false,
+			FailureRate:    0.5,
+			MinRequests:    10,
+			WindowMs:       60000,
+			OpenDurationMs: 30000,
+		},
 		Metrics: &metricsConfig{
 			E2ELatencyEnabled:            false,
 			KinsumerMemoryMetricsEnabled: false,
@@ -213,14 +458,19 @@ func defaultConfigData() *ConfigurationData {
 	}
 }
 
+// NewDefaultConfig returns the configuration used when no config file is given
+func NewDefaultConfig() *Config {
+	return &Config{
+		Data:    defaultConfigData(),
+		Decoder: &defaultsDecoder{},
+	}
+}
+
 // NewConfig returns a configuration
 func NewConfig() (*Config, error) {
 	switch filename := os.Getenv("SNOWBRIDGE_CONFIG_FILE"); filename {
 	case "":
-		return &Config{
-			Data:    defaultConfigData(),
-			Decoder: &defaultsDecoder{},
-		}, nil
+		return NewDefaultConfig(), nil
 
 	default:
 		// read the config file
@@ -234,6 +484,16 @@ func NewConfig() (*Config, error) {
 }
 
 func NewHclConfig(fileContents []byte, filename string) (*Config, error) {
+	return newHclConfig(fileContents, filename, CreateHclContext())
+}
+
+// NewOfflineHclConfig decodes a config like NewHclConfig, without connecting to anything:
+// secret references are checked but not resolved, and decode to the reference itself.
+func NewOfflineHclConfig(fileContents []byte, filename string) (*Config, error) {
+	return newHclConfig(fileContents, filename, createHclContext(checkSecretReference))
+}
+
+func newHclConfig(fileContents []byte, filename string, evalContext *hcl.EvalContext) (*Config, error) {
 
 	// Parsing
 	parser := hclparse.NewParser()
@@ -242,13 +502,10 @@ func NewHclConfig(fileContents []byte, filename string) (*Config, error) {
 		return nil, diags
 	}
 
-	// Creating EvalContext
-	evalContext := CreateHclContext() // ptr
-
 	// Decoding
 	configData := defaultConfigData()
 	decoderOpts := &DecoderOptions{Input: fileHCL.Body}
-	hclDecoder := &hclDecoder{EvalContext: evalContext}
+	hclDecoder := &hclDecoder{EvalContext: evalContext, tracker: secrets.NewTracker()}
 
 	err := hclDecoder.Decode(decoderOpts, configData)
 	if err != nil {
@@ -275,6 +532,11 @@ func (c *Config) CreateComponent(p Pluggable, opts *DecoderOptions) (any, error)
 	return p.Create(decodedConfig)
 }
 
+// DecodeComponent decodes the configuration of a pluggable component given the Decoder options, without creating it.
+func (c *Config) DecodeComponent(p ComponentConfigurable, opts *DecoderOptions) (any, error) {
+	return withDecoderOptions(opts)(p, c.Decoder)
+}
+
 // GetTags returns a list of tags to use in identifying this instance of snowbridge with enough
 // entropy so as to avoid collisions as it should not be possible to have both the host and process_id be
 // the same.
@@ -325,6 +587,24 @@ func (c *Config) GetObserver(appName, appVersion string, tags map[string]string)
 	return observer.New(sr, time.Duration(c.Data.StatsReceiver.BufferSec)*time.Second, metadataReporter), nil
 }
 
+// GetTracer builds and returns the message tracer, or nil when tracing is disabled
+func (c *Config) GetTracer(appVersion string) (*tracing.Tracer, error) {
+	if !c.Data.Tracing.Enabled {
+		return nil, nil
+	}
+
+	if err := c.Data.Tracing.Validate(); err != nil {
+		return nil, err
+	}
+
+	exporter, err := tracing.NewOTLPExporter(c.Data.Tracing.Endpoint, c.Data.Tracing.Headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return tracing.NewTracer(exporter, c.Data.Tracing.ServiceName, appVersion, c.Data.Tracing.SampleRatio), nil
+}
+
 func (c *Config) GetWebhookMonitoring(appName, appVersion string) (*monitoring.WebhookMonitoring, chan error, error) {
 	if c.Data.Monitoring.Webhook.Endpoint == "" {
 		return nil, nil, nil
@@ -346,30 +626,49 @@ func (c *Config) GetWebhookMonitoring(appName, appVersion string) (*monitoring.W
 
 // getStatsReceiver builds and returns the stats receiver
 func (c *Config) getStatsReceiver(tags map[string]string) (statsreceiveriface.StatsReceiver, error) {
+	plug, err := c.statsReceiverPlug(tags)
+	if plug == nil || err != nil {
+		return nil, err
+	}
+
 	useReceiver := c.Data.StatsReceiver.Receiver
-	decoderOpts := &DecoderOptions{
-		Input: useReceiver.Body,
+	component, err := c.CreateComponent(plug, &DecoderOptions{Input: useReceiver.Body})
+	if err != nil {
+		return nil, err
 	}
 
-	switch useReceiver.Name {
-	case "statsd":
-		plug := statsreceiver.AdaptStatsDStatsReceiverFunc(
-			statsreceiver.NewStatsDReceiverWithTags(tags, c.Data.Metrics.E2ELatencyEnabled, c.Data.Metrics.KinsumerMemoryMetricsEnabled),
-		)
-		component, err := c.CreateComponent(plug, decoderOpts)
-		if err != nil {
-			return nil, err
-		}
+	if r, ok := component.(statsreceiveriface.StatsReceiver); ok {
+		return r, nil
+	}
 
-		if r, ok := component.(statsreceiveriface.StatsReceiver); ok {
-			return r, nil
-		}
+	return nil, fmt.Errorf("could not interpret stats receiver configuration for %q", useReceiver.Name)
+}
+
+// ValidateStatsReceiver decodes the configuration of the stats receiver, without creating it
+func (c *Config) ValidateStatsReceiver() error {
+	plug, err := c.statsReceiverPlug(nil)
+	if plug == nil || err != nil {
+		return err
+	}
+	_, err = configure(plug, c.Decoder, &DecoderOptions{Input: c.Data.StatsReceiver.Receiver.Body})
+	return err
+}
+
+// statsReceiverPlug returns the pluggable stats receiver the config uses, or nil when there is none
+func (c *Config) statsReceiverPlug(tags map[string]string) (Pluggable, error) {
+	e2eLatency, kinsumerMemory := c.Data.Metrics.E2ELatencyEnabled, c.Data.Metrics.KinsumerMemoryMetricsEnabled
 
-		return nil, fmt.Errorf("could not interpret stats receiver configuration for %q", useReceiver.Name)
+	switch name := c.Data.StatsReceiver.Receiver.Name; name {
+	case "statsd":
+		return statsreceiver.AdaptStatsDStatsReceiverFunc(statsreceiver.NewStatsDReceiverWithTags(tags, e2eLatency, kinsumerMemory)), nil
+	case "prometheus":
+		return statsreceiver.AdaptPrometheusStatsReceiverFunc(statsreceiver.NewPrometheusReceiverWithTags(tags, e2eLatency, kinsumerMemory)), nil
+	case "otlp":
+		return statsreceiver.AdaptOTLPStatsReceiverFunc(statsreceiver.NewOTLPReceiverWithTags(tags, e2eLatency, kinsumerMemory)), nil
 	case "":
 		return nil, nil
 	default:
-		return nil, errors.New(fmt.Sprintf("Invalid stats receiver found; expected one of 'statsd' and got '%s'", useReceiver.Name))
+		return nil, errors.New(fmt.Sprintf("Invalid stats receiver found; expected one of 'statsd', 'prometheus', 'otlp' and got '%s'", name))
 	}
 }
 
@@ -31,8 +31,8 @@ func TestNewConfig_NoConfig(t *testing.T) {
 
 	assert.Equal("stdin", c.Data.Source.Use.Name)
 	assert.Nil(c.Data.Source.Use.Body)
-	assert.Equal("stdout", c.Data.Target.Target.Name)
-	assert.Nil(c.Data.Target.Target.Body)
+	assert.Equal("stdout", c.Data.Targets[0].Target.Name)
+	assert.Nil(c.Data.Targets[0].Target.Body)
 	assert.Equal("stdout", c.Data.FailureTarget.Target.Name)
 	assert.Nil(c.Data.FailureTarget.Target.Body)
 	assert.Equal("snowplow", c.Data.FailureParser.Format)
@@ -51,6 +51,29 @@ func TestNewConfig_NoConfig(t *testing.T) {
 	assert.Equal(1000, c.Data.Retry.Transient.Delay)
 	assert.Equal(5, c.Data.Retry.Transient.MaxAttempts)
 	assert.Equal(20000, c.Data.Retry.Setup.Delay)
+	assert.Equal(BackoffExponential, c.Data.Retry.Transient.Backoff)
+	assert.Equal(0, c.Data.Retry.Transient.MaxDelay)
+	assert.Equal(0, c.Data.Retry.Transient.Deadline)
+	assert.Nil(c.Data.Retry.Validate())
+	assert.Equal(5000, c.Data.Shutdown.DrainTimeoutMs)
+	assert.Equal(ShutdownPolicyFlush, c.Data.Shutdown.Policy)
+	assert.Nil(c.Data.Shutdown.Validate(c.Data.Spill))
+	assert.False(c.Data.Health.Enabled)
+	assert.Equal(":8080", c.Data.Health.Address)
+	assert.Equal(0, c.Data.Health.ActivityWindowMs)
+	assert.False(c.Data.Health.EnableProfiling)
+	assert.False(c.Data.Tracing.Enabled)
+	assert.Equal("http://localhost:4318/v1/traces", c.Data.Tracing.Endpoint)
+	assert.Equal("snowbridge", c.Data.Tracing.ServiceName)
+	assert.Equal(float64(1), c.Data.Tracing.SampleRatio)
+	assert.False(c.Data.HotReload.Enabled)
+	assert.Equal(5000, c.Data.HotReload.WatchIntervalMs)
+	assert.Equal("", c.Data.Spill.Path)
+	assert.Equal(int64(1073741824), c.Data.Spill.MaxBytes)
+	assert.Equal(10000, c.Data.Spill.DrainIntervalMs)
+	assert.False(c.Data.CircuitBreaker.Enabled)
+	assert.Equal(0.5, c.Data.CircuitBreaker.FailureRate)
+	assert.Equal(10, c.Data.CircuitBreaker.MinRequests)
 }
 
 func TestNewConfig_GetFailureParser(t *testing.T) {
@@ -146,7 +169,7 @@ func TestNewConfig_Hcl_invalids(t *testing.T) {
 		assert.Nil(statsReceiver)
 		assert.NotNil(err)
 		if err != nil {
-			assert.Equal("Invalid stats receiver found; expected one of 'statsd' and got 'fakeHCL'", err.Error())
+			assert.Equal("Invalid stats receiver found; expected one of 'statsd', 'prometheus', 'otlp' and got 'fakeHCL'", err.Error())
 		}
 	})
 }
@@ -181,7 +204,7 @@ func TestNewConfig_Hcl_defaults(t *testing.T) {
 	}
 
 	assert.Equal("stdin", c.Data.Source.Use.Name)
-	assert.Equal("stdout", c.Data.Target.Target.Name)
+	assert.Equal("stdout", c.Data.Targets[0].Target.Name)
 	assert.Equal("stdout", c.Data.FailureTarget.Target.Name)
 	assert.Equal("snowplow", c.Data.FailureParser.Format)
 	assert.Equal("{}", c.Data.Sentry.Tags)
@@ -231,6 +254,203 @@ func TestNewConfig_HclTransformationOrder(t *testing.T) {
 	assert.Equal("five", c.Data.Transform.Transformations[4].Name)
 }
 
+func TestNewConfig_HclMultipleTargets(t *testing.T) {
+	assert := assert.New(t)
+
+	filename := filepath.Join(assets.AssetsRootDir, "test", "config", "configs", "multiple-targets.hcl")
+	t.Setenv("SNOWBRIDGE_CONFIG_FILE", filename)
+
+	c, err := NewConfig()
+	assert.NotNil(c)
+	if err != nil {
+		t.Fatalf("function NewConfig failed with error: %q", err.Error())
+	}
+
+	assert.Equal(2, len(c.Data.Targets))
+	assert.Equal("", c.Data.Targets[0].Name)
+	assert.Equal("kafka", c.Data.Targets[0].Target.Name)
+	assert.Equal("api", c.Data.Targets[1].Name)
+	assert.Equal("http", c.Data.Targets[1].Target.Name)
+}
+
+func TestRetryConfig_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	c := defaultConfigData().Retry
+	c.Throttle.Backoff = "linear"
+	err := c.Validate()
+	if assert.NotNil(err) {
+		assert.Equal("invalid retry.throttle.backoff; expected one of 'fixed', 'exponential', 'exponential_jitter' and got 'linear'", err.Error())
+	}
+
+	c = defaultConfigData().Retry
+	c.Setup.Backoff = ""
+	c.Transient.Backoff = BackoffExponentialJitter
+	assert.Nil(c.Validate())
+
+	c.Transient.MaxDelay = -1
+	err = c.Validate()
+	if assert.NotNil(err) {
+		assert.Equal("invalid retry.transient configuration: max_delay_ms and deadline_ms must not be negative", err.Error())
+	}
+}
+
+func TestCircuitBreakerConfig_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	c := defaultConfigData().CircuitBreaker
+	c.Enabled = true
+	assert.Nil(c.Validate())
+
+	c.FailureRate = 1.5
+	err := c.Validate()
+	if assert.NotNil(err) {
+		assert.Equal("invalid circuit_breaker failure_rate 1.5, must be greater than 0 and at most 1", err.Error())
+	}
+
+	// Settings of a disabled breaker don't matter
+	c.Enabled = false
+	assert.Nil(c.Validate())
+}
+
+func TestHealthConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		Address          string
+		ProfilingAddress string
+		ExpectedError    string
+	}{
+		{Name: "defaults", Address: ":8080", ProfilingAddress: "localhost:6060"},
+		{Name: "same port on other hosts", Address: "10.0.0.1:8080", ProfilingAddress: "localhost:8080"},
+		{Name: "random ports", Address: ":0", ProfilingAddress: "localhost:0"},
+		{
+			Name:             "health on every interface",
+			Address:          ":8080",
+			ProfilingAddress: "localhost:8080",
+			ExpectedError:    `health address ":8080" and profiling_address "localhost:8080" clash, the profiling endpoints need a port of their own`,
+		},
+		{
+			Name:             "loopback by name and IP",
+			Address:          "127.0.0.1:9000",
+			ProfilingAddress: "localhost:9000",
+			ExpectedError:    `health address "127.0.0.1:9000" and profiling_address "localhost:9000" clash, the profiling endpoints need a port of their own`,
+		},
+		{
+			Name:             "no port",
+			Address:          ":8080",
+			ProfilingAddress: "localhost",
+			ExpectedError:    `invalid health profiling_address "localhost": address localhost: missing port in address`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			c := &HealthConfig{Enabled: true, Address: tt.Address, EnableProfiling: true, ProfilingAddress: tt.ProfilingAddress}
+			err := c.Validate()
+			if tt.ExpectedError == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.ExpectedError)
+
+			// Addresses only matter when both servers run
+			c.EnableProfiling = false
+			assert.Nil(t, c.Validate())
+		})
+	}
+}
+
+func TestSpillConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        *SpillConfig
+		ExpectedError string
+	}{
+		{Name: "disabled", Config: &SpillConfig{DrainIntervalMs: -1}},
+		{Name: "encrypted", Config: &SpillConfig{Path: "/tmp/spill", MaxBytes: 1024, DrainIntervalMs: 1000, EncryptionKey: "AAAAAAAAAAAAAAAAAAAAAA=="}},
+		{
+			Name:          "no max bytes",
+			Config:        &SpillConfig{Path: "/tmp/spill", DrainIntervalMs: 1000},
+			ExpectedError: "invalid spill max_bytes 0, must be greater than 0",
+		},
+		{
+			Name:          "no drain interval",
+			Config:        &SpillConfig{Path: "/tmp/spill", MaxBytes: 1024},
+			ExpectedError: "invalid spill drain_interval_ms 0, must be greater than 0",
+		},
+		{
+			Name:          "key not base64",
+			Config:        &SpillConfig{Path: "/tmp/spill", MaxBytes: 1024, DrainIntervalMs: 1000, EncryptionKey: "not a key"},
+			ExpectedError: "failed to decode spill encryption_key: illegal base64 data at input byte 3",
+		},
+		{
+			Name:          "key of the wrong size",
+			Config:        &SpillConfig{Path: "/tmp/spill", MaxBytes: 1024, DrainIntervalMs: 1000, EncryptionKey: "AAAA"},
+			ExpectedError: "invalid spill encryption_key: crypto/aes: invalid key size 3",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := tt.Config.Validate()
+			if tt.ExpectedError == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestShutdownConfig_Validate(t *testing.T) {
+	assert := assert.New(t)
+
+	c := &ShutdownConfig{DrainTimeoutMs: 1000, Policy: "wait"}
+	err := c.Validate(nil)
+	if assert.NotNil(err) {
+		assert.Equal("invalid shutdown policy; expected one of 'flush', 'nack', 'spill' and got 'wait'", err.Error())
+	}
+
+	c.Policy = ShutdownPolicySpill
+	err = c.Validate(&SpillConfig{})
+	if assert.NotNil(err) {
+		assert.Equal("shutdown policy 'spill' requires a spill path to be configured", err.Error())
+	}
+	assert.Nil(c.Validate(&SpillConfig{Path: "/tmp/spill"}))
+
+	c.DrainTimeoutMs = 0
+	err = c.Validate(&SpillConfig{Path: "/tmp/spill"})
+	if assert.NotNil(err) {
+		assert.Equal("invalid shutdown drain_timeout_ms 0, must be greater than 0", err.Error())
+	}
+}
+
+func TestNewConfig_GetTracer(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := NewConfig()
+	assert.Nil(err)
+
+	tracer, err := c.GetTracer("0.0.0")
+	assert.Nil(tracer)
+	assert.Nil(err)
+
+	c.Data.Tracing.Enabled = true
+	c.Data.Tracing.SampleRatio = 1.5
+	tracer, err = c.GetTracer("0.0.0")
+	assert.Nil(tracer)
+	if assert.NotNil(err) {
+		assert.Equal("invalid tracing.sample_ratio; expected a value between 0 and 1 and got 1.5", err.Error())
+	}
+
+	c.Data.Tracing.SampleRatio = 0.5
+	tracer, err = c.GetTracer("0.0.0")
+	assert.Nil(err)
+	if assert.NotNil(tracer) {
+		assert.Nil(tracer.Stop())
+	}
+}
+
 func TestNewConfig_GetMonitoring(t *testing.T) {
 	assert := assert.New(t)
 
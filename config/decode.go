@@ -14,11 +14,16 @@ package config
 import (
 	"errors"
 	"os"
+	"reflect"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
+
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
 )
 
 // Decoder is the interface that wraps the Decode method.
@@ -37,8 +42,10 @@ type DecoderOptions struct {
 }
 
 // hclDecoder implements Decoder.
+// It tracks the fields it sets from a secret, in a tracker which lives as long as the config it decodes.
 type hclDecoder struct {
 	EvalContext *hcl.EvalContext
+	tracker     *secrets.Tracker
 }
 
 // Decode populates target given HCL input through DecoderOptions.
@@ -67,33 +74,149 @@ func (h *hclDecoder) Decode(opts *DecoderOptions, target any) error {
 		return diag
 	}
 
+	if h.tracker != nil {
+		trackSecrets(h.tracker, src, h.EvalContext, reflect.ValueOf(target))
+	}
+	if refreshable, ok := target.(secrets.Refreshable); ok {
+		refreshable.SetSecretTracker(h.tracker)
+	}
 	return nil
 }
 
+// trackSecrets records the fields of target, and of the blocks it holds, which are set from a secret function,
+// so that they are redacted when printed and can be resolved again once the secret is rotated
+func trackSecrets(tracker *secrets.Tracker, body hcl.Body, evalCtx *hcl.EvalContext, target reflect.Value) {
+	v := reflect.Indirect(target)
+	if v.Kind() != reflect.Struct || !v.CanAddr() {
+		return
+	}
+	schema, _ := gohcl.ImpliedBodySchema(v.Addr().Interface())
+	content, _, _ := body.PartialContent(schema)
+	if content == nil {
+		return
+	}
+
+	blockIndexes := make(map[string]int)
+	for _, block := range content.Blocks {
+		field, ok := hclField(v, block.Type)
+		if !ok {
+			continue
+		}
+		if field.Kind() == reflect.Slice {
+			i := blockIndexes[block.Type]
+			blockIndexes[block.Type]++
+			if i >= field.Len() {
+				continue
+			}
+			field = field.Index(i)
+		}
+		trackSecrets(tracker, block.Body, evalCtx, field)
+	}
+
+	for name, attr := range content.Attributes {
+		field, ok := hclField(v, name)
+		if !ok {
+			continue
+		}
+		if ref, isSecret := secretReference(attr.Expr, evalCtx); isSecret {
+			tracker.Track(field.Addr().Interface(), ref)
+		}
+	}
+}
+
+// hclField returns the field of v decoded from the attribute or block called name
+func hclField(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := range v.NumField() {
+		if tagName, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("hcl"), ","); tagName == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// secretReference reports whether an expression calls a secret function.
+// It returns the reference resolved when the expression is a single call, and its value is the secret alone.
+func secretReference(expr hcl.Expression, evalCtx *hcl.EvalContext) (string, bool) {
+	syntaxExpr, ok := expr.(hclsyntax.Expression)
+	if !ok {
+		return "", false
+	}
+
+	if call, ok := syntaxExpr.(*hclsyntax.FunctionCallExpr); ok && len(call.Args) == 1 {
+		if prefix, isSecret := secretFunctions[call.Name]; isSecret {
+			arg, diags := call.Args[0].Value(evalCtx)
+			if !diags.HasErrors() && arg.IsKnown() && !arg.IsNull() && arg.Type() == cty.String {
+				return prefix + arg.AsString(), true
+			}
+			return "", true
+		}
+	}
+
+	found := false
+	_ = hclsyntax.VisitAll(syntaxExpr, func(node hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := node.(*hclsyntax.FunctionCallExpr); ok {
+			if _, isSecret := secretFunctions[call.Name]; isSecret {
+				found = true
+			}
+		}
+		return nil
+	})
+	return "", found
+}
+
 // CreateHclContext creates an *hcl.EvalContext that is used in decoding HCL.
 // Here we can add the evaluation features available for the HCL configuration
 // users.
-// For now, below is an example of 2 different ways users can reference
-// environment variables in their HCL configuration file.
+// Users can reference environment variables in 2 different ways, and secrets
+// held in files or secret managers.
 func CreateHclContext() *hcl.EvalContext {
+	return createHclContext(secrets.Resolve)
+}
+
+// createHclContext creates an *hcl.EvalContext in which secret references are resolved with resolve
+func createHclContext(resolve func(ref string) (string, error)) *hcl.EvalContext {
 	evalCtx := &hcl.EvalContext{
-		Functions: hclCtxFunctions(),
+		Functions: hclCtxFunctions(resolve),
 		Variables: hclCtxVariables(),
 	}
 
 	return evalCtx
 }
 
+// checkSecretReference stands in for resolving a secret when the config is checked offline.
+// The reference is checked, and is the value the secret decodes to.
+func checkSecretReference(ref string) (string, error) {
+	if err := secrets.Check(ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// secretFunctions are the HCL functions returning a secret,
+// along with the prefix they add to their argument to make a secret reference
+var secretFunctions = map[string]string{
+	"file":   secrets.SchemeFile + "://",
+	"secret": "",
+}
+
 // hclCtxFunctions constracts the Functions map of the hcl.EvalContext
 // Here, for example, we add the `env` as function.
 // Users can reference any env var as `env("MY_ENV_VAR")` e.g.
 // ```
 // listen_addr = env("LISTEN_ADDR")
 // ```
-func hclCtxFunctions() map[string]function.Function {
+// Secrets can be read from files with `file`, or from secret managers with `secret` e.g.
+// ```
+// password      = file("/run/secrets/password")
+// client_secret = secret("aws-sm://my-secret#client_secret")
+// ```
+func hclCtxFunctions(resolve func(ref string) (string, error)) map[string]function.Function {
 	funcs := map[string]function.Function{
 		"env": envFunc(),
 	}
+	for name, prefix := range secretFunctions {
+		funcs[name] = secretFunc(prefix, resolve)
+	}
 
 	return funcs
 }
@@ -133,6 +256,30 @@ func envFunc() function.Function {
 	})
 }
 
+// secretFunc constructs a cty.Function that takes a reference as string argument,
+// prefixed with prefix, and returns the value of the secret behind it as resolved by resolve.
+// Values are resolved while decoding, and are never part of the returned errors.
+func secretFunc(prefix string, resolve func(ref string) (string, error)) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{
+			{
+				Name:         "ref",
+				Type:         cty.String,
+				AllowNull:    false,
+				AllowUnknown: false,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			value, err := resolve(prefix + args[0].AsString())
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(value), nil
+		},
+	})
+}
+
 // envVarsMap constructs a map of the environment variables to be used in
 // hcl.EvalContext
 func envVarsMap(environ []string) map[string]cty.Value {
@@ -12,6 +12,8 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -19,6 +21,8 @@ import (
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
 )
 
 type testStruct struct {
@@ -27,7 +31,7 @@ type testStruct struct {
 
 func TestHclDecode(t *testing.T) {
 	evalCtx := &hcl.EvalContext{}
-	hclDecoder := hclDecoder{evalCtx}
+	hclDecoder := hclDecoder{EvalContext: evalCtx}
 	hclSrc := `
 test_string = "ateststring"
 `
@@ -99,7 +103,7 @@ func TestCreateHclContext(t *testing.T) {
 	}
 
 	evalCtx := CreateHclContext()
-	hclDecoder := hclDecoder{evalCtx}
+	hclDecoder := hclDecoder{EvalContext: evalCtx}
 	hclSrc := `
 test_string = env.TEST_STRING
 test_int = env("TEST_INT")
@@ -148,3 +152,136 @@ test_int = env("TEST_INT")
 		})
 	}
 }
+
+func TestCreateHclContext_Secrets(t *testing.T) {
+	assert := assert.New(t)
+
+	secretPath := filepath.Join(t.TempDir(), "password")
+	assert.Nil(os.WriteFile(secretPath, []byte("file-password\n"), 0600))
+	t.Setenv("SECRET_PATH", secretPath)
+
+	type testHclStruct struct {
+		FromFile   string `hcl:"from_file"`
+		FromSecret string `hcl:"from_secret"`
+	}
+
+	hclSrc := `
+from_file = file(env.SECRET_PATH)
+from_secret = secret("file://${env.SECRET_PATH}")
+`
+	p := hclparse.NewParser()
+	hclFile, diags := p.ParseHCL([]byte(hclSrc), "placeholder.hcl")
+	if diags.HasErrors() {
+		t.Errorf("Failed parsing HCL test source")
+	}
+
+	target := &testHclStruct{}
+	err := (&hclDecoder{EvalContext: CreateHclContext()}).Decode(&DecoderOptions{Input: hclFile.Body}, target)
+	assert.Nil(err)
+	assert.Equal(&testHclStruct{FromFile: "file-password", FromSecret: "file-password"}, target)
+
+	hclFile, diags = p.ParseHCL([]byte(`from_file = secret("vault://password")`), "invalid.hcl")
+	if diags.HasErrors() {
+		t.Errorf("Failed parsing HCL test source")
+	}
+	err = (&hclDecoder{EvalContext: CreateHclContext()}).Decode(&DecoderOptions{Input: hclFile.Body}, &struct {
+		FromFile string `hcl:"from_file"`
+	}{})
+	assert.ErrorContains(err, `unsupported secret reference scheme "vault"`)
+}
+
+func TestHclDecoder_TracksSecrets(t *testing.T) {
+	assert := assert.New(t)
+
+	secretPath := filepath.Join(t.TempDir(), "password")
+	assert.Nil(os.WriteFile(secretPath, []byte("file-password\n"), 0600))
+	t.Setenv("SECRET_PATH", secretPath)
+
+	type testHclBlock struct {
+		Password string `hcl:"password"`
+	}
+	type testHclStruct struct {
+		Plain        string          `hcl:"plain"`
+		Interpolated string          `hcl:"interpolated"`
+		Block        *testHclBlock   `hcl:"block,block"`
+		Blocks       []*testHclBlock `hcl:"blocks,block"`
+	}
+
+	hclSrc := `
+plain = "file-password"
+interpolated = "user:${file(env.SECRET_PATH)}"
+block {
+  password = file(env.SECRET_PATH)
+}
+blocks {
+  password = "file-password"
+}
+blocks {
+  password = secret("file://${env.SECRET_PATH}")
+}
+`
+	p := hclparse.NewParser()
+	hclFile, diags := p.ParseHCL([]byte(hclSrc), "placeholder.hcl")
+	if diags.HasErrors() {
+		t.Errorf("Failed parsing HCL test source")
+	}
+
+	target := &testHclStruct{}
+	tracker := secrets.NewTracker()
+	err := (&hclDecoder{EvalContext: CreateHclContext(), tracker: tracker}).Decode(&DecoderOptions{Input: hclFile.Body}, target)
+	assert.Nil(err)
+
+	// Fields are tracked by where they are set from, not by their value
+	assert.False(tracker.IsTracked(&target.Plain))
+	assert.True(tracker.IsTracked(&target.Interpolated))
+	assert.True(tracker.IsTracked(&target.Block.Password))
+	assert.False(tracker.IsTracked(&target.Blocks[0].Password))
+	assert.True(tracker.IsTracked(&target.Blocks[1].Password))
+
+	// Fields holding the secret alone can be resolved again, others keep their value
+	assert.Nil(os.WriteFile(secretPath, []byte("rotated-password\n"), 0600))
+	password, err := tracker.Refresh(&target.Block.Password)
+	assert.Nil(err)
+	assert.Equal("rotated-password", password)
+	interpolated, err := tracker.Refresh(&target.Interpolated)
+	assert.Nil(err)
+	assert.Equal("user:file-password", interpolated)
+}
+
+// refreshableHclStruct keeps hold of the tracker it is decoded with
+type refreshableHclStruct struct {
+	Password string `hcl:"password"`
+
+	tracker *secrets.Tracker
+}
+
+func (r *refreshableHclStruct) SetSecretTracker(t *secrets.Tracker) {
+	r.tracker = t
+}
+
+func TestHclDecoder_SetsSecretTracker(t *testing.T) {
+	assert := assert.New(t)
+
+	secretPath := filepath.Join(t.TempDir(), "password")
+	assert.Nil(os.WriteFile(secretPath, []byte("file-password\n"), 0600))
+	t.Setenv("SECRET_PATH", secretPath)
+
+	c, err := NewHclConfig([]byte(`
+target {
+  use "test" {
+    password = file(env.SECRET_PATH)
+  }
+}
+`), "test.hcl")
+	assert.Nil(err)
+
+	// Components are tracked along with the config they are decoded from, and only by it
+	target := &refreshableHclStruct{}
+	assert.Nil(c.Decoder.Decode(&DecoderOptions{Input: c.Data.Targets[0].Target.Body}, target))
+	assert.Same(c.secretTracker(), target.tracker)
+	assert.True(target.tracker.IsTracked(&target.Password))
+
+	other, err := NewHclConfig([]byte(``), "other.hcl")
+	assert.Nil(err)
+	assert.False(other.secretTracker().IsTracked(&target.Password))
+}
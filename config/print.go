@@ -0,0 +1,333 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty/gocty"
+
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
+	"github.com/snowplow/snowbridge/v5/pkg/statsreceiver"
+)
+
+// Formats the effective config can be printed in
+const (
+	PrintFormatHCL  = "hcl"
+	PrintFormatJSON = "json"
+)
+
+// redactedValue replaces the value of fields tagged `sensitive:"true"`, or set from a secret, whenever the config is printed
+const redactedValue = "REDACTED"
+
+// WrappingConfig is implemented by the configuration of a component wrapping a target, configured in a nested target block
+type WrappingConfig interface {
+	// WrappedTarget returns the nested target block, and the decoded configuration of the wrapped target
+	WrappedTarget() (*TargetConfig, any)
+}
+
+// ComponentConfigs holds the decoded configuration of the components configured in `use` blocks,
+// which are decoded by the packages implementing them, in the order they appear in the config.
+// The stats receiver is decoded here.
+type ComponentConfigs struct {
+	Source          any
+	Targets         []any
+	FailureTarget   any
+	FilterTarget    any
+	Transformations []any
+}
+
+// Print writes the effective configuration to out, with the defaults of the config and of each component filled in,
+// and the value of sensitive fields and of fields set from a secret redacted.
+func (c *Config) Print(out io.Writer, format string, components *ComponentConfigs) error {
+	resolved, err := c.resolveComponents(components)
+	if err != nil {
+		return err
+	}
+
+	body, err := printBody(reflect.ValueOf(c.Data), resolved, c.secretTracker())
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case PrintFormatHCL:
+		f := hclwrite.NewEmptyFile()
+		if err := body.writeHCL(f.Body(), true); err != nil {
+			return err
+		}
+		_, err = out.Write(f.Bytes())
+		return err
+	case PrintFormatJSON:
+		rendered, err := json.MarshalIndent(body.toJSON(), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(rendered))
+		return err
+	default:
+		return fmt.Errorf("invalid config format found; expected one of '%s', '%s' and got '%s'", PrintFormatHCL, PrintFormatJSON, format)
+	}
+}
+
+// resolveComponents maps each `use` block to the decoded configuration of the component it configures
+func (c *Config) resolveComponents(components *ComponentConfigs) (map[*use]any, error) {
+	resolved := make(map[*use]any)
+	if components != nil {
+		resolved[c.Data.Source.Use] = components.Source
+		for i, target := range c.Data.Targets {
+			if i < len(components.Targets) {
+				resolved[target.Target] = components.Targets[i]
+			}
+		}
+		resolved[c.Data.FailureTarget.Target] = components.FailureTarget
+		resolved[c.Data.FilterTarget.Target] = components.FilterTarget
+		if c.Data.Transform != nil {
+			for i, transformation := range c.Data.Transform.Transformations {
+				if i < len(components.Transformations) {
+					resolved[transformation] = components.Transformations[i]
+				}
+			}
+		}
+	}
+
+	statsReceiver, err := c.decodeStatsReceiver()
+	if err != nil {
+		return nil, err
+	}
+	resolved[c.Data.StatsReceiver.Receiver] = statsReceiver
+	return resolved, nil
+}
+
+// decodeStatsReceiver decodes the configuration of the stats receiver, without creating it
+func (c *Config) decodeStatsReceiver() (any, error) {
+	useReceiver := c.Data.StatsReceiver.Receiver
+
+	var plug ComponentConfigurable
+	switch useReceiver.Name {
+	case "statsd":
+		plug = statsreceiver.AdaptStatsDStatsReceiverFunc(nil)
+	case "prometheus":
+		plug = statsreceiver.AdaptPrometheusStatsReceiverFunc(nil)
+	case "otlp":
+		plug = statsreceiver.AdaptOTLPStatsReceiverFunc(nil)
+	default:
+		return nil, nil
+	}
+	return c.DecodeComponent(plug, &DecoderOptions{Input: useReceiver.Body})
+}
+
+// printedBody is a body of the config to print, holding attributes and blocks in the order they are declared
+type printedBody struct {
+	items []printedItem
+}
+
+// printedItem is either an attribute with its value, or a block with its labels and body
+type printedItem struct {
+	name  string
+	value any
+
+	labels   []string
+	body     *printedBody
+	repeated bool
+}
+
+// secretTracker returns the tracker of the fields set from a secret, which only configs decoded from HCL have
+func (c *Config) secretTracker() *secrets.Tracker {
+	if decoder, ok := c.Decoder.(*hclDecoder); ok {
+		return decoder.tracker
+	}
+	return nil
+}
+
+// printBody builds the body to print from a struct decoded from HCL, following its `hcl` tags.
+// The remaining body of `use` blocks is replaced by the resolved configuration of the component.
+func printBody(v reflect.Value, resolved map[*use]any, tracker *secrets.Tracker) (*printedBody, error) {
+	if u, ok := v.Interface().(*use); ok {
+		if componentConfig := resolved[u]; componentConfig != nil {
+			return printBody(reflect.ValueOf(componentConfig), resolved, tracker)
+		}
+		return &printedBody{}, nil
+	}
+
+	// The nested target block of a wrapping component prints the configuration of the wrapped target
+	if wrapping, ok := v.Interface().(WrappingConfig); ok {
+		if targetCfg, wrappedConfig := wrapping.WrappedTarget(); targetCfg != nil && targetCfg.Target != nil {
+			resolved[targetCfg.Target] = wrappedConfig
+		}
+	}
+
+	v = reflect.Indirect(v)
+	body := &printedBody{}
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+		name, kind, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		switch kind {
+		case "label", "remain":
+			continue
+		case "block":
+			blocks, err := printBlocks(name, value, resolved, tracker)
+			if err != nil {
+				return nil, err
+			}
+			body.items = append(body.items, blocks...)
+		default:
+			if isNil(value) {
+				continue
+			}
+			attr := reflect.Indirect(value).Interface()
+			if field.Tag.Get("sensitive") == "true" || value.CanAddr() && tracker.IsTracked(value.Addr().Interface()) {
+				attr = redact(attr)
+			}
+			body.items = append(body.items, printedItem{name: name, value: attr})
+		}
+	}
+	return body, nil
+}
+
+// printBlocks returns the blocks held by a block field, which is either a single block or a slice of them
+func printBlocks(name string, value reflect.Value, resolved map[*use]any, tracker *secrets.Tracker) ([]printedItem, error) {
+	if value.Kind() == reflect.Slice {
+		items := make([]printedItem, 0, value.Len())
+		for i := range value.Len() {
+			item, err := printBlock(name, value.Index(i), resolved, tracker)
+			if err != nil {
+				return nil, err
+			}
+			item.repeated = true
+			items = append(items, item)
+		}
+		return items, nil
+	}
+
+	// Components that aren't configured, like the stats receiver by default, are left out
+	if u, ok := value.Interface().(*use); isNil(value) || ok && u.Name == "" {
+		return nil, nil
+	}
+	item, err := printBlock(name, value, resolved, tracker)
+	if err != nil {
+		return nil, err
+	}
+	return []printedItem{item}, nil
+}
+
+// printBlock returns a block with the labels and body of the struct it was decoded into
+func printBlock(name string, value reflect.Value, resolved map[*use]any, tracker *secrets.Tracker) (printedItem, error) {
+	body, err := printBody(value, resolved, tracker)
+	if err != nil {
+		return printedItem{}, err
+	}
+
+	var labels []string
+	v := reflect.Indirect(value)
+	for i := range v.NumField() {
+		if _, kind, _ := strings.Cut(v.Type().Field(i).Tag.Get("hcl"), ","); kind == "label" {
+			labels = append(labels, v.Field(i).String())
+		}
+	}
+	return printedItem{name: name, labels: labels, body: body}, nil
+}
+
+// isNil reports whether a value is a nil pointer, map or slice
+func isNil(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// redact replaces a sensitive value, keeping the keys of maps. Unset values are kept to show that they are unset.
+func redact(value any) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		return redactedValue
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for key := range v {
+			redacted[key] = redactedValue
+		}
+		return redacted
+	default:
+		return redactedValue
+	}
+}
+
+// writeHCL writes the body as HCL, separating top level blocks with an empty line
+func (b *printedBody) writeHCL(body *hclwrite.Body, topLevel bool) error {
+	for i, item := range b.items {
+		if item.body == nil {
+			if topLevel && i > 0 && b.items[i-1].body != nil {
+				body.AppendNewline()
+			}
+			ty, err := gocty.ImpliedType(item.value)
+			if err != nil {
+				return fmt.Errorf("failed to print %s: %w", item.name, err)
+			}
+			value, err := gocty.ToCtyValue(item.value, ty)
+			if err != nil {
+				return fmt.Errorf("failed to print %s: %w", item.name, err)
+			}
+			body.SetAttributeValue(item.name, value)
+			continue
+		}
+
+		if topLevel {
+			body.AppendNewline()
+		}
+		block := body.AppendNewBlock(item.name, item.labels)
+		if err := item.body.writeHCL(block.Body(), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toJSON returns the body following the HCL JSON syntax: labelled blocks nest under their labels,
+// and blocks which can be repeated are lists.
+func (b *printedBody) toJSON() map[string]any {
+	object := make(map[string]any, len(b.items))
+	for _, item := range b.items {
+		if item.body == nil {
+			object[item.name] = item.value
+			continue
+		}
+
+		var block any = item.body.toJSON()
+		for i := len(item.labels) - 1; i >= 0; i-- {
+			block = map[string]any{item.labels[i]: block}
+		}
+
+		if !item.repeated {
+			object[item.name] = block
+			continue
+		}
+		blocks, _ := object[item.name].([]any)
+		object[item.name] = append(blocks, block)
+	}
+	return object
+}
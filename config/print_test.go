@@ -0,0 +1,216 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// printTestSource stands in for the configuration of a component decoded by another package
+type printTestSource struct {
+	Address  string            `hcl:"address"`
+	Password string            `hcl:"password,optional" sensitive:"true"`
+	Headers  map[string]string `hcl:"headers,optional" sensitive:"true"`
+	Unset    string            `hcl:"unset,optional" sensitive:"true"`
+	Internal string
+}
+
+func newPrintTestConfig(t *testing.T) *Config {
+	c, err := NewHclConfig([]byte(`
+source {
+  use "test" {
+    address  = "localhost:1234"
+    password = "hunter2"
+  }
+}
+
+sentry {
+  dsn = "https://key@sentry.example.com/1"
+}
+
+stats_receiver {
+  use "statsd" {
+    address = "localhost:8125"
+  }
+}
+
+spill {
+  path           = "/tmp/spill"
+  encryption_key = "0123456789abcdef"
+}
+
+log_level = "debug"
+`), "test.hcl")
+	require.NoError(t, err)
+	return c
+}
+
+func TestConfig_Print_HCL(t *testing.T) {
+	assert := assert.New(t)
+	c := newPrintTestConfig(t)
+
+	var out bytes.Buffer
+	err := c.Print(&out, PrintFormatHCL, &ComponentConfigs{
+		Source: &printTestSource{Address: "localhost:1234", Password: "hunter2", Headers: map[string]string{"Authorization": "Bearer abc"}, Internal: "hidden"},
+	})
+	assert.NoError(err)
+
+	printed := out.String()
+	assert.Contains(printed, `source {
+  use "test" {
+    address  = "localhost:1234"
+    password = "REDACTED"
+    headers = {
+      Authorization = "REDACTED"
+    }
+    unset = ""
+  }
+}
+`)
+	assert.NotContains(printed, "hunter2")
+	assert.NotContains(printed, "Bearer")
+	assert.NotContains(printed, "hidden")
+
+	// Sensitive fields of the config itself are redacted, and the defaults of the stats receiver are filled in
+	assert.Contains(printed, "dsn   = \"REDACTED\"")
+	assert.Contains(printed, "encryption_key    = \"REDACTED\"")
+	assert.Contains(printed, "use \"statsd\" {\n    address = \"localhost:8125\"\n    prefix  = \"snowplow.snowbridge\"")
+	assert.Contains(printed, "\nlog_level         = \"debug\"\n")
+
+	// The printed config can be read back
+	_, err = NewHclConfig(out.Bytes(), "printed.hcl")
+	assert.NoError(err)
+}
+
+func TestConfig_Print_JSON(t *testing.T) {
+	assert := assert.New(t)
+	c := newPrintTestConfig(t)
+
+	var out bytes.Buffer
+	err := c.Print(&out, PrintFormatJSON, &ComponentConfigs{
+		Source: &printTestSource{Address: "localhost:1234", Password: "hunter2"},
+	})
+	assert.NoError(err)
+
+	var printed map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &printed))
+
+	assert.Equal(map[string]any{
+		"use": map[string]any{
+			"test": map[string]any{
+				"address":  "localhost:1234",
+				"password": "REDACTED",
+				"unset":    "",
+			},
+		},
+	}, printed["source"])
+	assert.Equal("REDACTED", printed["sentry"].(map[string]any)["dsn"])
+	assert.Equal("debug", printed["log_level"])
+
+	// Blocks that can be repeated are lists
+	targets, ok := printed["target"].([]any)
+	if assert.True(ok) {
+		assert.Len(targets, 1)
+	}
+}
+
+func TestConfig_Print_Secrets(t *testing.T) {
+	assert := assert.New(t)
+
+	secretPath := filepath.Join(t.TempDir(), "address")
+	require.NoError(t, os.WriteFile(secretPath, []byte("secret-host:1234"), 0600))
+	t.Setenv("SECRET_PATH", secretPath)
+
+	c, err := NewHclConfig([]byte(`
+source {
+  use "test" {
+    address = file(env.SECRET_PATH)
+  }
+}
+`), "test.hcl")
+	require.NoError(t, err)
+
+	source := &printTestSource{}
+	require.NoError(t, c.Decoder.Decode(&DecoderOptions{Input: c.Data.Source.Use.Body}, source))
+	assert.Equal("secret-host:1234", source.Address)
+
+	// Values set from a secret are redacted, even in fields which are not sensitive
+	var out bytes.Buffer
+	assert.NoError(c.Print(&out, PrintFormatHCL, &ComponentConfigs{
+		Source:  source,
+		Targets: []any{&printTestSource{Address: "localhost:1234", Headers: map[string]string{"Authorization": "secret-host:1234"}}},
+	}))
+	assert.NotContains(out.String(), "secret-host")
+	assert.Regexp(`address\s+= "REDACTED"`, out.String())
+	assert.Contains(out.String(), `address  = "localhost:1234"`)
+}
+
+// printTestWrapper stands in for the configuration of a target wrapping another one
+type printTestWrapper struct {
+	Target      *TargetConfig `hcl:"target,block"`
+	Probability float64       `hcl:"probability,optional"`
+
+	wrappedConfig any
+}
+
+func (w *printTestWrapper) WrappedTarget() (*TargetConfig, any) {
+	return w.Target, w.wrappedConfig
+}
+
+func TestConfig_Print_WrappedTarget(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := NewHclConfig([]byte(`
+target {
+  use "wrapper" {
+    probability = 0.5
+
+    target {
+      use "test" {
+        address  = "localhost:1234"
+        password = "hunter2"
+      }
+    }
+  }
+}
+`), "test.hcl")
+	require.NoError(t, err)
+
+	wrapper := &printTestWrapper{}
+	require.NoError(t, c.Decoder.Decode(&DecoderOptions{Input: c.Data.Targets[0].Target.Body}, wrapper))
+	wrapper.wrappedConfig = &printTestSource{Address: "localhost:1234", Password: "hunter2"}
+
+	// The wrapped target is printed with its own configuration
+	var out bytes.Buffer
+	assert.NoError(c.Print(&out, PrintFormatHCL, &ComponentConfigs{Targets: []any{wrapper}}))
+	assert.Contains(out.String(), `use "test" {
+        address  = "localhost:1234"
+        password = "REDACTED"`)
+	assert.NotContains(out.String(), "hunter2")
+}
+
+func TestConfig_Print_InvalidFormat(t *testing.T) {
+	assert := assert.New(t)
+	c := newPrintTestConfig(t)
+
+	var out bytes.Buffer
+	err := c.Print(&out, "yaml", nil)
+	assert.EqualError(err, "invalid config format found; expected one of 'hcl', 'json' and got 'yaml'")
+	assert.Empty(out.String())
+}
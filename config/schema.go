@@ -0,0 +1,217 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/snowplow/snowbridge/v5/pkg/statsreceiver"
+)
+
+// schemaDialect is the JSON Schema version the config schema follows
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ComponentDefaults holds the default configuration of every component which can be configured in a `use` block, by name.
+// Sources, targets and transformations are registered by the packages implementing them, stats receivers are known here.
+type ComponentDefaults struct {
+	Sources         map[string]any
+	Targets         map[string]any
+	Transformations map[string]any
+}
+
+// Schema returns a JSON Schema for the JSON form of the configuration, as printed by Print.
+// It describes the type, default and whether it is required of every attribute, including those of every component.
+func Schema(components *ComponentDefaults) (map[string]any, error) {
+	statsReceivers := make(map[string]any)
+	for name, plug := range map[string]ComponentConfigurable{
+		"statsd":     statsreceiver.AdaptStatsDStatsReceiverFunc(nil),
+		"prometheus": statsreceiver.AdaptPrometheusStatsReceiverFunc(nil),
+		"otlp":       statsreceiver.AdaptOTLPStatsReceiverFunc(nil),
+	} {
+		defaults, err := plug.ProvideDefault()
+		if err != nil {
+			return nil, err
+		}
+		statsReceivers[name] = defaults
+	}
+
+	// The components which can be used, for each top level block holding a `use` block
+	uses := map[string]map[string]any{
+		"source":         components.Sources,
+		"target":         components.Targets,
+		"failure_target": components.Targets,
+		"filter_target":  components.Targets,
+		"transform":      components.Transformations,
+		"stats_receiver": statsReceivers,
+	}
+
+	schema, err := objectSchema(reflect.ValueOf(defaultConfigData()), true, func(block string) map[string]any {
+		return uses[block]
+	})
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = schemaDialect
+	schema["title"] = "Snowbridge configuration"
+	return schema, nil
+}
+
+// objectSchema returns the schema of a body decoded into a struct, following its `hcl` tags.
+// Defaults of optional attributes are taken from the struct when hasDefaults is set.
+// usable returns the components which can be used in the `use` blocks found in the named block of this body.
+func objectSchema(v reflect.Value, hasDefaults bool, usable func(block string) map[string]any) (map[string]any, error) {
+	v = reflect.Indirect(v)
+	properties := make(map[string]any)
+	required := make([]string, 0)
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		tag, ok := field.Tag.Lookup("hcl")
+		if !ok {
+			continue
+		}
+		name, kind, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		switch kind {
+		case "label", "remain":
+			continue
+		case "block":
+			if _, ok := value.Interface().(*use); ok || value.Type() == reflect.TypeFor[[]*use]() {
+				useBlockSchema, err := useSchema(value.Kind() == reflect.Slice, usable(name))
+				if err != nil {
+					return nil, err
+				}
+				properties[name] = useBlockSchema
+				continue
+			}
+
+			// `use` blocks anywhere under a top level block can use the components registered for it
+			nestedUsable := func(string) map[string]any { return usable(name) }
+
+			elem := value
+			repeated := value.Kind() == reflect.Slice
+			if repeated {
+				elem = reflect.New(value.Type().Elem()).Elem()
+			}
+			blockHasDefaults := hasDefaults && !repeated && !isNil(elem)
+			if isNil(elem) {
+				elem = reflect.New(elem.Type().Elem())
+			}
+
+			blockSchema, err := objectSchema(elem, blockHasDefaults, nestedUsable)
+			if err != nil {
+				return nil, err
+			}
+			if repeated {
+				blockSchema = map[string]any{"type": "array", "items": blockSchema}
+			}
+			properties[name] = blockSchema
+		default:
+			attrSchema, err := attributeSchema(field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe %s: %w", name, err)
+			}
+			if hasDefaults && kind != "" && !isNil(value) {
+				attrSchema["default"] = reflect.Indirect(value).Interface()
+			}
+			if field.Tag.Get("sensitive") == "true" {
+				attrSchema["writeOnly"] = true
+			}
+			properties[name] = attrSchema
+			if kind == "" {
+				required = append(required, name)
+			}
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// useSchema returns the schema of `use` blocks, which nest the configuration of a component under its name
+func useSchema(repeated bool, components map[string]any) (map[string]any, error) {
+	// A component nested in the configuration of another one, such as the target a chaos target wraps,
+	// can be any of those registered for its block, which are not described again
+	if components == nil {
+		schema := map[string]any{
+			"type":          "object",
+			"minProperties": 1,
+			"maxProperties": 1,
+		}
+		if repeated {
+			return map[string]any{"type": "array", "items": schema}, nil
+		}
+		return schema, nil
+	}
+
+	properties := make(map[string]any, len(components))
+	for name, defaults := range components {
+		componentSchema, err := objectSchema(reflect.ValueOf(defaults), true, func(string) map[string]any { return nil })
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s: %w", name, err)
+		}
+		properties[name] = componentSchema
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+		"minProperties":        1,
+		"maxProperties":        1,
+	}
+	if repeated {
+		return map[string]any{"type": "array", "items": schema}, nil
+	}
+	return schema, nil
+}
+
+// attributeSchema returns the schema of an attribute of the given type
+func attributeSchema(t reflect.Type) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return attributeSchema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice:
+		items, err := attributeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := attributeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
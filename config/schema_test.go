@@ -0,0 +1,86 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaProperty walks the properties of a schema along path
+func schemaProperty(t *testing.T, schema map[string]any, path ...string) map[string]any {
+	t.Helper()
+	for _, name := range path {
+		properties, ok := schema["properties"].(map[string]any)
+		require.True(t, ok, "no properties above %s", name)
+		schema, ok = properties[name].(map[string]any)
+		require.True(t, ok, "no property %s", name)
+	}
+	return schema
+}
+
+func TestSchema(t *testing.T) {
+	assert := assert.New(t)
+
+	source := printTestSource{Address: "localhost:1234"}
+	schema, err := Schema(&ComponentDefaults{
+		Sources:         map[string]any{"test": &source},
+		Targets:         map[string]any{},
+		Transformations: map[string]any{},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(schemaDialect, schema["$schema"])
+	assert.Equal(false, schema["additionalProperties"])
+
+	logLevel := schemaProperty(t, schema, "log_level")
+	assert.Equal("string", logLevel["type"])
+	assert.Equal("info", logLevel["default"])
+
+	sentry := schemaProperty(t, schema, "sentry")
+	assert.Equal([]string{"dsn"}, sentry["required"])
+	dsn := schemaProperty(t, sentry, "dsn")
+	assert.Equal(true, dsn["writeOnly"])
+	assert.NotContains(dsn, "default")
+
+	targets := schemaProperty(t, schema, "target")
+	assert.Equal("array", targets["type"])
+
+	sourceUse := schemaProperty(t, schema, "source", "use")
+	assert.Equal(1, sourceUse["minProperties"])
+	assert.Equal(1, sourceUse["maxProperties"])
+
+	testSource := schemaProperty(t, sourceUse, "test")
+	assert.Equal([]string{"address"}, testSource["required"])
+	assert.Equal(map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}, "writeOnly": true}, schemaProperty(t, testSource, "headers"))
+	assert.NotContains(testSource["properties"], "Internal")
+
+	statsd := schemaProperty(t, schema, "stats_receiver", "use", "statsd")
+	assert.Equal("object", statsd["type"])
+}
+
+func TestSchema_WrappedTarget(t *testing.T) {
+	schema, err := Schema(&ComponentDefaults{
+		Sources:         map[string]any{},
+		Targets:         map[string]any{"wrapper": &printTestWrapper{}},
+		Transformations: map[string]any{},
+	})
+	require.NoError(t, err)
+
+	// The wrapped target can be any target, which is not described again
+	targets, ok := schemaProperty(t, schema, "target")["items"].(map[string]any)
+	require.True(t, ok)
+	wrapper := schemaProperty(t, targets, "use", "wrapper")
+	assert.Equal(t, map[string]any{"type": "object", "minProperties": 1, "maxProperties": 1}, schemaProperty(t, wrapper, "target", "use"))
+}
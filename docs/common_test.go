@@ -43,7 +43,8 @@ func checkComponentForZeros(t *testing.T, component any) {
 	var zerosFound []string
 
 	for i := 0; i < typeOfComponent.NumField(); i++ {
-		if valOfComponent.Field(i).IsZero() {
+		// Unexported fields are not configured
+		if typeOfComponent.Field(i).IsExported() && valOfComponent.Field(i).IsZero() {
 			zerosFound = append(zerosFound, typeOfComponent.Field(i).Name)
 		}
 	}
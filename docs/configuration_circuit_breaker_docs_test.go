@@ -0,0 +1,34 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+	filePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "circuit-breaker-example.hcl")
+	c := getConfigFromFilepath(t, filePath)
+
+	breakerConfig := c.Data.CircuitBreaker
+	assert.NotNil(breakerConfig)
+	assert.True(breakerConfig.Enabled)
+	assert.Equal(0.8, breakerConfig.FailureRate)
+	assert.Equal(20, breakerConfig.MinRequests)
+	assert.Equal(30000, breakerConfig.WindowMs)
+	assert.Equal(10000, breakerConfig.OpenDurationMs)
+}
@@ -0,0 +1,34 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+	healthFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "health-example.hcl")
+	c := getConfigFromFilepath(t, healthFilePath)
+
+	healthConfig := c.Data.Health
+	assert.NotNil(healthConfig)
+	assert.True(healthConfig.Enabled)
+	assert.Equal(":9000", healthConfig.Address)
+	assert.Equal(300000, healthConfig.ActivityWindowMs)
+	assert.True(healthConfig.EnableProfiling)
+	assert.Equal("localhost:6061", healthConfig.ProfilingAddress)
+}
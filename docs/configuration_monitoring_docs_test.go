@@ -30,6 +30,14 @@ func TestMonitoringDocumentation(t *testing.T) {
 
 	testStatsDConfig(t, statsDFilePath, true)
 
+	prometheusFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "monitoring", "prometheus-example.hcl")
+
+	testPrometheusConfig(t, prometheusFilePath, true)
+
+	otlpFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "monitoring", "otlp-example.hcl")
+
+	testOTLPConfig(t, otlpFilePath, true)
+
 	loglevelFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "monitoring", "log-level-example.hcl")
 
 	loglevelConf := getConfigFromFilepath(t, loglevelFilePath)
@@ -76,6 +84,48 @@ func testStatsDConfig(t *testing.T, configpath string, fullExample bool) {
 	}
 }
 
+func testPrometheusConfig(t *testing.T, configpath string, fullExample bool) {
+	assert := assert.New(t)
+	c := getConfigFromFilepath(t, configpath)
+
+	confStatsRec := c.Data.StatsReceiver
+
+	configObject := &statsreceiver.PrometheusStatsReceiverConfig{}
+
+	err := gohcl.DecodeBody(confStatsRec.Receiver.Body, config.CreateHclContext(), configObject)
+	if err != nil {
+		assert.Fail(confStatsRec.Receiver.Name, err.Error())
+	}
+
+	if fullExample {
+		checkComponentForZeros(t, configObject)
+
+		// Check the config values that are outside the statsreceiver part
+		assert.NotZero(confStatsRec.BufferSec)
+	}
+}
+
+func testOTLPConfig(t *testing.T, configpath string, fullExample bool) {
+	assert := assert.New(t)
+	c := getConfigFromFilepath(t, configpath)
+
+	confStatsRec := c.Data.StatsReceiver
+
+	configObject := &statsreceiver.OTLPStatsReceiverConfig{}
+
+	err := gohcl.DecodeBody(confStatsRec.Receiver.Body, config.CreateHclContext(), configObject)
+	if err != nil {
+		assert.Fail(confStatsRec.Receiver.Name, err.Error())
+	}
+
+	if fullExample {
+		checkComponentForZeros(t, configObject)
+
+		// Check the config values that are outside the statsreceiver part
+		assert.NotZero(confStatsRec.BufferSec)
+	}
+}
+
 func testSentryConfig(t *testing.T, configpath string, fullExample bool) {
 	assert := assert.New(t)
 	t.Setenv("SNOWBRIDGE_CONFIG_FILE", configpath)
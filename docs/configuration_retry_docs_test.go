@@ -30,4 +30,9 @@ func TestRetryConfigDocumentation(t *testing.T) {
 	assert.Equal(10, retryConfig.Transient.MaxAttempts)
 	assert.Equal(30000, retryConfig.Setup.Delay)
 	assert.Equal(3, retryConfig.Setup.MaxAttempts)
+	assert.Equal("exponential_jitter", retryConfig.Transient.Backoff)
+	assert.Equal(60000, retryConfig.Transient.MaxDelay)
+	assert.Equal(300000, retryConfig.Transient.Deadline)
+	assert.Equal("fixed", retryConfig.Throttle.Backoff)
+	assert.Nil(retryConfig.Validate())
 }
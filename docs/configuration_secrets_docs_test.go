@@ -0,0 +1,48 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/target/http"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretsConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+
+	// Write the secrets referenced in the config example
+	secretsDir := t.TempDir()
+	clientSecretFile := filepath.Join(secretsDir, "client_secret")
+	refreshTokenFile := filepath.Join(secretsDir, "refresh_token")
+	assert.Nil(os.WriteFile(clientSecretFile, []byte("client_secret_test\n"), 0600))
+	assert.Nil(os.WriteFile(refreshTokenFile, []byte("refresh_token_test"), 0600))
+	t.Setenv("CLIENT_SECRET_FILE", clientSecretFile)
+	t.Setenv("REFRESH_TOKEN_FILE", refreshTokenFile)
+
+	secretsFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "secrets-example.hcl")
+	c := getConfigFromFilepath(t, secretsFilePath)
+
+	use := c.Data.Targets[0].Target
+	assert.Equal("http", use.Name)
+
+	targetConfig := &http.HTTPTargetConfig{}
+	err := c.Decoder.Decode(&config.DecoderOptions{Input: use.Body}, targetConfig)
+	assert.Nil(err)
+	assert.Equal("client_secret_test", targetConfig.OAuth2ClientSecret)
+	assert.Equal("refresh_token_test", targetConfig.OAuth2RefreshToken)
+}
@@ -0,0 +1,32 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+	shutdownFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "shutdown-example.hcl")
+	c := getConfigFromFilepath(t, shutdownFilePath)
+
+	shutdownConfig := c.Data.Shutdown
+	assert.NotNil(shutdownConfig)
+	assert.Equal(60000, shutdownConfig.DrainTimeoutMs)
+	assert.Equal("nack", shutdownConfig.Policy)
+	assert.Nil(shutdownConfig.Validate(c.Data.Spill))
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/snowplow/snowbridge/v5/assets"
 	"github.com/snowplow/snowbridge/v5/config"
+	generatorsource "github.com/snowplow/snowbridge/v5/pkg/source/generator"
 	httpsource "github.com/snowplow/snowbridge/v5/pkg/source/http"
 	kafkasource "github.com/snowplow/snowbridge/v5/pkg/source/kafka"
 	kinesissource "github.com/snowplow/snowbridge/v5/pkg/source/kinesis"
@@ -34,7 +35,7 @@ func TestSourceDocumentation(t *testing.T) {
 	t.Setenv("SASL_PASSWORD", "test")
 	t.Setenv("HOSTNAME", "hostname")
 
-	sourcesToTest := []string{"http", "kafka", "kinesis", "pubsub", "sqs", "stdin"}
+	sourcesToTest := []string{"generator", "http", "kafka", "kinesis", "pubsub", "sqs", "stdin"}
 
 	for _, src := range sourcesToTest {
 
@@ -58,6 +59,8 @@ func testSourceConfig(t *testing.T, filepath string, fullExample bool) {
 
 	var configObject any
 	switch use.Name {
+	case "generator":
+		configObject = &generatorsource.Configuration{}
 	case "http":
 		configObject = &httpsource.Configuration{}
 	case "kafka":
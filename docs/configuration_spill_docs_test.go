@@ -0,0 +1,33 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpillConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+	spillFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "spill-example.hcl")
+	c := getConfigFromFilepath(t, spillFilePath)
+
+	spillConfig := c.Data.Spill
+	assert.NotNil(spillConfig)
+	assert.Equal("/var/lib/snowbridge/spill", spillConfig.Path)
+	assert.Equal(int64(536870912), spillConfig.MaxBytes)
+	assert.Equal("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", spillConfig.EncryptionKey)
+	assert.Equal(30000, spillConfig.DrainIntervalMs)
+}
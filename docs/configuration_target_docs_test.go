@@ -20,6 +20,7 @@ import (
 	"github.com/hashicorp/hcl/v2/gohcl"
 	"github.com/snowplow/snowbridge/v5/assets"
 	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/target/chaos"
 	"github.com/snowplow/snowbridge/v5/pkg/target/eventhub"
 	"github.com/snowplow/snowbridge/v5/pkg/target/http"
 	"github.com/snowplow/snowbridge/v5/pkg/target/kafka"
@@ -39,7 +40,7 @@ func TestTargetDocumentation(t *testing.T) {
 	t.Setenv("CLIENT_SECRET", "client_secret_test")
 	t.Setenv("REFRESH_TOKEN", "refresh_token_test")
 
-	targetsToTest := []string{"eventhub", "http", "kafka", "kinesis", "pubsub", "sqs", "stdout"}
+	targetsToTest := []string{"chaos", "eventhub", "http", "kafka", "kinesis", "pubsub", "sqs", "stdout"}
 
 	for _, tgt := range targetsToTest {
 
@@ -55,11 +56,27 @@ func TestTargetDocumentation(t *testing.T) {
 	}
 }
 
+func TestMultipleTargetsDocumentation(t *testing.T) {
+	assert := assert.New(t)
+
+	filePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "targets", "multiple-targets-example.hcl")
+	c := getConfigFromFilepath(t, filePath)
+
+	if assert.Equal(2, len(c.Data.Targets)) {
+		assert.Equal("", c.Data.Targets[0].Name)
+		assert.Equal("events_api", c.Data.Targets[1].Name)
+	}
+
+	for _, target := range c.Data.Targets {
+		testTargetComponent(t, target.Target.Name, target.Target.Body, false)
+	}
+}
+
 func testTargetConfig(t *testing.T, filepath string, fullExample bool) {
 
 	c := getConfigFromFilepath(t, filepath)
 
-	use := c.Data.Target.Target
+	use := c.Data.Targets[0].Target
 	testTargetComponent(t, use.Name, use.Body, fullExample)
 }
 
@@ -82,6 +99,8 @@ func testTargetComponent(t *testing.T, name string, body hcl.Body, fullExample b
 	assert := assert.New(t)
 	var configObject any
 	switch name {
+	case chaos.SupportedTargetChaos:
+		configObject = &chaos.ChaosTargetConfig{}
 	case eventhub.SupportedTargetEventHub:
 		configObject = &eventhub.EventHubConfig{}
 	case http.SupportedTargetHTTP:
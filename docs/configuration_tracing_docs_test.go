@@ -0,0 +1,35 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package docs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/assets"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracingConfigDocumentation(t *testing.T) {
+	assert := assert.New(t)
+	tracingFilePath := filepath.Join(assets.AssetsRootDir, "docs", "configuration", "tracing-example.hcl")
+	c := getConfigFromFilepath(t, tracingFilePath)
+
+	tracingConfig := c.Data.Tracing
+	assert.NotNil(tracingConfig)
+	assert.True(tracingConfig.Enabled)
+	assert.Equal("https://otel-collector.acme.com:4318/v1/traces", tracingConfig.Endpoint)
+	assert.Equal(map[string]string{"Authorization": "Bearer myToken"}, tracingConfig.Headers)
+	assert.Equal("event-forwarding", tracingConfig.ServiceName)
+	assert.Equal(0.1, tracingConfig.SampleRatio)
+	assert.Nil(tracingConfig.Validate())
+}
@@ -32,7 +32,7 @@ import (
 )
 
 func TestBuiltinTransformationDocumentation(t *testing.T) {
-	transformationsToTest := []string{"base64Decode", "base64Encode", "jq", "jqFilter"}
+	transformationsToTest := []string{"base64Decode", "base64Encode", "jq", "jqFilter", "jqRoute"}
 
 	for _, tfm := range transformationsToTest {
 
@@ -172,6 +172,8 @@ func testTransformationConfig(t *testing.T, filepath string, fullExample bool) {
 			configObject = &jq.JQMapperConfig{}
 		case "jqFilter":
 			configObject = &filter.JQFilterConfig{}
+		case "jqRoute":
+			configObject = &jq.JQRouteConfig{}
 		default:
 			assert.Fail(fmt.Sprint("Source not recognised: ", use.Name))
 		}
@@ -0,0 +1,156 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	// pprof imported for the side effect of registering its HTTP handlers
+	_ "net/http/pprof"
+)
+
+// Check reports why a component is unhealthy, or nil if it is healthy
+type Check func() error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Server serves /healthz for liveness and /readyz for readiness probes,
+// or the pprof endpoints under /debug/pprof/ when built with NewProfilingServer.
+// Checks must be added before the server is started.
+type Server struct {
+	server    *http.Server
+	listener  net.Listener
+	serves    string
+	liveness  []namedCheck
+	readiness []namedCheck
+	log       *log.Entry
+}
+
+// NewServer builds a server serving the probes on address once started
+func NewServer(address string) *Server {
+	s := &Server{serves: "health endpoints", log: log.WithFields(log.Fields{"name": "HealthServer"})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) { s.serveChecks(w, s.liveness) })
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) { s.serveChecks(w, s.readiness) })
+
+	s.server = &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	return s
+}
+
+// NewProfilingServer builds a server serving the pprof endpoints on address once started.
+// Profiles expose the memory of the app, so address should only be reachable locally.
+func NewProfilingServer(address string) *Server {
+	s := &Server{serves: "profiling endpoints", log: log.WithFields(log.Fields{"name": "ProfilingServer"})}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/pprof/", http.DefaultServeMux)
+
+	s.server = &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	return s
+}
+
+// AddLivenessCheck adds a check which fails /healthz, telling the orchestrator to restart the app
+func (s *Server) AddLivenessCheck(name string, check Check) {
+	s.liveness = append(s.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds a check which fails /readyz, telling the orchestrator the app can't do its work
+func (s *Server) AddReadinessCheck(name string, check Check) {
+	s.readiness = append(s.readiness, namedCheck{name: name, check: check})
+}
+
+// Start listens on the configured address and serves in the background
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.log.Infof("Serving %s on %s", s.serves, listener.Addr())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.log.WithError(err).Errorf("Server of %s stopped", s.serves)
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on, once started
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Stop shuts the server down
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.log.WithError(err).Warnf("Failed to shut down server of %s", s.serves)
+	}
+}
+
+// checksResponse is the body of a probe response
+type checksResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func (s *Server) serveChecks(w http.ResponseWriter, checks []namedCheck) {
+	response := checksResponse{Status: "ok", Checks: make(map[string]string, len(checks))}
+	code := http.StatusOK
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			response.Checks[c.name] = err.Error()
+			response.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		response.Checks[c.name] = "ok"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.log.WithError(err).Warn("Failed to write health response")
+	}
+}
+
+// IdleCheck fails once there has been no activity for longer than window.
+// The window starts counting when the check is created, so that a freshly started app is given time to receive data.
+func IdleCheck(window time.Duration, lastActivity func() time.Time) Check {
+	started := time.Now()
+	return func() error {
+		last := lastActivity()
+		if last.Before(started) {
+			last = started
+		}
+		if idle := time.Since(last); idle > window {
+			return fmt.Errorf("no messages delivered or acked for %s, longer than the %s window", idle.Truncate(time.Second), window)
+		}
+		return nil
+	}
+}
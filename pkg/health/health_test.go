@@ -0,0 +1,130 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getProbe(t *testing.T, url string) (int, checksResponse) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("probe request failed: %s", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var body checksResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode probe response: %s", err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestServer_Probes(t *testing.T) {
+	assert := assert.New(t)
+
+	var targetsErr error
+	server := NewServer("127.0.0.1:0")
+	server.AddLivenessCheck("observer", func() error { return nil })
+	server.AddReadinessCheck("targets", func() error { return targetsErr })
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+	baseURL := "http://" + server.Addr()
+
+	code, body := getProbe(t, baseURL+"/healthz")
+	assert.Equal(http.StatusOK, code)
+	assert.Equal(checksResponse{Status: "ok", Checks: map[string]string{"observer": "ok"}}, body)
+
+	code, body = getProbe(t, baseURL+"/readyz")
+	assert.Equal(http.StatusOK, code)
+	assert.Equal("ok", body.Status)
+
+	targetsErr = errors.New("targets are not open yet")
+	code, body = getProbe(t, baseURL+"/readyz")
+	assert.Equal(http.StatusServiceUnavailable, code)
+	assert.Equal(checksResponse{Status: "unavailable", Checks: map[string]string{"targets": "targets are not open yet"}}, body)
+
+	// Readiness doesn't affect liveness
+	code, _ = getProbe(t, baseURL+"/healthz")
+	assert.Equal(http.StatusOK, code)
+}
+
+func TestServer_Profiling(t *testing.T) {
+	assert := assert.New(t)
+
+	// Profiles are only served by the profiling server, and probes only by the health server
+	testCases := []struct {
+		Name      string
+		Server    *Server
+		Profiling bool
+	}{
+		{Name: "health server", Server: NewServer("127.0.0.1:0"), Profiling: false},
+		{Name: "profiling server", Server: NewProfilingServer("127.0.0.1:0"), Profiling: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Server.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer tt.Server.Stop()
+
+			for path, served := range map[string]bool{"/debug/pprof/": tt.Profiling, "/healthz": !tt.Profiling} {
+				resp, err := http.Get("http://" + tt.Server.Addr() + path)
+				if assert.NoError(err) {
+					_ = resp.Body.Close()
+					if served {
+						assert.Equal(http.StatusOK, resp.StatusCode, path)
+					} else {
+						assert.Equal(http.StatusNotFound, resp.StatusCode, path)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestServer_StartError(t *testing.T) {
+	server := NewServer("127.0.0.1:0")
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	// The address is taken
+	assert.Error(t, NewServer(server.Addr()).Start())
+}
+
+func TestIdleCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	var last time.Time
+	check := IdleCheck(50*time.Millisecond, func() time.Time { return last })
+
+	// The window counts from creation when nothing happened yet
+	assert.Nil(check())
+	time.Sleep(60 * time.Millisecond)
+	assert.ErrorContains(check(), "no messages delivered or acked for")
+
+	last = time.Now()
+	assert.Nil(check())
+}
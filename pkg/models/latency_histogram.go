@@ -0,0 +1,108 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package models
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// latencyRelativeAccuracy bounds the relative error of the quantiles reported by a LatencyHistogram
+const latencyRelativeAccuracy = 0.01
+
+var (
+	latencyGamma    = (1 + latencyRelativeAccuracy) / (1 - latencyRelativeAccuracy)
+	latencyLogGamma = math.Log(latencyGamma)
+)
+
+// LatencyHistogram is a mergeable sketch of a latency distribution.
+// Latencies are counted in logarithmic buckets (as in DDSketch), so quantiles are reported within 1% of their true value
+// whatever the range of latencies, and histograms from different windows or replicas can be merged without losing accuracy.
+// The zero value is an empty histogram ready to use.
+type LatencyHistogram struct {
+	buckets map[int]int64
+	zeros   int64
+	count   int64
+}
+
+// Record adds a latency to the histogram. Negative latencies, which clock skew can produce, are counted as zero.
+func (h *LatencyHistogram) Record(d time.Duration) {
+	h.count++
+	if d <= 0 {
+		h.zeros++
+		return
+	}
+	if h.buckets == nil {
+		h.buckets = make(map[int]int64)
+	}
+	h.buckets[int(math.Ceil(math.Log(float64(d))/latencyLogGamma))]++
+}
+
+// Merge adds all the latencies recorded by another histogram to this one
+func (h *LatencyHistogram) Merge(other *LatencyHistogram) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	if h.buckets == nil {
+		h.buckets = make(map[int]int64, len(other.buckets))
+	}
+	for idx, n := range other.buckets {
+		h.buckets[idx] += n
+	}
+	h.zeros += other.zeros
+	h.count += other.count
+}
+
+// Count returns the number of latencies recorded
+func (h *LatencyHistogram) Count() int64 {
+	return h.count
+}
+
+// Quantile returns the latency below which the fraction q of the recorded latencies fall, or 0 if nothing was recorded
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(min(max(q, 0), 1) * float64(h.count)))
+
+	var value time.Duration
+	h.ForEach(func(latency time.Duration, n int64) bool {
+		value = latency
+		rank -= n
+		return rank > 0
+	})
+	return value
+}
+
+// ForEach calls f with the representative latency and count of every bucket, from the lowest latency to the highest,
+// until f returns false
+func (h *LatencyHistogram) ForEach(f func(latency time.Duration, count int64) bool) {
+	if h.zeros > 0 && !f(0, h.zeros) {
+		return
+	}
+	indexes := make([]int, 0, len(h.buckets))
+	for idx := range h.buckets {
+		indexes = append(indexes, idx)
+	}
+	slices.Sort(indexes)
+	for _, idx := range indexes {
+		if !f(bucketLatency(idx), h.buckets[idx]) {
+			return
+		}
+	}
+}
+
+// bucketLatency returns the latency a bucket is reported as, which is within the relative accuracy of all the latencies it counts
+func bucketLatency(idx int) time.Duration {
+	return time.Duration(math.Round(2 * math.Pow(latencyGamma, float64(idx)) / (latencyGamma + 1)))
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package models
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertWithinAccuracy checks that a reported latency is within the histogram's relative accuracy of the expected one
+func assertWithinAccuracy(t *testing.T, expected time.Duration, actual time.Duration) {
+	t.Helper()
+	assert.LessOrEqual(t, math.Abs(float64(actual-expected)), latencyRelativeAccuracy*float64(expected), "expected %s, got %s", expected, actual)
+}
+
+func TestLatencyHistogram_Quantile(t *testing.T) {
+	assert := assert.New(t)
+
+	var h LatencyHistogram
+	assert.Equal(time.Duration(0), h.Quantile(0.5))
+
+	for i := 1; i <= 1000; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(int64(1000), h.Count())
+	assertWithinAccuracy(t, time.Millisecond, h.Quantile(0))
+	assertWithinAccuracy(t, 500*time.Millisecond, h.Quantile(0.5))
+	assertWithinAccuracy(t, 950*time.Millisecond, h.Quantile(0.95))
+	assertWithinAccuracy(t, 990*time.Millisecond, h.Quantile(0.99))
+	assertWithinAccuracy(t, time.Second, h.Quantile(1))
+}
+
+func TestLatencyHistogram_Outlier(t *testing.T) {
+	var h LatencyHistogram
+	for range 99 {
+		h.Record(5 * time.Millisecond)
+	}
+	h.Record(time.Hour)
+
+	// The outlier only shows at the top of the distribution
+	assertWithinAccuracy(t, 5*time.Millisecond, h.Quantile(0.5))
+	assertWithinAccuracy(t, 5*time.Millisecond, h.Quantile(0.99))
+	assertWithinAccuracy(t, time.Hour, h.Quantile(1))
+}
+
+func TestLatencyHistogram_ZeroAndNegative(t *testing.T) {
+	assert := assert.New(t)
+
+	var h LatencyHistogram
+	h.Record(0)
+	h.Record(-time.Second)
+	h.Record(time.Second)
+
+	assert.Equal(int64(3), h.Count())
+	assert.Equal(time.Duration(0), h.Quantile(0.5))
+	assertWithinAccuracy(t, time.Second, h.Quantile(1))
+}
+
+func TestLatencyHistogram_Merge(t *testing.T) {
+	assert := assert.New(t)
+
+	var merged, all LatencyHistogram
+	for window := range 3 {
+		var h LatencyHistogram
+		for i := 1; i <= 100; i++ {
+			latency := time.Duration(window*100+i) * time.Millisecond
+			h.Record(latency)
+			all.Record(latency)
+		}
+		merged.Merge(&h)
+	}
+	merged.Merge(nil)
+	merged.Merge(&LatencyHistogram{})
+
+	assert.Equal(all, merged)
+	assertWithinAccuracy(t, 150*time.Millisecond, merged.Quantile(0.5))
+}
+
+func TestLatencyHistogram_ForEach(t *testing.T) {
+	assert := assert.New(t)
+
+	var h LatencyHistogram
+	h.Record(time.Second)
+	h.Record(0)
+	h.Record(time.Millisecond)
+	h.Record(time.Millisecond)
+
+	var latencies []time.Duration
+	var counts []int64
+	h.ForEach(func(latency time.Duration, count int64) bool {
+		latencies = append(latencies, latency)
+		counts = append(counts, count)
+		return true
+	})
+
+	assert.Equal([]int64{1, 2, 1}, counts)
+	assert.Equal(time.Duration(0), latencies[0])
+	assertWithinAccuracy(t, time.Millisecond, latencies[1])
+	assertWithinAccuracy(t, time.Second, latencies[2])
+
+	// Iteration stops when asked to
+	calls := 0
+	h.ForEach(func(latency time.Duration, count int64) bool {
+		calls++
+		return false
+	})
+	assert.Equal(1, calls)
+}
@@ -23,6 +23,10 @@ type Message struct {
 	Data         []byte
 	HTTPHeaders  map[string]string
 
+	// Route is the name of the target the message should be written to.
+	// If empty, the message is written to every target.
+	Route string
+
 	// CollectorTstamp is the timestamp created by the Snowplow collector, extracted from the `collector_tstamp` atomic field. Used to measure E2E latency
 	CollectorTstamp time.Time
 
@@ -44,6 +48,12 @@ type Message struct {
 	// Time the request was done, to measure request latency for debugging purposes - we manually track this timestamp unlike other metrics, to get as accurate as possible a picture of just the request latency.
 	TimeRequestFinished time.Time
 
+	// TraceParent is the W3C traceparent the source received the message with, if any
+	TraceParent string
+
+	// TransformationSteps holds the timing of each transformation applied to the message, only recorded when tracing is enabled
+	TransformationSteps []TransformationStep
+
 	// AckFunc must be called on a successful message emission to ensure
 	// any cleanup process for the source is actioned
 	AckFunc func()
@@ -57,6 +67,13 @@ type Message struct {
 	err error
 }
 
+// TransformationStep records when a single transformation started and finished
+type TransformationStep struct {
+	Name     string
+	Started  time.Time
+	Finished time.Time
+}
+
 // SetError sets the value of the message error in case of invalidation
 func (m *Message) SetError(err error) {
 	m.err = err
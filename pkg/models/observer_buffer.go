@@ -22,6 +22,16 @@ type MetadataCodeDescription struct {
 	Description string
 }
 
+// TargetStats contains the write metrics of a single named target
+type TargetStats struct {
+	TargetResults int64
+	MsgSent       int64
+	MsgFailed     int64
+
+	// BreakerStateChanges counts the circuit breaker transitions of the target
+	BreakerStateChanges int64
+}
+
 // ObserverBuffer contains all the metrics we are processing
 type ObserverBuffer struct {
 	TargetResults int64
@@ -46,9 +56,23 @@ type ObserverBuffer struct {
 	MaxE2ELatency       time.Duration
 	MinE2ELatency       time.Duration
 
+	// Distribution of each latency, for percentiles
+	ProcLatencies      LatencyHistogram
+	MsgLatencies       LatencyHistogram
+	TransformLatencies LatencyHistogram
+	FilterLatencies    LatencyHistogram
+	RequestLatencies   LatencyHistogram
+	E2ELatencies       LatencyHistogram
+
 	InvalidErrors map[MetadataCodeDescription]int
 	FailedErrors  map[MetadataCodeDescription]int
 
+	// Write metrics broken down by target name
+	Targets map[string]*TargetStats
+
+	// Current circuit breaker state by target name (0 closed, 1 half-open, 2 open)
+	BreakerStates map[string]int64
+
 	// Kinsumer metrics
 	KinsumerRecordsInMemory      int64 // Current count of records in memory
 	KinsumerRecordsInMemoryBytes int64 // Current bytes of records in memory
@@ -78,6 +102,35 @@ func (b *ObserverBuffer) appendFailedError(msgs []*Message) {
 	}
 }
 
+func (b *ObserverBuffer) targetStats(name string) *TargetStats {
+	if b.Targets == nil {
+		b.Targets = make(map[string]*TargetStats)
+	}
+
+	stats, ok := b.Targets[name]
+	if !ok {
+		stats = &TargetStats{}
+		b.Targets[name] = stats
+	}
+	return stats
+}
+
+func (b *ObserverBuffer) appendTargetStats(res *TargetWriteResult) {
+	stats := b.targetStats(res.TargetName)
+	stats.TargetResults++
+	stats.MsgSent += int64(len(res.Sent))
+	stats.MsgFailed += int64(len(res.Failed))
+}
+
+// AppendBreakerState records a circuit breaker transition of the named target
+func (b *ObserverBuffer) AppendBreakerState(targetName string, state int64) {
+	if b.BreakerStates == nil {
+		b.BreakerStates = make(map[string]int64)
+	}
+	b.BreakerStates[targetName] = state
+	b.targetStats(targetName).BreakerStateChanges++
+}
+
 // AppendWrite adds a normal TargetWriteResult onto the buffer and stores the result
 func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 	if res == nil {
@@ -88,12 +141,17 @@ func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 	b.MsgSent += int64(len(res.Sent))
 	b.MsgFailed += int64(len(res.Failed))
 
+	if res.TargetName != "" {
+		b.appendTargetStats(res)
+	}
+
 	// Appending errors metadata
 	b.appendFailedError(res.Failed)
 
 	// Calculate processing, message, and E2E latencies only for successfully sent messages
 	for _, msg := range res.Sent {
 		procLatency := msg.TimeRequestFinished.Sub(msg.TimePulled)
+		b.ProcLatencies.Record(procLatency)
 		if b.MaxProcLatency < procLatency {
 			b.MaxProcLatency = procLatency
 		}
@@ -102,6 +160,7 @@ func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 		}
 
 		messageLatency := msg.TimeRequestFinished.Sub(msg.TimeCreated)
+		b.MsgLatencies.Record(messageLatency)
 		if b.MaxMsgLatency < messageLatency {
 			b.MaxMsgLatency = messageLatency
 		}
@@ -111,6 +170,7 @@ func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 
 		if !msg.CollectorTstamp.IsZero() {
 			e2eLatency := msg.TimeRequestFinished.Sub(msg.CollectorTstamp)
+			b.E2ELatencies.Record(e2eLatency)
 			if b.MaxE2ELatency < e2eLatency {
 				b.MaxE2ELatency = e2eLatency
 			}
@@ -125,6 +185,7 @@ func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 	for _, msg := range allMessages {
 		if !msg.TimeRequestStarted.IsZero() && !msg.TimeRequestFinished.IsZero() {
 			requestLatency := msg.TimeRequestFinished.Sub(msg.TimeRequestStarted)
+			b.RequestLatencies.Record(requestLatency)
 			if b.MaxRequestLatency < requestLatency {
 				b.MaxRequestLatency = requestLatency
 			}
@@ -135,6 +196,7 @@ func (b *ObserverBuffer) AppendWrite(res *TargetWriteResult) {
 
 		if !msg.TimeTransformationStarted.IsZero() && !msg.TimeTransformed.IsZero() {
 			transformLatency := msg.TimeTransformed.Sub(msg.TimeTransformationStarted)
+			b.TransformLatencies.Record(transformLatency)
 			if b.MaxTransformLatency < transformLatency {
 				b.MaxTransformLatency = transformLatency
 			}
@@ -171,6 +233,7 @@ func (b *ObserverBuffer) AppendFiltered(res *TargetWriteResult) {
 	for _, msg := range res.Sent {
 		if !msg.TimeRequestFinished.IsZero() && !msg.TimePulled.IsZero() {
 			filterLatency := msg.TimeRequestFinished.Sub(msg.TimePulled)
+			b.FilterLatencies.Record(filterLatency)
 			if b.MaxFilterLatency < filterLatency {
 				b.MaxFilterLatency = filterLatency
 			}
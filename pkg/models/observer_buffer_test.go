@@ -117,6 +117,12 @@ func TestObserverBuffer(t *testing.T) {
 	assert.Equal(time.Duration(80)*time.Minute, b.MaxE2ELatency)
 	assert.Equal(time.Duration(60)*time.Minute, b.MinE2ELatency)
 
+	assert.Equal(int64(4), b.ProcLatencies.Count())
+	assert.Equal(int64(4), b.MsgLatencies.Count())
+	assert.Equal(int64(1), b.FilterLatencies.Count())
+	assert.InDelta(float64(7*time.Minute), float64(b.ProcLatencies.Quantile(1)), 0.01*float64(7*time.Minute))
+	assert.InDelta(float64(4*time.Minute), float64(b.ProcLatencies.Quantile(0)), 0.01*float64(4*time.Minute))
+
 	assert.Equal("TargetResults:2,MsgFiltered:1,MsgSent:4,MsgFailed:2,InvalidTargetResults:2,InvalidMsgSent:4,InvalidMsgFailed:2,MinProcLatency:240000,MaxProcLatency:420000,MinMsgLatency:3000000,MaxMsgLatency:4200000,MinFilterLatency:600000,MaxFilterLatency:600000,MinTransformLatency:60000,MaxTransformLatency:180000,MinReqLatency:60000,MaxReqLatency:300000,MinE2ELatency:3600000,MaxE2ELatency:4800000", b.String())
 }
 
@@ -241,3 +247,46 @@ func TestObserverBuffer_BasicNoTransform(t *testing.T) {
 
 	assert.Equal("TargetResults:1,MsgFiltered:0,MsgSent:1,MsgFailed:0,InvalidTargetResults:0,InvalidMsgSent:0,InvalidMsgFailed:0,MinProcLatency:240000,MaxProcLatency:240000,MinMsgLatency:3000000,MaxMsgLatency:3000000,MinFilterLatency:0,MaxFilterLatency:0,MinTransformLatency:0,MaxTransformLatency:0,MinReqLatency:60000,MaxReqLatency:60000,MinE2ELatency:0,MaxE2ELatency:0", b.String())
 }
+
+func TestObserverBuffer_PerTarget(t *testing.T) {
+	assert := assert.New(t)
+
+	b := ObserverBuffer{
+		InvalidErrors: make(map[MetadataCodeDescription]int),
+		FailedErrors:  make(map[MetadataCodeDescription]int),
+	}
+
+	kafkaResult := NewTargetWriteResult([]*Message{{Data: []byte("Foo")}, {Data: []byte("Bar")}}, nil, nil)
+	kafkaResult.TargetName = "kafka"
+	httpResult := NewTargetWriteResult([]*Message{{Data: []byte("Foo")}}, []*Message{{Data: []byte("Bar")}}, nil)
+	httpResult.TargetName = "http"
+
+	b.AppendWrite(kafkaResult)
+	b.AppendWrite(httpResult)
+	b.AppendWrite(NewTargetWriteResult([]*Message{{Data: []byte("Baz")}}, nil, nil))
+
+	assert.Equal(int64(3), b.TargetResults)
+	assert.Equal(int64(4), b.MsgSent)
+	assert.Equal(int64(1), b.MsgFailed)
+
+	// Results without a target name only count towards the totals
+	assert.Equal(map[string]*TargetStats{
+		"kafka": {TargetResults: 1, MsgSent: 2, MsgFailed: 0},
+		"http":  {TargetResults: 1, MsgSent: 1, MsgFailed: 1},
+	}, b.Targets)
+}
+
+func TestObserverBuffer_BreakerState(t *testing.T) {
+	assert := assert.New(t)
+
+	b := ObserverBuffer{}
+	b.AppendBreakerState("kafka", 2)
+	b.AppendBreakerState("kafka", 1)
+	b.AppendBreakerState("http", 2)
+
+	assert.Equal(map[string]int64{"kafka": 1, "http": 2}, b.BreakerStates)
+	assert.Equal(map[string]*TargetStats{
+		"kafka": {BreakerStateChanges: 2},
+		"http":  {BreakerStateChanges: 1},
+	}, b.Targets)
+}
@@ -11,6 +11,8 @@
 
 package models
 
+import "time"
+
 // SetupWriteError is a wrapper for target write error and used by targets as a signal
 // for a caller that this kind of error should be retried using 'setup-like' retry strategy.
 type SetupWriteError struct {
@@ -25,6 +27,8 @@ func (err SetupWriteError) Error() string {
 // for a caller that this kind of error should be retried using 'throttle-like' retry strategy.
 type ThrottleWriteError struct {
 	Err error
+	// RetryAfter is the delay the target asked for before the next attempt, zero when it gave no hint
+	RetryAfter time.Duration
 }
 
 func (err ThrottleWriteError) Error() string {
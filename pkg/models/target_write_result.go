@@ -25,6 +25,10 @@ type TargetWriteResult struct {
 	// due to various parseability reasons.  These messages cannot be retried
 	// and need to be specially handled.
 	Invalid []*Message
+
+	// TargetName is the name of the good target which produced this result.
+	// It is set by the router and used to report per-target metrics.
+	TargetName string
 }
 
 // NewTargetWriteResult builds a result structure to return from a target write attempt.
@@ -17,6 +17,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -53,6 +54,8 @@ type WebhookMonitoring struct {
 
 	exitSignal chan struct{}
 
+	// mu guards the health state, which is read by health probes
+	mu           sync.Mutex
 	isHealthy    bool
 	currentError error
 }
@@ -85,27 +88,23 @@ func (m *WebhookMonitoring) Start() {
 		for {
 			select {
 			case <-ticker.C:
-				if m.isHealthy {
+				if isHealthy, currentError := m.state(); isHealthy {
 					m.sendHeartbeat()
-				} else if m.currentError != nil {
-					m.sendAlert(m.currentError)
+				} else if currentError != nil {
+					m.sendAlert(currentError)
 				}
 
 			case err := <-m.alertChan:
 				if err != nil {
 					// First alert gets sent immediately
-					if m.isHealthy {
+					if isHealthy, _ := m.state(); isHealthy {
 						m.sendAlert(err)
-						m.isHealthy = false
-						m.currentError = err
-					} else {
-						// In case error changes, we need to make sure it would be sent
-						m.currentError = err
 					}
+					// In case error changes, we need to make sure it would be sent
+					m.setState(false, err)
 				} else {
 					m.log.Debug("setup error resolved, resuming heartbeats")
-					m.isHealthy = true
-					m.currentError = nil
+					m.setState(true, nil)
 				}
 
 			case <-m.exitSignal:
@@ -120,6 +119,28 @@ func (m *WebhookMonitoring) Stop() {
 	m.exitSignal <- struct{}{}
 }
 
+// Status returns the setup error currently alerted on, or nil while the app is healthy
+func (m *WebhookMonitoring) Status() error {
+	isHealthy, currentError := m.state()
+	if isHealthy {
+		return nil
+	}
+	return fmt.Errorf("setup error: %w", currentError)
+}
+
+func (m *WebhookMonitoring) state() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.isHealthy, m.currentError
+}
+
+func (m *WebhookMonitoring) setState(isHealthy bool, currentError error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isHealthy = isHealthy
+	m.currentError = currentError
+}
+
 func (m *WebhookMonitoring) sendAlert(err error) {
 	m.log.Info("Sending an alert")
 
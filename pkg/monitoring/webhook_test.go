@@ -323,3 +323,24 @@ func TestWebhookMonitoringRetries(t *testing.T) {
 		assert.Equal(t, 6, calls)
 	})
 }
+
+func TestWebhookMonitoringStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	sr := TestWebhookSender{onDo: func(*http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}}
+	alertChan := make(chan error)
+	webhook := NewWebhookMonitoring("snowbridge", "3.4.0", &sr, "https://test.webhook.com", nil, time.Hour, alertChan)
+	webhook.Start()
+	defer webhook.Stop()
+
+	assert.Nil(webhook.Status())
+
+	alertChan <- errors.New("failed to connect to target API")
+	assert.Eventually(func() bool { return webhook.Status() != nil }, time.Second, 10*time.Millisecond)
+	assert.EqualError(webhook.Status(), "setup error: failed to connect to target API")
+
+	alertChan <- nil
+	assert.Eventually(func() bool { return webhook.Status() == nil }, time.Second, 10*time.Millisecond)
+}
@@ -12,7 +12,10 @@
 package observer
 
 import (
+	"errors"
+	"maps"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -32,8 +35,9 @@ type Observer struct {
 	filteredChan           chan *models.TargetWriteResult
 	targetWriteChan        chan *models.TargetWriteResult
 	targetWriteInvalidChan chan *models.TargetWriteResult
+	breakerStateChan       chan *breakerState
 	reportInterval         time.Duration
-	isRunning              bool
+	isRunning              atomic.Bool
 
 	// Kinsumer metrics channels
 	kinsumerRecordsChan      chan int64
@@ -44,6 +48,12 @@ type Observer struct {
 	log *log.Entry
 }
 
+// breakerState is a circuit breaker transition of a named target
+type breakerState struct {
+	targetName string
+	state      int64
+}
+
 // bufferSnapshot is the unit of ownership transferred from the ingestion loop to the flush loop.
 type bufferSnapshot struct {
 	buffer *models.ObserverBuffer
@@ -61,21 +71,20 @@ func New(statsClient statsreceiveriface.StatsReceiver, reportInterval time.Durat
 		filteredChan:             make(chan *models.TargetWriteResult, 1000),
 		targetWriteChan:          make(chan *models.TargetWriteResult, 1000),
 		targetWriteInvalidChan:   make(chan *models.TargetWriteResult, 1000),
+		breakerStateChan:         make(chan *breakerState, 1000),
 		kinsumerRecordsChan:      make(chan int64, 1000),
 		kinsumerRecordsBytesChan: make(chan int64, 1000),
 		reportInterval:           reportInterval,
 		log:                      log.WithFields(log.Fields{"name": "Observer"}),
-		isRunning:                false,
 	}
 }
 
 // Start launches the ingestion and flush goroutines.
 func (o *Observer) Start() {
-	if o.isRunning {
+	if !o.isRunning.CompareAndSwap(false, true) {
 		o.log.Warn("Observer is already running")
 		return
 	}
-	o.isRunning = true
 	// Cap 2: one slot for a regular tick snapshot, one for a possible exit-signal
 	// follow-up — so the final window still lands even if metadataLoop is mid-Send.
 	o.metadataChan = make(chan *bufferSnapshot, 2)
@@ -107,6 +116,8 @@ func (o *Observer) ingestionLoop() {
 			current.AppendWrite(res)
 		case res := <-o.targetWriteInvalidChan:
 			current.AppendWriteInvalid(res)
+		case b := <-o.breakerStateChan:
+			current.AppendBreakerState(b.targetName, b.state)
 		case count := <-o.kinsumerRecordsChan:
 			current.KinsumerRecordsInMemory = count
 		case bytes := <-o.kinsumerRecordsBytesChan:
@@ -116,6 +127,7 @@ func (o *Observer) ingestionLoop() {
 			snapshot := &bufferSnapshot{buffer: current, start: periodStart, end: end}
 			// Gauges represent current state, not period counts — carry them over.
 			current = newBuffer(current.KinsumerRecordsInMemory, current.KinsumerRecordsInMemoryBytes)
+			current.BreakerStates = maps.Clone(snapshot.buffer.BreakerStates)
 			periodStart = end
 			o.publishStats(snapshot.buffer)
 			o.forwardToMetadata(snapshot)
@@ -163,11 +175,19 @@ func newBuffer(recordsInMemory, recordsInMemoryBytes int64) *models.ObserverBuff
 // Stop issues a signal to halt observer processing
 func (o *Observer) Stop() {
 	o.log.Info("Observer Stop() called")
-	if o.isRunning {
+	if o.isRunning.Load() {
 		o.exitSignal <- struct{}{}
 		o.wg.Wait()
-		o.isRunning = false
+		o.isRunning.Store(false)
+	}
+}
+
+// Running returns an error if the observer is not running
+func (o *Observer) Running() error {
+	if !o.isRunning.Load() {
+		return errors.New("observer is not running")
 	}
+	return nil
 }
 
 // --- Functions called to push information to observer
@@ -188,6 +208,12 @@ func (o *Observer) TargetWriteFiltered(r *models.TargetWriteResult) {
 	o.filteredChan <- r
 }
 
+// TargetBreakerState pushes a circuit breaker transition of a target onto a channel for processing
+// by the observer
+func (o *Observer) TargetBreakerState(targetName string, state int64) {
+	o.breakerStateChan <- &breakerState{targetName: targetName, state: state}
+}
+
 // UpdateKinsumerRecordsInMemory updates the current count of records in memory
 func (o *Observer) UpdateKinsumerRecordsInMemory(count int64) {
 	select {
@@ -221,5 +221,6 @@ func TestObserverContinuesWhenMetadataHangs(t *testing.T) {
 	// Unblock metadata so Stop() can drain cleanly.
 	close(metaBlock)
 	observer.Stop()
-	a.False(observer.isRunning, "observer should no longer be running after Stop")
+	a.False(observer.isRunning.Load(), "observer should no longer be running after Stop")
+	a.EqualError(observer.Running(), "observer is not running")
 }
@@ -0,0 +1,297 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/snowplow/snowbridge/v5/cmd"
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/failure"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/observer"
+	"github.com/snowplow/snowbridge/v5/pkg/router"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+	"github.com/snowplow/snowbridge/v5/pkg/statsreceiver/statsreceiveriface"
+	"github.com/snowplow/snowbridge/v5/pkg/target/silent"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetconfig"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+	"github.com/snowplow/snowbridge/v5/pkg/transform"
+)
+
+// Config describes a pipeline built in process.
+// Source and Targets are required, every other field falls back to the default of the config file when left empty.
+type Config struct {
+	Source sourceiface.Source
+
+	// Transformations are applied in order to every message, messages are forwarded untouched when empty
+	Transformations []transform.TransformationFunction
+	WorkerPool      int
+
+	// Targets need unique names, which must not clash with those of the filter and failure targets either
+	Targets []Target
+	// FilterTarget and FailureTarget default to targets which ack and drop the messages, named 'filter' and 'failure'
+	FilterTarget  *Target
+	FailureTarget *Target
+	// FailureFormat is either 'snowplow' or 'event_forwarding'
+	FailureFormat string
+
+	// Settings left to their zero value take their default, down to whole retry classes.
+	// A zero value can't be set explicitly: e.g. MaxAttempts 0 gets the default number of attempts.
+	// The defaults which are zero, such as disabled circuit breakers and spilling, can be relied on.
+	Retry          *config.RetryConfig
+	Shutdown       *config.ShutdownConfig
+	CircuitBreaker *config.CircuitBreakerConfig
+	// Spill is disabled unless a path is set, which the 'spill' shutdown policy requires
+	Spill *config.SpillConfig
+
+	// OnMetrics is called with the metrics gathered over every MetricsInterval, and once more when the pipeline stops
+	OnMetrics       func(buffer *models.ObserverBuffer)
+	MetricsInterval time.Duration
+}
+
+// Target is an initialised target driver, along with the name it is reported under
+type Target struct {
+	Name   string
+	Driver targetiface.TargetDriver
+}
+
+// Pipeline runs a source, a transformation chain and targets in process.
+// Unlike the app, it reads no config file or environment variable, and installs no signal handler.
+type Pipeline struct {
+	pipeline *router.Pipeline
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	stopped bool
+	done    chan struct{}
+}
+
+// New builds a pipeline from cfg, without starting anything
+func New(cfg *Config) (p *Pipeline, err error) {
+	defaults := config.DefaultConfigurationData()
+
+	if err := checkTargetNames(cfg); err != nil {
+		return nil, err
+	}
+
+	// Targets run a flush ticker as soon as they are built, which must be stopped if the pipeline can't be
+	var built []*targetiface.Target
+	defer func() {
+		if err != nil {
+			for _, target := range built {
+				target.Ticker.Stop()
+			}
+		}
+	}()
+	build := func(t *Target, name string) (*targetiface.Target, error) {
+		target, err := newTargetOrSilent(t, name)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, target)
+		return target, nil
+	}
+
+	targets := make([]*targetiface.Target, 0, len(cfg.Targets))
+	for _, t := range cfg.Targets {
+		target, err := build(&t, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	filterTarget, err := build(cfg.FilterTarget, filterTargetName)
+	if err != nil {
+		return nil, err
+	}
+	failureTarget, err := build(cfg.FailureTarget, failureTargetName)
+	if err != nil {
+		return nil, err
+	}
+	failureParser, err := newFailureParser(cfg.FailureFormat, failureTarget.GetBatchingConfig().MaxMessageBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// A nil receiver must stay an untyped nil for the observer to skip it
+	var statsReceiver statsreceiveriface.StatsReceiver
+	if cfg.OnMetrics != nil {
+		statsReceiver = metricsCallback(cfg.OnMetrics)
+	}
+	interval := cfg.MetricsInterval
+	if interval <= 0 {
+		interval = time.Duration(defaults.StatsReceiver.BufferSec) * time.Second
+	}
+
+	pipeline, err := router.NewPipeline(&router.PipelineComponents{
+		Source:         cfg.Source,
+		Transformation: transform.NewTransformation(cfg.Transformations...),
+		WorkerPool:     cfg.WorkerPool,
+		Targets:        targets,
+		FilterTarget:   filterTarget,
+		FailureTarget:  failureTarget,
+		FailureParser:  failureParser,
+		Observer:       observer.New(statsReceiver, interval, nil),
+		Retry:          withDefaults(cfg.Retry, defaults.Retry),
+		Spill:          withDefaults(cfg.Spill, defaults.Spill),
+		Shutdown:       withDefaults(cfg.Shutdown, defaults.Shutdown),
+		CircuitBreaker: withDefaults(cfg.CircuitBreaker, defaults.CircuitBreaker),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{
+		pipeline: pipeline,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Run starts the pipeline and blocks until it has shut down, after ctx is cancelled, Stop is called,
+// a component hits a fatal error or the source has no more messages.
+// A pipeline can only be run once.
+func (p *Pipeline) Run(ctx context.Context) error {
+	p.mu.Lock()
+	if p.cancel != nil {
+		p.mu.Unlock()
+		return errors.New("pipeline has already been run")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.cancel = cancel
+	if p.stopped {
+		cancel()
+	}
+	p.mu.Unlock()
+
+	defer close(p.done)
+	return p.pipeline.Run(ctx)
+}
+
+// Stop shuts the pipeline down gracefully and waits for Run to return.
+// Stopping a pipeline which has not been run yet makes Run return straight away.
+func (p *Pipeline) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	cancel := p.cancel
+	p.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-p.done
+	}
+}
+
+// Names of the filter and failure targets when they are left to their default
+const (
+	filterTargetName  = "filter"
+	failureTargetName = "failure"
+)
+
+// checkTargetNames makes sure every target has its own name, as names identify targets in logs and metrics
+func checkTargetNames(cfg *Config) error {
+	names := make([]string, 0, len(cfg.Targets)+2)
+	for _, t := range cfg.Targets {
+		names = append(names, t.Name)
+	}
+	for _, t := range []struct {
+		target      *Target
+		defaultName string
+	}{{cfg.FilterTarget, filterTargetName}, {cfg.FailureTarget, failureTargetName}} {
+		if t.target != nil {
+			names = append(names, t.target.Name)
+		} else {
+			names = append(names, t.defaultName)
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("duplicate target name %q: every target, including the filter and failure targets, needs a unique name", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func newTarget(t *Target) (*targetiface.Target, error) {
+	if t.Driver == nil {
+		return nil, fmt.Errorf("target '%s' has no driver", t.Name)
+	}
+	return targetconfig.NewTarget(t.Name, t.Driver)
+}
+
+func newTargetOrSilent(t *Target, name string) (*targetiface.Target, error) {
+	if t != nil {
+		return newTarget(t)
+	}
+
+	driver := &silent.SilentTargetDriver{}
+	if err := driver.InitFromConfig(driver.GetDefaultConfiguration()); err != nil {
+		return nil, err
+	}
+	return targetconfig.NewTarget(name, driver)
+}
+
+func newFailureParser(format string, maxMessageSize int) (failure.FailureParser, error) {
+	switch format {
+	case "", failure.SnowplowFailureTarget:
+		return failure.NewSnowplowFailure(maxMessageSize, cmd.AppName, cmd.AppVersion)
+	case failure.EventForwardingFailureTarget:
+		return failure.NewEventForwardingFailure(maxMessageSize, cmd.AppName, cmd.AppVersion)
+	default:
+		return nil, fmt.Errorf("invalid failure format found; expected one of 'snowplow', 'event_forwarding' and got '%s'", format)
+	}
+}
+
+// metricsCallback sends the buffers of the observer to a function
+type metricsCallback func(buffer *models.ObserverBuffer)
+
+// Send calls the callback with the buffer
+func (f metricsCallback) Send(buffer *models.ObserverBuffer) {
+	f(buffer)
+}
+
+// withDefaults returns a copy of value in which every field left empty, down to the blocks it holds, is taken from fallback
+func withDefaults[T any](value, fallback *T) *T {
+	if value == nil {
+		return fallback
+	}
+	merged := *value
+	fillEmpty(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(fallback).Elem())
+	return &merged
+}
+
+// fillEmpty sets the empty fields of the struct value to those of fallback.
+// Blocks which are set are copied before being filled, so that the caller's config is left untouched.
+func fillEmpty(value, fallback reflect.Value) {
+	for i := range value.NumField() {
+		field, fallbackField := value.Field(i), fallback.Field(i)
+		switch {
+		case field.IsZero():
+			field.Set(fallbackField)
+		case field.Kind() == reflect.Pointer && field.Elem().Kind() == reflect.Struct && !fallbackField.IsNil():
+			block := reflect.New(field.Elem().Type())
+			block.Elem().Set(field.Elem())
+			fillEmpty(block.Elem(), fallbackField.Elem())
+			field.Set(block)
+		}
+	}
+}
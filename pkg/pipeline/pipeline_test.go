@@ -0,0 +1,253 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/source/inmemory"
+	"github.com/snowplow/snowbridge/v5/pkg/target/capture"
+	"github.com/snowplow/snowbridge/v5/pkg/transform"
+)
+
+func upperCase(message *models.Message, intermediateState any) (*models.Message, *models.Message, *models.Message, any) {
+	message.Data = []byte(strings.ToUpper(string(message.Data)))
+	return message, nil, nil, intermediateState
+}
+
+func dropFiltered(message *models.Message, intermediateState any) (*models.Message, *models.Message, *models.Message, any) {
+	if string(message.Data) == "FILTERED" {
+		return nil, message, nil, intermediateState
+	}
+	return message, nil, nil, intermediateState
+}
+
+func TestPipeline_RunsToCompletion(t *testing.T) {
+	assert := assert.New(t)
+
+	input := make(chan []string, 1)
+	source, err := inmemory.Build(input)
+	assert.Nil(err)
+	target := capture.New()
+	filtered := capture.New()
+
+	var sent int64
+	p, err := New(&Config{
+		Source:          source,
+		Transformations: []transform.TransformationFunction{upperCase, dropFiltered},
+		Targets:         []Target{{Name: "capture", Driver: target}},
+		FilterTarget:    &Target{Name: "filtered", Driver: filtered},
+		OnMetrics: func(buffer *models.ObserverBuffer) {
+			atomic.AddInt64(&sent, buffer.MsgSent)
+		},
+	})
+	assert.Nil(err)
+
+	// Closing the input ends the source, which shuts the pipeline down once every message is delivered
+	input <- []string{"hello", "filtered", "world"}
+	close(input)
+
+	assert.Nil(p.Run(context.Background()))
+	assert.Equal([]string{"HELLO", "WORLD"}, target.Data())
+	assert.Equal([]string{"FILTERED"}, filtered.Data())
+	assert.Equal(int64(2), atomic.LoadInt64(&sent))
+
+	assert.EqualError(p.Run(context.Background()), "pipeline has already been run")
+}
+
+func TestPipeline_Stop(t *testing.T) {
+	assert := assert.New(t)
+
+	input := make(chan []string)
+	source, err := inmemory.Build(input)
+	assert.Nil(err)
+	target := capture.New()
+
+	p, err := New(&Config{
+		Source:  source,
+		Targets: []Target{{Name: "capture", Driver: target}},
+	})
+	assert.Nil(err)
+
+	done := make(chan error)
+	go func() {
+		done <- p.Run(context.Background())
+	}()
+
+	input <- []string{"hello"}
+	assert.Eventually(func() bool { return len(target.Data()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	p.Stop()
+	select {
+	case err := <-done:
+		assert.Nil(err)
+	default:
+		t.Fatal("Stop returned before Run")
+	}
+	p.Stop()
+}
+
+func TestPipeline_StopBeforeRun(t *testing.T) {
+	assert := assert.New(t)
+
+	source, err := inmemory.Build(make(chan []string))
+	assert.Nil(err)
+
+	p, err := New(&Config{
+		Source:  source,
+		Targets: []Target{{Name: "capture", Driver: capture.New()}},
+	})
+	assert.Nil(err)
+
+	p.Stop()
+	assert.Nil(p.Run(context.Background()))
+}
+
+func TestNew_Errors(t *testing.T) {
+	source, err := inmemory.Build(make(chan []string))
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		Name   string
+		Config *Config
+		Error  string
+	}{
+		{
+			Name:   "no targets",
+			Config: &Config{Source: source},
+			Error:  "at least one target must be configured",
+		},
+		{
+			Name:   "no source",
+			Config: &Config{Targets: []Target{{Name: "capture", Driver: capture.New()}}},
+			Error:  "a pipeline needs a source",
+		},
+		{
+			Name:   "target without driver",
+			Config: &Config{Source: source, Targets: []Target{{Name: "capture"}}},
+			Error:  "target 'capture' has no driver",
+		},
+		{
+			Name:   "duplicate target names",
+			Config: &Config{Source: source, Targets: []Target{{Name: "capture", Driver: capture.New()}, {Name: "capture", Driver: capture.New()}}},
+			Error:  `duplicate target name "capture": every target, including the filter and failure targets, needs a unique name`,
+		},
+		{
+			Name:   "target named as the default filter target",
+			Config: &Config{Source: source, Targets: []Target{{Name: "filter", Driver: capture.New()}}},
+			Error:  `duplicate target name "filter": every target, including the filter and failure targets, needs a unique name`,
+		},
+		{
+			Name:   "target named as the failure target",
+			Config: &Config{Source: source, Targets: []Target{{Name: "capture", Driver: capture.New()}}, FailureTarget: &Target{Name: "capture", Driver: capture.New()}},
+			Error:  `duplicate target name "capture": every target, including the filter and failure targets, needs a unique name`,
+		},
+		{
+			Name:   "unknown failure format",
+			Config: &Config{Source: source, Targets: []Target{{Name: "capture", Driver: capture.New()}}, FailureFormat: "csv"},
+			Error:  "invalid failure format found; expected one of 'snowplow', 'event_forwarding' and got 'csv'",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			p, err := New(tt.Config)
+			assert.Nil(t, p)
+			assert.EqualError(t, err, tt.Error)
+		})
+	}
+}
+
+func TestNew_PartialConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	source, err := inmemory.Build(make(chan []string))
+	assert.Nil(err)
+
+	// Retry classes and settings left out take their default
+	retry := &config.RetryConfig{Transient: &config.TransientRetryConfig{MaxAttempts: 2}}
+	p, err := New(&Config{
+		Source:         source,
+		Targets:        []Target{{Name: "capture", Driver: capture.New()}},
+		Retry:          retry,
+		Shutdown:       &config.ShutdownConfig{Policy: config.ShutdownPolicyNack},
+		CircuitBreaker: &config.CircuitBreakerConfig{Enabled: true},
+	})
+	assert.Nil(err)
+	assert.NotNil(p)
+	assert.Nil(retry.Setup)
+}
+
+func TestNew_ZeroMeansDefault(t *testing.T) {
+	assert := assert.New(t)
+
+	defaults := config.DefaultConfigurationData()
+
+	// Zero values can't be told apart from settings left out, and take the default
+	retry := withDefaults(&config.RetryConfig{Transient: &config.TransientRetryConfig{MaxAttempts: 0}}, defaults.Retry)
+	assert.Equal(defaults.Retry.Transient.MaxAttempts, retry.Transient.MaxAttempts)
+	shutdown := withDefaults(&config.ShutdownConfig{DrainTimeoutMs: 0}, defaults.Shutdown)
+	assert.Equal(defaults.Shutdown.DrainTimeoutMs, shutdown.DrainTimeoutMs)
+
+	// Defaults which are zero stay so: breakers and spilling are off unless enabled
+	breaker := withDefaults(&config.CircuitBreakerConfig{Enabled: false, FailureRate: 0.9}, defaults.CircuitBreaker)
+	assert.False(breaker.Enabled)
+	assert.Empty(withDefaults(&config.SpillConfig{}, defaults.Spill).Path)
+}
+
+func TestNew_Spill(t *testing.T) {
+	assert := assert.New(t)
+
+	source, err := inmemory.Build(make(chan []string))
+	assert.Nil(err)
+
+	shutdown := &config.ShutdownConfig{Policy: config.ShutdownPolicySpill}
+	_, err = New(&Config{
+		Source:   source,
+		Targets:  []Target{{Name: "capture", Driver: capture.New()}},
+		Shutdown: shutdown,
+	})
+	assert.EqualError(err, "shutdown policy 'spill' requires a spill path to be configured")
+
+	p, err := New(&Config{
+		Source:   source,
+		Targets:  []Target{{Name: "capture", Driver: capture.New()}},
+		Shutdown: shutdown,
+		Spill:    &config.SpillConfig{Path: t.TempDir()},
+	})
+	assert.Nil(err)
+	assert.NotNil(p)
+}
+
+func TestWithDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	defaults := config.DefaultConfigurationData().Retry
+	retry := &config.RetryConfig{Transient: &config.TransientRetryConfig{MaxAttempts: 2, InvalidAfterMax: true}}
+
+	merged := withDefaults(retry, defaults)
+	assert.Equal(&config.TransientRetryConfig{Delay: 1000, MaxAttempts: 2, InvalidAfterMax: true, Backoff: config.BackoffExponential}, merged.Transient)
+	assert.Equal(defaults.Setup, merged.Setup)
+	assert.Equal(defaults.Throttle, merged.Throttle)
+
+	// The config given is left untouched
+	assert.Equal(&config.RetryConfig{Transient: &config.TransientRetryConfig{MaxAttempts: 2, InvalidAfterMax: true}}, retry)
+	assert.Same(defaults, withDefaults(nil, defaults))
+}
@@ -0,0 +1,230 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/failure"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/observer"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+	"github.com/snowplow/snowbridge/v5/pkg/transform"
+	transformer "github.com/snowplow/snowbridge/v5/pkg/transform/transformer"
+)
+
+// PipelineComponents are what a pipeline is built from.
+// The app builds them from the config file, the pipeline package from values given in process.
+type PipelineComponents struct {
+	Source         sourceiface.Source
+	Transformation transform.TransformationApplyFunction
+	WorkerPool     int
+
+	Targets       []*targetiface.Target
+	FilterTarget  *targetiface.Target
+	FailureTarget *targetiface.Target
+	FailureParser failure.FailureParser
+
+	// Observer gathers the metrics of the pipeline, Metrics defaults to it
+	Observer *observer.Observer
+	Metrics  RouterMetrics
+
+	// AlertChannel receives setup errors for the webhook monitoring, it is optional
+	AlertChannel chan error
+
+	Retry          *config.RetryConfig
+	Spill          *config.SpillConfig
+	Shutdown       *config.ShutdownConfig
+	CircuitBreaker *config.CircuitBreakerConfig
+}
+
+// Pipeline moves messages from a source, through the transformations, to the targets
+type Pipeline struct {
+	source      sourceiface.Source
+	transformer *transformer.Transformer
+	router      *Router
+	observer    *observer.Observer
+
+	drainTimeout time.Duration
+
+	// background runs alongside the pipeline until its context is cancelled, such as the config reloader
+	background []func(ctx context.Context)
+
+	started bool
+	mu      sync.Mutex
+}
+
+// NewPipeline wires the components of a pipeline together, without starting anything
+func NewPipeline(c *PipelineComponents) (*Pipeline, error) {
+	if c.Source == nil {
+		return nil, errors.New("a pipeline needs a source")
+	}
+	if len(c.Targets) == 0 {
+		return nil, errors.New("at least one target must be configured")
+	}
+	if c.FilterTarget == nil || c.FailureTarget == nil || c.FailureParser == nil {
+		return nil, errors.New("a pipeline needs a filter target, a failure target and a failure parser")
+	}
+	if c.Observer == nil {
+		return nil, errors.New("a pipeline needs an observer")
+	}
+
+	if err := c.Retry.Validate(); err != nil {
+		return nil, err
+	}
+	if err := c.Spill.Validate(); err != nil {
+		return nil, err
+	}
+	spillLogs, err := newSpillLogs(c.Spill, c.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Shutdown.Validate(c.Spill); err != nil {
+		return nil, err
+	}
+
+	transformation := c.Transformation
+	if transformation == nil {
+		transformation = transform.NewTransformation()
+	}
+	metrics := c.Metrics
+	if metrics == nil {
+		metrics = c.Observer
+	}
+
+	// The source and the transformer are the sole producers to their output channels, so ownership clearly lies with them.
+	sourceOutput := make(chan *models.Message)
+	c.Source.SetChannels(sourceOutput)
+	transformationOutput := make(chan *models.TransformationResult)
+
+	router := &Router{
+		transformationOutput: transformationOutput,
+		invalidChannel:       make(chan *invalidMessages),
+		AlertChannel:         c.AlertChannel,
+
+		// Targets (all use targetiface.Target)
+		Targets:       c.Targets,
+		FilterTarget:  c.FilterTarget,
+		FailureTarget: c.FailureTarget,
+
+		FailureParser: c.FailureParser,
+		metrics:       metrics,
+		maxTargetSize: c.Targets[0].GetBatchingConfig().MaxMessageBytes,
+		retryConfig:   c.Retry,
+
+		spillLogs:        spillLogs,
+		spillDrainPeriod: time.Duration(c.Spill.DrainIntervalMs) * time.Millisecond,
+
+		shutdownPolicy: c.Shutdown.Policy,
+		inFlight:       newInFlightTracker(),
+	}
+	if err := router.enableCircuitBreakers(c.CircuitBreaker); err != nil {
+		return nil, err
+	}
+
+	return &Pipeline{
+		source:       c.Source,
+		transformer:  transformer.NewTransformer(transformation, sourceOutput, transformationOutput, c.Observer, c.WorkerPool),
+		router:       router,
+		observer:     c.Observer,
+		drainTimeout: time.Duration(c.Shutdown.DrainTimeoutMs) * time.Millisecond,
+	}, nil
+}
+
+// Transformer returns the transformer of the pipeline, whose transformation can be swapped while it runs
+func (p *Pipeline) Transformer() *transformer.Transformer {
+	return p.transformer
+}
+
+// Router returns the router of the pipeline, which reports on the health of its targets
+func (p *Pipeline) Router() *Router {
+	return p.router
+}
+
+// AddBackground runs start alongside the pipeline, with the context it runs in. It must be called before Run.
+func (p *Pipeline) AddBackground(start func(ctx context.Context)) {
+	p.background = append(p.background, start)
+}
+
+// Run starts the pipeline and blocks until it has shut down.
+// It shuts down when ctx is cancelled, when a component hits a fatal error, or when the source has no more messages,
+// and then waits for the messages in flight to be delivered according to the shutdown policy.
+// A pipeline can only be run once.
+func (p *Pipeline) Run(ctx context.Context) error {
+	p.mu.Lock()
+	if p.started {
+		p.mu.Unlock()
+		return errors.New("pipeline has already been run")
+	}
+	p.started = true
+	p.mu.Unlock()
+
+	// The observer may have been started already, to gather metrics while the components were built
+	if p.observer.Running() != nil {
+		p.observer.Start()
+		defer p.observer.Stop()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.router.cancel = cancel
+
+	var wg sync.WaitGroup
+
+	// Start all async components.
+	// If any of them quits naturally, without any error, cancel context to signal we should shut down application.
+	runAsync := func(start func()) {
+		wg.Go(func() {
+			defer cancel()
+			start()
+		})
+	}
+
+	runAsync(func() { p.source.Start(ctx) })
+	runAsync(p.transformer.Start)
+	runAsync(p.router.Start)
+	for _, start := range p.background {
+		runAsync(func() { start(ctx) })
+	}
+
+	// Wait for context cancellation, might be caused by:
+	// - OS signal, or the caller of Run
+	// - Component calling cancel() due to fatal error
+	// - Component quits naturally
+	<-ctx.Done()
+
+	log.Infof("Starting graceful shutdown with policy '%s'. Waiting up to %s for app to complete shutdown, %d messages in flight...", p.router.shutdownPolicy, p.drainTimeout, p.router.InFlight())
+	p.router.beginDrain()
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("App shutdown completed successfully")
+	case <-time.After(p.drainTimeout):
+		log.Warnf("Shutdown timed out after %s, forcing quit...", p.drainTimeout)
+		p.router.abandonInFlight()
+	}
+	p.router.logDrainReport()
+
+	return nil
+}
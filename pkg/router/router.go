@@ -9,11 +9,13 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	retry "github.com/avast/retry-go/v4"
@@ -23,6 +25,7 @@ import (
 	"github.com/snowplow/snowbridge/v5/config"
 	"github.com/snowplow/snowbridge/v5/pkg/failure"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/spill"
 	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 )
 
@@ -30,6 +33,9 @@ import (
 type invalidMessages struct {
 	Invalid   []*models.Message
 	Oversized []*models.Message
+
+	// MaxMessageBytes is the limit exceeded by the oversized messages, when it differs from the router's default
+	MaxMessageBytes int
 }
 
 // RouterMetrics defines the methods needed for router metrics tracking
@@ -37,6 +43,7 @@ type RouterMetrics interface {
 	TargetWrite(r *models.TargetWriteResult)
 	TargetWriteInvalid(r *models.TargetWriteResult)
 	TargetWriteFiltered(r *models.TargetWriteResult)
+	TargetBreakerState(targetName string, state int64)
 }
 
 // Router orchestrates data flow from transformation channel to targets
@@ -48,12 +55,14 @@ type Router struct {
 	invalidChannel chan *invalidMessages
 
 	AlertChannel chan error
+	alerts       routerAlerts
 
 	// Function to cancel application context on fatal errors
 	cancel context.CancelFunc
 
 	// Targets (state now lives in targets themselves via embedded BatchingState)
-	Target        *targetiface.Target
+	// Every transformed message is written to each of the good targets
+	Targets       []*targetiface.Target
 	FilterTarget  *targetiface.Target
 	FailureTarget *targetiface.Target
 
@@ -67,6 +76,22 @@ type Router struct {
 
 	// Retry configuration
 	retryConfig *config.RetryConfig
+
+	// Spill logs keyed by good target, batches which exhaust their retries are written here when set
+	spillLogs        map[*targetiface.Target]*spill.Log
+	spillDrainPeriod time.Duration
+	spillDrainers    sync.WaitGroup
+
+	// What to do with undelivered data once shutdown starts, and the messages it has to account for
+	shutdownPolicy string
+	inFlight       *inFlightTracker
+	draining       atomic.Bool
+
+	// State reported to health probes: whether targets were opened, and when the last message arrived
+	openMu        sync.Mutex
+	openErr       error
+	openedRouters int
+	lastDelivered atomic.Int64
 }
 
 func (r *Router) Start() {
@@ -85,24 +110,34 @@ func (r *Router) Start() {
 func (r *Router) Route() {
 	defer r.goodRouterShutdown()
 
-	if err := r.Target.Open(); err != nil {
-		log.WithError(err).Error("Failed to open target")
-		r.cancel()
-		return
+	for _, target := range r.Targets {
+		if err := target.Open(); err != nil {
+			log.WithError(err).WithField("target", target.Name).Error("Failed to open target")
+			r.recordOpen(errors.Wrapf(err, "target %q", target.Name))
+			r.cancel()
+			return
+		}
 	}
 	if err := r.FilterTarget.Open(); err != nil {
 		log.WithError(err).Error("Failed to open filter target")
+		r.recordOpen(errors.Wrap(err, "filter target"))
 		r.cancel()
 		return
 	}
+	r.recordOpen(nil)
+
+	done := make(chan struct{})
+	defer close(done)
+	ticks := mergeTickers(r.Targets, done)
+	r.startSpillDrainers(done)
 
 	for {
 		select {
 		// Flush on ticker
-		// For simplicity, we only care about the good target's ticker
-		// Filtered gets flushed whenever that happens
-		case <-r.Target.Ticker.C:
-			r.flushGoodBuffer(r.Target, r.metrics.TargetWrite)
+		// Each good target is flushed on its own ticker
+		// For simplicity, filtered gets flushed whenever any of them ticks
+		case target := <-ticks:
+			r.flushGoodBuffer(target, r.metrics.TargetWrite)
 			r.flushGoodBuffer(r.FilterTarget, r.metrics.TargetWriteFiltered)
 
 		case messages, ok := <-r.transformationOutput:
@@ -110,6 +145,7 @@ func (r *Router) Route() {
 				log.Info("Transformation output channel closed")
 				return
 			}
+			r.lastDelivered.Store(time.Now().UnixNano())
 
 			// Pass invalid data to invalid channel (blocks if full - backpressure)
 			if messages.Invalid != nil {
@@ -129,9 +165,11 @@ func (r *Router) RouteInvalid() {
 
 	if err := r.FailureTarget.Open(); err != nil {
 		log.WithError(err).Error("Failed to open failure target")
+		r.recordOpen(errors.Wrap(err, "failure target"))
 		r.cancel()
 		return
 	}
+	r.recordOpen(nil)
 
 	for {
 		select {
@@ -150,16 +188,58 @@ func (r *Router) RouteInvalid() {
 	}
 }
 
+// recordOpen records the outcome of opening the targets of one of the two route loops
+func (r *Router) recordOpen(err error) {
+	r.openMu.Lock()
+	defer r.openMu.Unlock()
+	if err != nil {
+		r.openErr = err
+		return
+	}
+	r.openedRouters++
+}
+
+// TargetsOpen returns an error until all targets are open, or the error which prevented opening them
+func (r *Router) TargetsOpen() error {
+	r.openMu.Lock()
+	defer r.openMu.Unlock()
+	if r.openErr != nil {
+		return errors.Wrap(r.openErr, "failed to open")
+	}
+	if r.openedRouters < 2 {
+		return errors.New("targets are not open yet")
+	}
+	return nil
+}
+
+// LastActivity returns when a message was last delivered to the router or acked, zero if never
+func (r *Router) LastActivity() time.Time {
+	last := r.inFlight.lastAcked()
+	if delivered := r.lastDelivered.Load(); delivered > last {
+		last = delivered
+	}
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
 // WriteBatch deals with writing a single batch to a (non-failure) target
 func (r *Router) WriteBatch(batch []*models.Message, target *targetiface.Target, metricsFunc func(*models.TargetWriteResult)) {
-	target.SpawnThrottledAsyncWrite(func() {
+	r.inFlight.track(target, batch)
+	if r.abandonBatch(target, batch) {
+		return
+	}
+
+	target.SpawnThrottledAsyncWrite(batch, func() {
 		var writeResult *models.TargetWriteResult
 
 		invalids := make([]*models.Message, 0)
 		messagesToSend := batch
 		writeFunc := func() error {
 			var err error
-			writeResult, err = target.Write(messagesToSend)
+			writeResult, err = target.GuardedWrite(messagesToSend)
+			writeResult.TargetName = target.Name
 			metricsFunc(writeResult)
 
 			messagesToSend = writeResult.Failed
@@ -167,7 +247,7 @@ func (r *Router) WriteBatch(batch []*models.Message, target *targetiface.Target,
 			return err
 		}
 
-		err, sendToInvalid := handleWriteWithRetryConfig(r.retryConfig, writeFunc, r.AlertChannel)
+		err, sendToInvalid := handleWriteWithRetryConfig(r.retryConfig, writeFunc, r.setupAlerts(target))
 
 		if err != nil {
 			if sendToInvalid {
@@ -178,7 +258,7 @@ func (r *Router) WriteBatch(batch []*models.Message, target *targetiface.Target,
 						invalids = append(invalids, writeResult.Failed...)
 					}
 				}
-			} else {
+			} else if !r.spillBatch(target, writeResult.Failed, err) {
 				err = errors.Wrap(err, "Target write failed after retries")
 				r.signalUnrecoverableError(err, writeResult.Failed)
 			}
@@ -193,7 +273,7 @@ func (r *Router) WriteBatch(batch []*models.Message, target *targetiface.Target,
 
 // WriteFailureBatch writes a batch to the failure target
 func (r *Router) WriteFailureBatch(batch []*models.Message, metricsFunc func(*models.TargetWriteResult)) {
-	r.FailureTarget.SpawnThrottledAsyncWrite(func() {
+	r.FailureTarget.SpawnThrottledAsyncWrite(batch, func() {
 		var writeResult *models.TargetWriteResult
 
 		invalids := make([]*models.Message, 0)
@@ -221,6 +301,44 @@ func (r *Router) WriteFailureBatch(batch []*models.Message, metricsFunc func(*mo
 	})
 }
 
+// enableCircuitBreakers gives each good target its own circuit breaker
+func (r *Router) enableCircuitBreakers(cfg *config.CircuitBreakerConfig) error {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	breakerConfig := targetiface.CircuitBreakerConfig{
+		FailureRate:  cfg.FailureRate,
+		MinRequests:  cfg.MinRequests,
+		Window:       time.Duration(cfg.WindowMs) * time.Millisecond,
+		OpenDuration: time.Duration(cfg.OpenDurationMs) * time.Millisecond,
+	}
+	for _, target := range r.Targets {
+		target.Breaker = targetiface.NewCircuitBreaker(breakerConfig, r.breakerStateChanged(target))
+	}
+	return nil
+}
+
+// breakerStateChanged reports circuit breaker transitions of a target to the logs, metrics and webhook monitoring
+func (r *Router) breakerStateChanged(target *targetiface.Target) func(from, to targetiface.BreakerState) {
+	return func(from, to targetiface.BreakerState) {
+		log.WithField("target", target.Name).Warnf("Circuit breaker changed from %s to %s", from, to)
+		r.metrics.TargetBreakerState(target.Name, int64(to))
+
+		// Only this breaker's own alert is resolved when it closes, not a setup error or another breaker's
+		source := alertSource{target: target, breaker: true}
+		switch to {
+		case targetiface.BreakerOpen:
+			r.raiseAlert(source, fmt.Errorf("circuit breaker opened for target %q after repeated write failures", target.Name))
+		case targetiface.BreakerClosed:
+			r.resolveAlert(source)
+		}
+	}
+}
+
 func (r *Router) handleGoodMessages(messages *models.TransformationResult) {
 	if messages.Transformed != nil {
 
@@ -229,18 +347,114 @@ func (r *Router) handleGoodMessages(messages *models.TransformationResult) {
 			return
 		}
 
-		batchToSend, oversized := r.Target.AddMessage(messages.Transformed)
+		if route := messages.Transformed.Route; route != "" {
+			target := r.findTarget(route)
+			if target == nil {
+				messages.Transformed.SetError(&models.TransformationError{
+					SafeMessage: fmt.Sprintf("message routed to unknown target %q", route),
+					Err:         fmt.Errorf("no target named %q", route),
+				})
+				r.invalidChannel <- &invalidMessages{Invalid: []*models.Message{messages.Transformed}}
+				return
+			}
 
-		// If we have a batch ready, send it
-		if batchToSend != nil {
-			r.WriteBatch(batchToSend, r.Target, r.metrics.TargetWrite)
+			r.addToTarget(target, messages.Transformed)
+			return
 		}
 
-		// Pass oversized data to invalid channel (blocks if full - backpressure)
-		if oversized != nil {
-			r.invalidChannel <- &invalidMessages{Oversized: []*models.Message{oversized}}
+		copies := fanOut(messages.Transformed, len(r.Targets))
+		for i, target := range r.Targets {
+			r.addToTarget(target, copies[i])
+		}
+	}
+}
+
+// addToTarget adds a message to the target's batch, writing the batch if it is ready
+func (r *Router) addToTarget(target *targetiface.Target, message *models.Message) {
+	batchToSend, oversized := target.AddMessage(message)
+
+	// If we have a batch ready, send it
+	if batchToSend != nil {
+		r.WriteBatch(batchToSend, target, r.metrics.TargetWrite)
+	}
+
+	// Pass oversized data to invalid channel (blocks if full - backpressure)
+	if oversized != nil {
+		r.invalidChannel <- &invalidMessages{
+			Oversized:       []*models.Message{oversized},
+			MaxMessageBytes: target.GetBatchingConfig().MaxMessageBytes,
+		}
+	}
+}
+
+// findTarget returns the good target with the given name, or nil if there is none
+func (r *Router) findTarget(name string) *targetiface.Target {
+	for _, target := range r.Targets {
+		if target.Name == name {
+			return target
+		}
+	}
+	return nil
+}
+
+// fanOut returns one copy of the message per good target, sharing the same data.
+// The original message is acked only once every copy has been acked,
+// and nacked as soon as any copy is nacked.
+func fanOut(message *models.Message, count int) []*models.Message {
+	if count == 1 {
+		return []*models.Message{message}
+	}
+
+	var pending atomic.Int64
+	pending.Store(int64(count))
+	var nacked atomic.Bool
+	var nackOnce sync.Once
+
+	ackFunc := func() {
+		if pending.Add(-1) == 0 && !nacked.Load() && message.AckFunc != nil {
+			message.AckFunc()
 		}
 	}
+	nackFunc := func() {
+		nackOnce.Do(func() {
+			nacked.Store(true)
+			if message.NackFunc != nil {
+				message.NackFunc()
+			}
+		})
+	}
+
+	copies := make([]*models.Message, count)
+	for i := range copies {
+		msgCopy := *message
+		msgCopy.AckFunc = ackFunc
+		msgCopy.NackFunc = nackFunc
+		copies[i] = &msgCopy
+	}
+	return copies
+}
+
+// mergeTickers forwards the ticks of every good target onto a single channel,
+// so that the route loop can flush each target on its own schedule.
+func mergeTickers(targets []*targetiface.Target, done <-chan struct{}) <-chan *targetiface.Target {
+	ticks := make(chan *targetiface.Target)
+	for _, target := range targets {
+		go func() {
+			for {
+				select {
+				case <-done:
+					return
+				case <-target.Ticker.C:
+					select {
+					case ticks <- target:
+					case <-done:
+						return
+					}
+				}
+			}
+		}()
+	}
+	return ticks
 }
 
 func (r *Router) handleFilteredMessages(messages *models.TransformationResult) {
@@ -262,15 +476,22 @@ func (r *Router) goodRouterShutdown() {
 	log.Info("Flushing and shutting down good router")
 
 	// Write any current batches
-	r.flushGoodBuffer(r.Target, r.metrics.TargetWrite)
+	for _, target := range r.Targets {
+		r.flushGoodBuffer(target, r.metrics.TargetWrite)
+	}
 	r.flushGoodBuffer(r.FilterTarget, r.metrics.TargetWriteFiltered)
 
 	// Wait for everything that can output to invalid
-	r.Target.WaitGroup.Wait()
+	for _, target := range r.Targets {
+		target.WaitGroup.Wait()
+	}
 	r.FilterTarget.WaitGroup.Wait()
+	r.spillDrainers.Wait()
 
-	log.Info("Closing target and filter target...")
-	r.Target.Close()
+	log.Info("Closing targets and filter target...")
+	for _, target := range r.Targets {
+		target.Close()
+	}
 	r.FilterTarget.Close()
 
 	// Close the invalid channel
@@ -295,7 +516,12 @@ func (r *Router) handleInvalidMessages(messages *invalidMessages) {
 		return
 	}
 
-	oversizedInvalids, err := r.FailureParser.MakeOversizedPayloads(r.maxTargetSize, messages.Oversized)
+	maxTargetSize := r.maxTargetSize
+	if messages.MaxMessageBytes > 0 {
+		maxTargetSize = messages.MaxMessageBytes
+	}
+
+	oversizedInvalids, err := r.FailureParser.MakeOversizedPayloads(maxTargetSize, messages.Oversized)
 	if err != nil {
 		err = errors.Wrap(err, "Failed to transform oversized messages")
 		r.signalUnrecoverableError(err, messages.Oversized)
@@ -328,28 +554,33 @@ func (r *Router) handleInvalidMessages(messages *invalidMessages) {
 // - transient errors (any other error not of the above types):
 // -- configurable retry attempts, no alerts
 // Type of an error is decided based on a response returned by the target.
-func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, alertChan chan error) (err error, sendToInvalid bool) {
+// Each kind waits between retries according to its backoff mode, capped by max_delay_ms,
+// and stops retrying once its deadline_ms has passed. A delay suggested by the target
+// through a throttle error is honoured instead of the backoff.
+// alert, when set, is called with each setup error, then with nil once they are resolved.
+func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, alert func(err error)) (err error, sendToInvalid bool) {
 	setupErrored := false
 
-	retryOnlySetupErrors := retry.RetryIf(func(err error) bool {
+	setupBackoff := newBackoffPolicy(cfg.Setup.Backoff, cfg.Setup.Delay, cfg.Setup.MaxDelay, cfg.Setup.Deadline)
+	isSetupError := func(err error) bool {
 		_, isSetup := err.(models.SetupWriteError)
 		return isSetup
-	})
+	}
 
 	onSetupError := retry.OnRetry(func(attempt uint, err error) {
 		log.Warnf("Setup target write error. Attempt: %d, error: %s\n", attempt+1, err)
-		if alertChan != nil {
+		if alert != nil {
 			setupErrored = true
-			alertChan <- err
+			alert(err)
 		}
 	})
 
 	//First try to handle error as setup...
 	err = retry.Do(
 		write,
-		retryOnlySetupErrors,
+		setupBackoff.retryIf(isSetupError),
 		onSetupError,
-		retry.Delay(time.Duration(cfg.Setup.Delay)*time.Millisecond),
+		setupBackoff.delayOption(0),
 		retry.Attempts(uint(cfg.Setup.MaxAttempts)),
 		retry.LastErrorOnly(true),
 	)
@@ -367,8 +598,8 @@ func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, ale
 
 	// Now, `err` is either nil or no longer setup-related
 	// Thus we should reset monitoring to re-enable heartbeats
-	if alertChan != nil && setupErrored {
-		alertChan <- nil
+	if alert != nil && setupErrored {
+		alert(nil)
 	}
 
 	if err == nil {
@@ -380,9 +611,10 @@ func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, ale
 		log.Warnf("Throttle target write error. Starting retrying. error: %s\n", err)
 		// We already had at least 1 attempt from above 'setup' retrying section,
 		// so before we start throttle retrying we need to add 'manual' initial delay.
-		time.Sleep(time.Duration(cfg.Throttle.Delay) * time.Millisecond)
+		throttleBackoff := newBackoffPolicy(cfg.Throttle.Backoff, cfg.Throttle.Delay, cfg.Throttle.MaxDelay, cfg.Throttle.Deadline)
+		throttleBackoff.sleep(0, err)
 
-		retryOnlyThrottleErrors := retry.RetryIf(func(err error) bool {
+		retryOnlyThrottleErrors := throttleBackoff.retryIf(func(err error) bool {
 			_, isThrottle := err.(models.ThrottleWriteError)
 			return isThrottle
 		})
@@ -394,7 +626,7 @@ func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, ale
 			write,
 			retryOnlyThrottleErrors,
 			onThrottleError,
-			retry.Delay(time.Duration(cfg.Throttle.Delay)*time.Millisecond),
+			throttleBackoff.delayOption(1),
 			retry.Attempts(uint(cfg.Throttle.MaxAttempts)),
 			retry.LastErrorOnly(true),
 		)
@@ -414,13 +646,14 @@ func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, ale
 	log.Warnf("Transient target write error. Starting retrying. error: %s\n", err)
 	// We already had at least 1 attempt from above 'throttle' retrying section,
 	// so before we start transient retrying we need to add 'manual' initial delay.
-	time.Sleep(time.Duration(cfg.Transient.Delay) * time.Millisecond)
+	transientBackoff := newBackoffPolicy(cfg.Transient.Backoff, cfg.Transient.Delay, cfg.Transient.MaxDelay, cfg.Transient.Deadline)
+	transientBackoff.sleep(0, err)
 
 	onTransientError := retry.OnRetry(func(retry uint, err error) {
 		log.Warnf("Retry failed with transient error. Retry counter: %d, error: %s\n", retry+1, err)
 	})
 
-	retryOnlyNotFatal := retry.RetryIf(func(err error) bool {
+	retryOnlyNotFatal := transientBackoff.retryIf(func(err error) bool {
 		_, isFatal := err.(models.FatalWriteError)
 		return !isFatal
 	})
@@ -429,8 +662,8 @@ func handleWriteWithRetryConfig(cfg *config.RetryConfig, write func() error, ale
 		write,
 		retryOnlyNotFatal,
 		onTransientError,
-		// * 2 because we have initial sleep above
-		retry.Delay(time.Duration(cfg.Transient.Delay*2)*time.Millisecond),
+		// the initial sleep above counts as the first retry's delay
+		transientBackoff.delayOption(1),
 		retry.Attempts(uint(cfg.Transient.MaxAttempts)),
 		retry.LastErrorOnly(true),
 	)
@@ -472,7 +705,7 @@ func handleSimpleWrite(write func() error) error {
 }
 
 func (r *Router) flushGoodBuffer(target *targetiface.Target, metricsFunc func(*models.TargetWriteResult)) {
-	if messages := target.Flush(); messages != nil {
+	for _, messages := range target.FlushAll() {
 		r.WriteBatch(messages, target, metricsFunc)
 	}
 }
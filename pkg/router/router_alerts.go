@@ -0,0 +1,90 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"sync"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// alertSource is what raised an alert: setup errors of a target, or its circuit breaker
+type alertSource struct {
+	target  *targetiface.Target
+	breaker bool
+}
+
+// routerAlerts tracks the alert webhook monitoring is on, so that an alert is only resolved by what raised it.
+// Alerts of breakers which are still open are raised again once the alert on top of them is resolved.
+type routerAlerts struct {
+	mu       sync.Mutex
+	raisedBy *alertSource
+	breakers map[*targetiface.Target]error
+}
+
+// raiseAlert sends an alert to webhook monitoring, on behalf of source
+func (r *Router) raiseAlert(source alertSource, err error) {
+	if r.AlertChannel == nil {
+		return
+	}
+	r.alerts.mu.Lock()
+	defer r.alerts.mu.Unlock()
+
+	if source.breaker {
+		if r.alerts.breakers == nil {
+			r.alerts.breakers = make(map[*targetiface.Target]error)
+		}
+		r.alerts.breakers[source.target] = err
+	}
+	r.alerts.raisedBy = &source
+	r.AlertChannel <- err
+}
+
+// resolveAlert resumes heartbeats, unless the alert webhook monitoring is on was raised by something else than source,
+// or another circuit breaker is still open
+func (r *Router) resolveAlert(source alertSource) {
+	if r.AlertChannel == nil {
+		return
+	}
+	r.alerts.mu.Lock()
+	defer r.alerts.mu.Unlock()
+
+	if source.breaker {
+		delete(r.alerts.breakers, source.target)
+	}
+	if r.alerts.raisedBy == nil || *r.alerts.raisedBy != source {
+		return
+	}
+
+	for target, err := range r.alerts.breakers {
+		r.alerts.raisedBy = &alertSource{target: target, breaker: true}
+		r.AlertChannel <- err
+		return
+	}
+	r.alerts.raisedBy = nil
+	r.AlertChannel <- nil
+}
+
+// setupAlerts returns how the retries of writes to target raise and resolve alerts on setup errors
+func (r *Router) setupAlerts(target *targetiface.Target) func(err error) {
+	if r.AlertChannel == nil {
+		return nil
+	}
+	source := alertSource{target: target}
+	return func(err error) {
+		if err != nil {
+			r.raiseAlert(source, err)
+		} else {
+			r.resolveAlert(source)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+func TestRouterAlerts_BreakerKeepsSetupAlert(t *testing.T) {
+	first, _ := createMockTarget(10)
+	second, _ := createMockTarget(10)
+	alertChan := make(chan error, 10)
+	router := &Router{AlertChannel: alertChan, metrics: createMockMetrics()}
+
+	// The breaker of the first target opens, then the second target alerts on a setup error
+	router.breakerStateChanged(first)(targetiface.BreakerClosed, targetiface.BreakerOpen)
+	setupErr := errors.New("setup error")
+	router.setupAlerts(second)(setupErr)
+
+	// The breaker closing doesn't resolve the setup alert
+	router.breakerStateChanged(first)(targetiface.BreakerHalfOpen, targetiface.BreakerClosed)
+	if assert.Len(t, alertChan, 2) {
+		assert.Error(t, <-alertChan)
+		assert.Equal(t, setupErr, <-alertChan)
+	}
+
+	// Which is resolved once the second target's setup error is
+	router.setupAlerts(second)(nil)
+	if assert.Len(t, alertChan, 1) {
+		assert.Nil(t, <-alertChan)
+	}
+}
+
+func TestRouterAlerts_OpenBreakerRaisedAgain(t *testing.T) {
+	first, _ := createMockTarget(10)
+	first.Name = "first"
+	second, _ := createMockTarget(10)
+	second.Name = "second"
+	alertChan := make(chan error, 10)
+	router := &Router{AlertChannel: alertChan, metrics: createMockMetrics()}
+
+	router.breakerStateChanged(first)(targetiface.BreakerClosed, targetiface.BreakerOpen)
+	router.breakerStateChanged(second)(targetiface.BreakerClosed, targetiface.BreakerOpen)
+	<-alertChan
+	<-alertChan
+
+	// The second breaker closing brings back the alert of the first one, which is still open
+	router.breakerStateChanged(second)(targetiface.BreakerHalfOpen, targetiface.BreakerClosed)
+	if assert.Len(t, alertChan, 1) {
+		assert.EqualError(t, <-alertChan, `circuit breaker opened for target "first" after repeated write failures`)
+	}
+
+	router.breakerStateChanged(first)(targetiface.BreakerHalfOpen, targetiface.BreakerClosed)
+	if assert.Len(t, alertChan, 1) {
+		assert.Nil(t, <-alertChan)
+	}
+}
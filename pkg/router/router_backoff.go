@@ -0,0 +1,117 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	retry "github.com/avast/retry-go/v4"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// maxBackoffShift bounds the exponent of exponential backoff
+const maxBackoffShift = 32
+
+// maxRetryAfter caps a delay suggested by the target when max delay is not set,
+// so that a bogus hint can't stall a write, and hold its concurrency slot, for hours
+const maxRetryAfter = 5 * time.Minute
+
+// backoffPolicy computes the delays between retries of one retry class
+type backoffPolicy struct {
+	mode     string
+	delay    time.Duration
+	maxDelay time.Duration
+	deadline time.Time
+}
+
+// newBackoffPolicy builds a policy from a retry class' configuration.
+// The deadline, if any, starts counting when the policy is created.
+func newBackoffPolicy(mode string, delayMs, maxDelayMs, deadlineMs int) *backoffPolicy {
+	p := &backoffPolicy{
+		mode:     mode,
+		delay:    time.Duration(delayMs) * time.Millisecond,
+		maxDelay: time.Duration(maxDelayMs) * time.Millisecond,
+	}
+	if deadlineMs > 0 {
+		p.deadline = time.Now().Add(time.Duration(deadlineMs) * time.Millisecond)
+	}
+	return p
+}
+
+// next returns the delay to wait before the retry with the given 0-based index.
+// A delay suggested by the target through lastErr replaces the computed one, within max delay and deadline,
+// or within maxRetryAfter when max delay is not set.
+func (p *backoffPolicy) next(retryIndex uint, lastErr error) time.Duration {
+	if throttleErr, ok := lastErr.(models.ThrottleWriteError); ok && throttleErr.RetryAfter > 0 {
+		hint := throttleErr.RetryAfter
+		if p.maxDelay == 0 {
+			hint = min(hint, maxRetryAfter)
+		}
+		return p.bound(hint)
+	}
+
+	d := p.delay
+	if p.mode != config.BackoffFixed {
+		shift := min(retryIndex, maxBackoffShift)
+		if p.delay > time.Duration(math.MaxInt64)>>shift {
+			d = time.Duration(math.MaxInt64)
+		} else {
+			d = p.delay << shift
+		}
+	}
+	d = p.bound(d)
+	if p.mode == config.BackoffExponentialJitter && d > 0 {
+		d = rand.N(d + 1)
+	}
+	return d
+}
+
+// bound caps a delay by the max delay and the time left until the deadline
+func (p *backoffPolicy) bound(d time.Duration) time.Duration {
+	if p.maxDelay > 0 {
+		d = min(d, p.maxDelay)
+	}
+	if !p.deadline.IsZero() {
+		d = max(min(d, time.Until(p.deadline)), 0)
+	}
+	return d
+}
+
+// expired reports whether the retry deadline has passed
+func (p *backoffPolicy) expired() bool {
+	return !p.deadline.IsZero() && !time.Now().Before(p.deadline)
+}
+
+// sleep waits for the delay before the retry with the given index
+func (p *backoffPolicy) sleep(retryIndex uint, lastErr error) {
+	time.Sleep(p.next(retryIndex, lastErr))
+}
+
+// delayOption plugs the policy into retry-go.
+// skipped is the number of retries already waited for before retry.Do is called.
+func (p *backoffPolicy) delayOption(skipped uint) retry.Option {
+	return retry.DelayType(func(n uint, err error, _ *retry.Config) time.Duration {
+		// retry-go counts retries from 1
+		return p.next(n-1+skipped, err)
+	})
+}
+
+// retryIf wraps a retry condition so that nothing is retried past the deadline
+func (p *backoffPolicy) retryIf(shouldRetry func(error) bool) retry.Option {
+	return retry.RetryIf(func(err error) bool {
+		return shouldRetry(err) && !p.expired()
+	})
+}
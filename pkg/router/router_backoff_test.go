@@ -0,0 +1,184 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+func TestBackoffPolicy_Next(t *testing.T) {
+	assert := assert.New(t)
+
+	fixed := newBackoffPolicy(config.BackoffFixed, 100, 0, 0)
+	assert.Equal(100*time.Millisecond, fixed.next(0, nil))
+	assert.Equal(100*time.Millisecond, fixed.next(5, nil))
+
+	exponential := newBackoffPolicy(config.BackoffExponential, 100, 0, 0)
+	assert.Equal(100*time.Millisecond, exponential.next(0, nil))
+	assert.Equal(200*time.Millisecond, exponential.next(1, nil))
+	assert.Equal(800*time.Millisecond, exponential.next(3, nil))
+
+	capped := newBackoffPolicy(config.BackoffExponential, 100, 300, 0)
+	assert.Equal(200*time.Millisecond, capped.next(1, nil))
+	assert.Equal(300*time.Millisecond, capped.next(2, nil))
+	assert.Equal(300*time.Millisecond, capped.next(1000, nil))
+
+	// Large retry counts must not overflow into a negative or short delay
+	uncapped := newBackoffPolicy(config.BackoffExponential, 20000, 0, 0)
+	assert.Greater(uncapped.next(1000, nil), 24*time.Hour)
+
+	jitter := newBackoffPolicy(config.BackoffExponentialJitter, 100, 300, 0)
+	for range 100 {
+		d := jitter.next(4, nil)
+		assert.GreaterOrEqual(d, time.Duration(0))
+		assert.LessOrEqual(d, 300*time.Millisecond)
+	}
+}
+
+func TestBackoffPolicy_RetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := newBackoffPolicy(config.BackoffExponential, 100, 5000, 0)
+	hinted := models.ThrottleWriteError{Err: errors.New("slow down"), RetryAfter: 2 * time.Second}
+	assert.Equal(2*time.Second, policy.next(0, hinted))
+	assert.Equal(2*time.Second, policy.next(3, hinted))
+
+	// The hint still honours max delay
+	hinted.RetryAfter = time.Minute
+	assert.Equal(5*time.Second, policy.next(0, hinted))
+
+	// Without a hint the backoff applies
+	assert.Equal(400*time.Millisecond, policy.next(2, models.ThrottleWriteError{Err: errors.New("slow down")}))
+}
+
+func TestBackoffPolicy_RetryAfterWithoutMaxDelay(t *testing.T) {
+	assert := assert.New(t)
+
+	// A hint is capped even when max delay is not set
+	policy := newBackoffPolicy(config.BackoffExponential, 100, 0, 0)
+	hinted := models.ThrottleWriteError{Err: errors.New("slow down"), RetryAfter: 24 * time.Hour}
+	assert.Equal(maxRetryAfter, policy.next(0, hinted))
+
+	hinted.RetryAfter = time.Minute
+	assert.Equal(time.Minute, policy.next(0, hinted))
+
+	// A max delay above the cap is honoured
+	policy = newBackoffPolicy(config.BackoffExponential, 100, 3600000, 0)
+	hinted.RetryAfter = 24 * time.Hour
+	assert.Equal(time.Hour, policy.next(0, hinted))
+}
+
+func TestHandleWriteWithRetryConfig_ThrottleRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &config.RetryConfig{
+		Setup:     &config.SetupRetryConfig{Delay: 1, MaxAttempts: 1},
+		Throttle:  &config.ThrottleRetryConfig{Delay: 10000, MaxAttempts: 3},
+		Transient: &config.TransientRetryConfig{Delay: 1, MaxAttempts: 1},
+	}
+
+	attempts := 0
+	write := func() error {
+		attempts++
+		if attempts < 3 {
+			return models.ThrottleWriteError{Err: errors.New("slow down"), RetryAfter: 20 * time.Millisecond}
+		}
+		return nil
+	}
+
+	start := time.Now()
+	err, _ := handleWriteWithRetryConfig(cfg, write, nil)
+
+	assert.Nil(err)
+	assert.Equal(3, attempts)
+	// The 10s configured delay was replaced by the target's hint
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestBackoffPolicy_Deadline(t *testing.T) {
+	assert := assert.New(t)
+
+	policy := newBackoffPolicy(config.BackoffFixed, 10000, 0, 50)
+	assert.False(policy.expired())
+	assert.LessOrEqual(policy.next(0, nil), 50*time.Millisecond)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(policy.expired())
+	assert.Equal(time.Duration(0), policy.next(0, nil))
+}
+
+func TestHandleWriteWithRetryConfig_Deadline(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := &config.RetryConfig{
+		Setup:     &config.SetupRetryConfig{Delay: 1, MaxAttempts: 1},
+		Throttle:  &config.ThrottleRetryConfig{Delay: 1, MaxAttempts: 1},
+		Transient: &config.TransientRetryConfig{Delay: 20, MaxAttempts: 1000, Backoff: config.BackoffFixed, Deadline: 100, InvalidAfterMax: true},
+	}
+
+	attempts := 0
+	write := func() error {
+		attempts++
+		return errors.New("transient failure")
+	}
+
+	start := time.Now()
+	err, sendToInvalid := handleWriteWithRetryConfig(cfg, write, nil)
+	elapsed := time.Since(start)
+
+	assert.EqualError(err, "transient failure")
+	assert.True(sendToInvalid)
+	assert.Less(elapsed, time.Second)
+	assert.Less(attempts, 10)
+}
+
+func TestWriteBatch_RetryBackoffFixed(t *testing.T) {
+	target, mockDriver := createMockTarget(10)
+	mockCancel, _ := createMockCancel()
+
+	router := &Router{
+		Targets:        []*targetiface.Target{target},
+		invalidChannel: make(chan *invalidMessages, 10),
+		cancel:         mockCancel,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 1, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 1, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 30, MaxAttempts: 4, Backoff: config.BackoffFixed},
+		},
+	}
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "fail-for-3"},
+	}
+	ackedMessages, _, mu := addAckNackTracking(testMessages)
+
+	start := time.Now()
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+	elapsed := time.Since(start)
+
+	assert.Equal(t, 4, len(mockDriver.GetReceivedBatches()))
+	// Three fixed delays of 30ms; exponential backoff would have waited 30+60+120ms
+	assert.Less(t, elapsed, 180*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.True(t, ackedMessages["message1"])
+}
@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"errors"
@@ -59,7 +59,7 @@ func TestRouter_MetricsTracking(t *testing.T) {
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
 		AlertChannel:         make(chan error, 10),
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		FailureTarget:        failureTarget,
 		FailureParser:        failureParser,
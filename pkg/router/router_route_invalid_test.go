@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"encoding/json"
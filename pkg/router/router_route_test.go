@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"testing"
@@ -46,7 +46,7 @@ func TestRoute_MixedMessages(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		retryConfig: &config.RetryConfig{
 			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -117,7 +117,7 @@ func TestRoute_MixedMessages(t *testing.T) {
 
 	// Wait for writes to complete
 	time.Sleep(50 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 	router.FilterTarget.WaitGroup.Wait()
 
 	// Drain invalid messages from channel
@@ -229,7 +229,7 @@ func TestRoute_BatchByteLimit(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		retryConfig: &config.RetryConfig{
 			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -267,7 +267,7 @@ func TestRoute_BatchByteLimit(t *testing.T) {
 
 	// Sleep for a but in case waitgroup.Add() hasn't yet been called in the async write
 	time.Sleep(50 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 
 	// Verify one batch was sent with first 3 messages (triggered by 4th exceeding limit)
 	targetBatches := targetDriver.GetReceivedBatches()
@@ -301,7 +301,7 @@ func TestRoute_FatalError(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		retryConfig: &config.RetryConfig{
 			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -333,7 +333,7 @@ func TestRoute_FatalError(t *testing.T) {
 
 	// Wait for async write to complete
 	time.Sleep(50 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 
 	// Verify cancel was called due to fatal error
 	assert.True(t, wasCancelCalled(), "Cancel should be called due to fatal error in target write")
@@ -370,7 +370,7 @@ func TestRoute_TransformedMessageWithNilData(t *testing.T) {
 			transformationOutput: transformationOutput,
 			invalidChannel:       invalidChannel,
 			cancel:               mockCancel,
-			Target:               target,
+			Targets:              []*targetiface.Target{target},
 			FilterTarget:         filterTarget,
 			retryConfig: &config.RetryConfig{
 				Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -422,7 +422,7 @@ func TestRoute_TransformedMessageWithNilData(t *testing.T) {
 			transformationOutput: transformationOutput,
 			invalidChannel:       invalidChannel,
 			cancel:               mockCancel,
-			Target:               target,
+			Targets:              []*targetiface.Target{target},
 			FilterTarget:         filterTarget,
 			retryConfig: &config.RetryConfig{
 				Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -473,7 +473,7 @@ func TestRoute_TickerFlush(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		retryConfig: &config.RetryConfig{
 			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
@@ -503,7 +503,7 @@ func TestRoute_TickerFlush(t *testing.T) {
 	// Wait longer than the flush period, and check results.
 
 	time.Sleep(150 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 
 	// Verify no batches sent yet (ticker hasn't fired because it's stopped)
 	assert.Equal(t, 0, len(targetDriver.GetReceivedBatches()), "Should have 0 batches (ticker is stopped)")
@@ -527,11 +527,11 @@ func TestRoute_TickerFlush(t *testing.T) {
 
 	// Wait for the full batch write to complete
 	time.Sleep(50 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 
 	// Wait a bit longer than the flush period for ticker to fire and flush the partial batch
 	time.Sleep(120 * time.Millisecond)
-	router.Target.WaitGroup.Wait()
+	router.Targets[0].WaitGroup.Wait()
 
 	// Get batches with timestamps
 	batchesWithTimestamps := targetDriver.GetReceivedBatchesWithTimestamps()
@@ -563,3 +563,216 @@ func TestRoute_TickerFlush(t *testing.T) {
 	batchesWithTimestamps = targetDriver.GetReceivedBatchesWithTimestamps()
 	assert.Equal(t, 2, len(batchesWithTimestamps), "Should have still only 2 batches")
 }
+
+func TestRoute_MultipleTargets(t *testing.T) {
+	batchingConfig := targetiface.BatchingConfig{
+		MaxBatchMessages:  2,
+		MaxBatchBytes:     1000000,
+		MaxMessageBytes:   1000000,
+		FlushPeriodMillis: 3600000, // 1 hour
+	}
+	kafkaTarget, kafkaDriver := createMockTargetWithConfig(10, batchingConfig)
+	defer kafkaTarget.Ticker.Stop()
+	kafkaTarget.Name = "kafka"
+
+	httpTarget, httpDriver := createMockTargetWithConfig(10, batchingConfig)
+	defer httpTarget.Ticker.Stop()
+	httpTarget.Name = "http"
+
+	filterTarget, _ := createMockTargetWithConfig(10, batchingConfig)
+	defer filterTarget.Ticker.Stop()
+
+	transformationOutput := make(chan *models.TransformationResult, 10)
+	invalidChannel := make(chan *invalidMessages, 10)
+	mockCancel, wasCancelCalled := createMockCancel()
+	metrics := createMockMetrics()
+
+	router := &Router{
+		transformationOutput: transformationOutput,
+		invalidChannel:       invalidChannel,
+		cancel:               mockCancel,
+		Targets:              []*targetiface.Target{kafkaTarget, httpTarget},
+		FilterTarget:         filterTarget,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 100, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 100, MaxAttempts: 1},
+		},
+		metrics: metrics,
+	}
+
+	messages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "success"},
+		{Data: []byte("message2"), PartitionKey: "success"},
+	}
+	acked, nacked, mu := addAckNackTracking(messages)
+
+	go router.Route()
+
+	for _, msg := range messages {
+		transformationOutput <- models.NewTransformationResult(msg, nil, nil)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	kafkaTarget.WaitGroup.Wait()
+	httpTarget.WaitGroup.Wait()
+
+	// Every target receives every message
+	for _, driver := range []*mockTargetDriver{kafkaDriver, httpDriver} {
+		batches := driver.GetReceivedBatches()
+		if !assert.Equal(t, 1, len(batches)) {
+			t.FailNow()
+		}
+		assert.Equal(t, "message1", string(batches[0][0].Data))
+		assert.Equal(t, "message2", string(batches[0][1].Data))
+	}
+
+	// Each target gets its own copy of the message
+	assert.NotSame(t, kafkaDriver.GetReceivedBatches()[0][0], httpDriver.GetReceivedBatches()[0][0])
+
+	mu.Lock()
+	assert.Equal(t, map[string]bool{"message1": true, "message2": true}, acked)
+	assert.Empty(t, nacked)
+	mu.Unlock()
+
+	// Metrics are reported per target
+	buffer := metrics.GetWriteBuffer()
+	assert.Equal(t, int64(4), buffer.MsgSent)
+	if assert.Equal(t, 2, len(buffer.Targets)) {
+		assert.Equal(t, &models.TargetStats{TargetResults: 1, MsgSent: 2}, buffer.Targets["kafka"])
+		assert.Equal(t, &models.TargetStats{TargetResults: 1, MsgSent: 2}, buffer.Targets["http"])
+	}
+
+	assert.False(t, wasCancelCalled())
+	close(transformationOutput)
+}
+
+func TestRoute_RoutedMessages(t *testing.T) {
+	batchingConfig := targetiface.BatchingConfig{
+		MaxBatchMessages:  1,
+		MaxBatchBytes:     1000000,
+		MaxMessageBytes:   1000000,
+		FlushPeriodMillis: 3600000, // 1 hour
+	}
+	kafkaTarget, kafkaDriver := createMockTargetWithConfig(10, batchingConfig)
+	defer kafkaTarget.Ticker.Stop()
+	kafkaTarget.Name = "kafka"
+
+	httpTarget, httpDriver := createMockTargetWithConfig(10, batchingConfig)
+	defer httpTarget.Ticker.Stop()
+	httpTarget.Name = "http"
+
+	filterTarget, _ := createMockTargetWithConfig(10, batchingConfig)
+	defer filterTarget.Ticker.Stop()
+
+	transformationOutput := make(chan *models.TransformationResult, 10)
+	invalidChannel := make(chan *invalidMessages, 10)
+	mockCancel, wasCancelCalled := createMockCancel()
+
+	router := &Router{
+		transformationOutput: transformationOutput,
+		invalidChannel:       invalidChannel,
+		cancel:               mockCancel,
+		Targets:              []*targetiface.Target{kafkaTarget, httpTarget},
+		FilterTarget:         filterTarget,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 100, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 100, MaxAttempts: 1},
+		},
+		metrics: createMockMetrics(),
+	}
+
+	messages := []*models.Message{
+		{Data: []byte("to-kafka"), PartitionKey: "success", Route: "kafka"},
+		{Data: []byte("to-http"), PartitionKey: "success", Route: "http"},
+		{Data: []byte("to-nowhere"), PartitionKey: "success", Route: "unknown"},
+	}
+
+	go router.Route()
+
+	for _, msg := range messages {
+		transformationOutput <- models.NewTransformationResult(msg, nil, nil)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	kafkaTarget.WaitGroup.Wait()
+	httpTarget.WaitGroup.Wait()
+
+	kafkaBatches := kafkaDriver.GetReceivedBatches()
+	if assert.Equal(t, 1, len(kafkaBatches)) {
+		assert.Equal(t, "to-kafka", string(kafkaBatches[0][0].Data))
+	}
+
+	httpBatches := httpDriver.GetReceivedBatches()
+	if assert.Equal(t, 1, len(httpBatches)) {
+		assert.Equal(t, "to-http", string(httpBatches[0][0].Data))
+	}
+
+	// Messages routed to a target that doesn't exist are treated as invalid
+	select {
+	case invalid := <-invalidChannel:
+		if assert.Equal(t, 1, len(invalid.Invalid)) {
+			assert.Equal(t, "to-nowhere", string(invalid.Invalid[0].Data))
+			assert.Contains(t, invalid.Invalid[0].GetError().Error(), `message routed to unknown target "unknown"`)
+		}
+	default:
+		t.Fatal("expected unknown route to be sent to the invalid channel")
+	}
+
+	assert.False(t, wasCancelCalled())
+	close(transformationOutput)
+}
+
+func TestFanOut(t *testing.T) {
+	t.Run("single target gets the original message", func(t *testing.T) {
+		msg := &models.Message{Data: []byte("message")}
+		copies := fanOut(msg, 1)
+
+		assert.Equal(t, 1, len(copies))
+		assert.Same(t, msg, copies[0])
+	})
+
+	t.Run("acks the original only once every copy is acked", func(t *testing.T) {
+		msg := &models.Message{Data: []byte("message")}
+		acked, nacked, mu := addAckNackTracking([]*models.Message{msg})
+
+		copies := fanOut(msg, 3)
+		assert.Equal(t, 3, len(copies))
+
+		copies[0].AckFunc()
+		copies[1].AckFunc()
+		mu.Lock()
+		assert.Empty(t, acked)
+		mu.Unlock()
+
+		copies[2].AckFunc()
+		mu.Lock()
+		assert.True(t, acked["message"])
+		assert.Empty(t, nacked)
+		mu.Unlock()
+	})
+
+	t.Run("nacks the original when any copy is nacked", func(t *testing.T) {
+		msg := &models.Message{Data: []byte("message")}
+		acked, nacked, mu := addAckNackTracking([]*models.Message{msg})
+
+		nackCount := 0
+		originalNack := msg.NackFunc
+		msg.NackFunc = func() {
+			nackCount++
+			originalNack()
+		}
+
+		copies := fanOut(msg, 3)
+		copies[0].AckFunc()
+		copies[1].NackFunc()
+		copies[2].NackFunc()
+
+		mu.Lock()
+		assert.Empty(t, acked)
+		assert.True(t, nacked["message"])
+		mu.Unlock()
+		assert.Equal(t, 1, nackCount)
+	})
+}
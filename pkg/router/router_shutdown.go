@@ -0,0 +1,207 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// inFlightTracker follows messages handed to targets until they are acked or nacked,
+// so that shutdown knows what is left to drain and can report on it.
+// All methods are no-ops on a nil tracker.
+type inFlightTracker struct {
+	mu       sync.Mutex
+	messages map[*models.Message]*targetiface.Target
+	draining bool
+	acked    int
+	nacked   int
+
+	// lastAck is when a message was last acked, in unix nanoseconds
+	lastAck atomic.Int64
+}
+
+func newInFlightTracker() *inFlightTracker {
+	return &inFlightTracker{messages: make(map[*models.Message]*targetiface.Target)}
+}
+
+// track registers a batch written to a target, wrapping the ack and nack functions of its messages.
+// The wrapped functions settle a message only once, so that the drain can ack or nack it
+// while a write which is still running does the same.
+func (t *inFlightTracker) track(target *targetiface.Target, batch []*models.Message) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, msg := range batch {
+		if _, tracked := t.messages[msg]; tracked {
+			continue
+		}
+		t.messages[msg] = target
+
+		ack, nack := msg.AckFunc, msg.NackFunc
+		msg.AckFunc = func() {
+			if t.settle(msg, true) && ack != nil {
+				ack()
+			}
+		}
+		msg.NackFunc = func() {
+			if t.settle(msg, false) && nack != nil {
+				nack()
+			}
+		}
+	}
+}
+
+// settle marks a message as done, returning false if it was already settled
+func (t *inFlightTracker) settle(msg *models.Message, acked bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, tracked := t.messages[msg]; !tracked {
+		return false
+	}
+	delete(t.messages, msg)
+	if acked {
+		t.lastAck.Store(time.Now().UnixNano())
+	}
+
+	if t.draining {
+		if acked {
+			t.acked++
+		} else {
+			t.nacked++
+		}
+	}
+	return true
+}
+
+// count returns the number of messages written to targets which are neither acked nor nacked
+func (t *inFlightTracker) count() int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.messages)
+}
+
+// lastAcked returns when a message was last acked, in unix nanoseconds, or 0 if none was
+func (t *inFlightTracker) lastAcked() int64 {
+	if t == nil {
+		return 0
+	}
+	return t.lastAck.Load()
+}
+
+// startDrain starts counting acks and nacks for the shutdown report
+func (t *inFlightTracker) startDrain() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+}
+
+// outstanding returns the unsettled messages grouped by the target they were written to
+func (t *inFlightTracker) outstanding() map[*targetiface.Target][]*models.Message {
+	result := make(map[*targetiface.Target][]*models.Message)
+	if t == nil {
+		return result
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for msg, target := range t.messages {
+		result[target] = append(result[target], msg)
+	}
+	return result
+}
+
+// report returns the acks and nacks counted since the drain started, and what is still in flight
+func (t *inFlightTracker) report() (acked, nacked, inFlight int) {
+	if t == nil {
+		return 0, 0, 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.acked, t.nacked, len(t.messages)
+}
+
+// InFlight returns the number of messages written to targets which are neither acked nor nacked yet
+func (r *Router) InFlight() int {
+	return r.inFlight.count()
+}
+
+// beginDrain switches the router to its shutdown policy.
+// From then on, batches which are ready to write are nacked or spilled instead, unless the policy is to flush.
+func (r *Router) beginDrain() {
+	r.inFlight.startDrain()
+	r.draining.Store(true)
+}
+
+// abandonBatch applies the shutdown policy to a batch about to be written.
+// It returns true if the batch was settled and must not be written.
+func (r *Router) abandonBatch(target *targetiface.Target, batch []*models.Message) bool {
+	if !r.draining.Load() || r.shutdownPolicy == "" || r.shutdownPolicy == config.ShutdownPolicyFlush {
+		return false
+	}
+	r.settleUndelivered(target, batch)
+	return true
+}
+
+// abandonInFlight applies the shutdown policy to every message still in flight when the drain times out.
+// With the flush policy they are left alone, to be redelivered by the source.
+func (r *Router) abandonInFlight() {
+	if r.shutdownPolicy == "" || r.shutdownPolicy == config.ShutdownPolicyFlush {
+		return
+	}
+	for target, messages := range r.inFlight.outstanding() {
+		r.settleUndelivered(target, messages)
+	}
+}
+
+// settleUndelivered spills or nacks messages which won't be written, according to the shutdown policy.
+// Messages which can't be spilled are nacked.
+func (r *Router) settleUndelivered(target *targetiface.Target, messages []*models.Message) {
+	if r.shutdownPolicy == config.ShutdownPolicySpill {
+		if spillLog, ok := r.spillLogs[target]; ok {
+			err := appendAndAck(spillLog, messages)
+			if err == nil {
+				log.WithField("target", target.Name).Infof("Spilled %d undelivered messages to disk on shutdown", len(messages))
+				return
+			}
+			log.WithError(err).WithField("target", target.Name).Error("Failed to spill undelivered messages on shutdown, nacking them")
+		}
+	}
+	log.WithField("target", target.Name).Infof("Nacking %d undelivered messages on shutdown", len(messages))
+	nackMessages(messages)
+}
+
+// logDrainReport logs what happened to messages in flight since the drain started
+func (r *Router) logDrainReport() {
+	acked, nacked, inFlight := r.inFlight.report()
+	fields := log.Fields{"acked": acked, "nacked": nacked, "in_flight": inFlight}
+	if inFlight > 0 {
+		log.WithFields(fields).Warnf("Shutdown drain finished with %d messages neither acked nor nacked, the source will redeliver them", inFlight)
+		return
+	}
+	log.WithFields(fields).Info("Shutdown drain finished")
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+func TestInFlightTracker(t *testing.T) {
+	assert := assert.New(t)
+
+	target, _ := createMockTarget(10)
+	messages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "message1"},
+		{Data: []byte("message2"), PartitionKey: "message2"},
+		{Data: []byte("message3"), PartitionKey: "message3"},
+	}
+	ackedMessages, nackedMessages, mu := addAckNackTracking(messages)
+
+	tracker := newInFlightTracker()
+	tracker.track(target, messages)
+	assert.Equal(3, tracker.count())
+
+	// Settled before the drain, not part of the report
+	messages[0].AckFunc()
+
+	tracker.startDrain()
+	messages[1].AckFunc()
+	messages[2].NackFunc()
+
+	// Settling twice has no effect
+	messages[1].NackFunc()
+	messages[2].AckFunc()
+
+	acked, nacked, inFlight := tracker.report()
+	assert.Equal(1, acked)
+	assert.Equal(1, nacked)
+	assert.Equal(0, inFlight)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(map[string]bool{"message1": true, "message2": true}, ackedMessages)
+	assert.Equal(map[string]bool{"message3": true}, nackedMessages)
+}
+
+func TestWriteBatch_ShutdownPolicies(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Policy        string
+		ExpectWritten bool
+		ExpectAcked   bool
+		ExpectSpilled bool
+	}{
+		{Name: "flush keeps writing", Policy: config.ShutdownPolicyFlush, ExpectWritten: true, ExpectAcked: true},
+		{Name: "nack stops writing", Policy: config.ShutdownPolicyNack},
+		{Name: "spill writes to the spill log", Policy: config.ShutdownPolicySpill, ExpectAcked: true, ExpectSpilled: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			target, mockDriver := createMockTarget(10)
+			target.Name = "mock"
+			router, _ := createSpillRouter(t, target)
+			router.shutdownPolicy = tt.Policy
+			router.inFlight = newInFlightTracker()
+
+			testMessages := []*models.Message{{Data: []byte("message1"), PartitionKey: "message1"}}
+			ackedMessages, nackedMessages, mu := addAckNackTracking(testMessages)
+
+			router.beginDrain()
+			router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+			target.WaitGroup.Wait()
+
+			assert.Equal(tt.ExpectWritten, len(mockDriver.GetReceivedBatches()) == 1)
+			assert.Equal(tt.ExpectSpilled, router.spillLogs[target].Size() > 0)
+			assert.Equal(0, router.InFlight())
+
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(tt.ExpectAcked, ackedMessages["message1"])
+			assert.Equal(!tt.ExpectAcked, nackedMessages["message1"])
+		})
+	}
+}
+
+func TestAbandonInFlight(t *testing.T) {
+	assert := assert.New(t)
+
+	target, _ := createMockTarget(10)
+	router, _ := createSpillRouter(t, target)
+	router.Targets = []*targetiface.Target{target}
+	router.shutdownPolicy = config.ShutdownPolicyNack
+	router.inFlight = newInFlightTracker()
+
+	testMessages := []*models.Message{{Data: []byte("message1"), PartitionKey: "slow"}}
+	ackedMessages, nackedMessages, mu := addAckNackTracking(testMessages)
+
+	// The write is in flight when the drain starts, and still running when it times out
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	router.beginDrain()
+	assert.Equal(1, router.InFlight())
+	router.abandonInFlight()
+
+	// The late ack of the write is ignored, the message was already handed back to the source
+	target.WaitGroup.Wait()
+
+	acked, nacked, inFlight := router.inFlight.report()
+	assert.Equal(0, acked)
+	assert.Equal(1, nacked)
+	assert.Equal(0, inFlight)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.False(ackedMessages["message1"])
+	assert.True(nackedMessages["message1"])
+}
@@ -0,0 +1,187 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/spill"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// newSpillLogs opens a spill log per good target, in a sub-directory named after the target.
+// It returns nil when spilling is disabled.
+func newSpillLogs(cfg *config.SpillConfig, targets []*targetiface.Target) (map[*targetiface.Target]*spill.Log, error) {
+	if cfg == nil || cfg.Path == "" {
+		return nil, nil
+	}
+
+	logs := make(map[*targetiface.Target]*spill.Log, len(targets))
+	for _, target := range targets {
+		spillLog, err := spill.New(filepath.Join(cfg.Path, spillDirName(target.Name)), cfg.MaxBytes, cfg.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		if size := spillLog.Size(); size > 0 {
+			log.WithField("target", target.Name).Infof("Found %d bytes of spilled data, it will be replayed once the target accepts writes", size)
+		}
+		logs[target] = spillLog
+	}
+	return logs, nil
+}
+
+// spillDirName returns the name of the directory holding the spill log of a target within the spill path.
+// Names which aren't a plain file name, such as those holding a `/` or made of dots, are made into one
+// and suffixed with a hash of the name, so that a log never ends up outside the spill path or shared by two targets.
+func spillDirName(targetName string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, targetName)
+	if safe == targetName && strings.Trim(targetName, ".") != "" {
+		return targetName
+	}
+
+	hash := sha256.Sum256([]byte(targetName))
+	return safe + "-" + hex.EncodeToString(hash[:4])
+}
+
+// spillBatch writes a batch which exhausted its retries to the target's spill log and acks it.
+// It returns false if the batch could not be spilled, in which case the caller should treat the failure as unrecoverable.
+func (r *Router) spillBatch(target *targetiface.Target, messages []*models.Message, writeErr error) bool {
+	spillLog, ok := r.spillLogs[target]
+	if !ok || len(messages) == 0 {
+		return false
+	}
+
+	// A fatal error means the data itself will never be accepted, so replaying it would not help
+	if _, isFatal := writeErr.(models.FatalWriteError); isFatal {
+		return false
+	}
+
+	if err := appendAndAck(spillLog, messages); err != nil {
+		log.WithError(err).WithField("target", target.Name).Error("Failed to spill batch to disk")
+		return false
+	}
+
+	log.WithError(writeErr).WithField("target", target.Name).Warnf("Target write failed after retries, spilled %d messages to disk", len(messages))
+	return true
+}
+
+// appendAndAck writes messages to a spill log and acks them, since the spill log now owns their delivery
+func appendAndAck(spillLog *spill.Log, messages []*models.Message) error {
+	if err := spillLog.Append(messages); err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		if msg.AckFunc != nil {
+			msg.AckFunc()
+		}
+	}
+	return nil
+}
+
+// startSpillDrainers starts a goroutine per spill log, which periodically replays spilled batches into its target
+func (r *Router) startSpillDrainers(done <-chan struct{}) {
+	for _, target := range r.Targets {
+		spillLog, ok := r.spillLogs[target]
+		if !ok {
+			continue
+		}
+
+		r.spillDrainers.Go(func() {
+			ticker := time.NewTicker(r.spillDrainPeriod)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					r.drainSpill(target, spillLog, done)
+				}
+			}
+		})
+	}
+}
+
+// drainSpill replays spilled batches into the target, oldest first,
+// until the log is empty or the target fails a write.
+func (r *Router) drainSpill(target *targetiface.Target, spillLog *spill.Log, done <-chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		// Writes would block until an open breaker lets them through, holding up shutdown: replay on a later tick instead
+		if target.Breaker != nil && target.Breaker.State() != targetiface.BreakerClosed {
+			return
+		}
+
+		segment, err := spillLog.Oldest()
+		if err != nil {
+			log.WithError(err).WithField("target", target.Name).Error("Failed to read spilled batch")
+			return
+		}
+		if segment == nil {
+			return
+		}
+
+		// Share the target's rate and concurrency limits with regular writes
+		target.AcquireWriteSlot(segment.Messages)
+		writeResult, writeErr := target.GuardedWrite(segment.Messages)
+		target.ReleaseWriteSlot()
+
+		writeResult.TargetName = target.Name
+		r.metrics.TargetWrite(writeResult)
+
+		if len(writeResult.Invalid) > 0 {
+			r.invalidChannel <- &invalidMessages{Invalid: writeResult.Invalid}
+		}
+
+		// Nothing was accepted, keep the segment as it is and retry it on the next tick
+		if len(writeResult.Failed) == len(segment.Messages) {
+			log.WithError(writeErr).WithField("target", target.Name).Warn("Target still failing, pausing replay of spilled data")
+			return
+		}
+
+		// Spill what the target didn't accept before dropping the segment, so that a crash in between can only cause duplicates
+		if len(writeResult.Failed) > 0 {
+			if err := spillLog.Append(writeResult.Failed); err != nil {
+				log.WithError(err).WithField("target", target.Name).Error("Failed to spill back rejected messages, the whole batch will be replayed")
+				return
+			}
+		}
+
+		if err := spillLog.Remove(segment); err != nil {
+			log.WithError(err).WithField("target", target.Name).Error("Failed to remove replayed batch from spill log")
+			return
+		}
+
+		if writeErr != nil {
+			log.WithError(writeErr).WithField("target", target.Name).Warn("Target still failing, pausing replay of spilled data")
+			return
+		}
+	}
+}
@@ -0,0 +1,227 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/spill"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+func createSpillRouter(t *testing.T, target *targetiface.Target) (*Router, func() bool) {
+	t.Helper()
+
+	spillLogs, err := newSpillLogs(&config.SpillConfig{Path: t.TempDir(), MaxBytes: 1000000, DrainIntervalMs: 100}, []*targetiface.Target{target})
+	if err != nil {
+		t.Fatalf("failed to create spill logs: %s", err)
+	}
+
+	mockCancel, wasCancelCalled := createMockCancel()
+	return &Router{
+		Targets:        []*targetiface.Target{target},
+		invalidChannel: make(chan *invalidMessages, 10),
+		cancel:         mockCancel,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 100, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 100, MaxAttempts: 1},
+		},
+		metrics:   createMockMetrics(),
+		spillLogs: spillLogs,
+	}, wasCancelCalled
+}
+
+func TestWriteBatch_SpillAndReplay(t *testing.T) {
+	target, mockDriver := createMockTarget(10)
+	target.Name = "mock"
+	router, wasCancelCalled := createSpillRouter(t, target)
+
+	// Fails on the initial write and on the single transient retry, then succeeds
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "fail-for-2"},
+		{Data: []byte("message2"), PartitionKey: "fail-for-2"},
+	}
+	ackedMessages, nackedMessages, mu := addAckNackTracking(testMessages)
+
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+
+	// The batch is spilled and acked rather than crashing the app
+	assert.False(t, wasCancelCalled())
+	assert.Positive(t, router.spillLogs[target].Size())
+	mu.Lock()
+	assert.Equal(t, map[string]bool{"message1": true, "message2": true}, ackedMessages)
+	assert.Empty(t, nackedMessages)
+	mu.Unlock()
+
+	// Replaying writes the spilled batch to the recovered target and empties the log
+	router.drainSpill(target, router.spillLogs[target], make(chan struct{}))
+
+	receivedBatches := mockDriver.GetReceivedBatches()
+	if !assert.Equal(t, 3, len(receivedBatches)) {
+		t.FailNow()
+	}
+	replayed := receivedBatches[2]
+	if assert.Equal(t, 2, len(replayed)) {
+		assert.Equal(t, "message1", string(replayed[0].Data))
+		assert.Equal(t, "message2", string(replayed[1].Data))
+	}
+	assert.Zero(t, router.spillLogs[target].Size())
+}
+
+func TestWriteBatch_SpillKeepsRejectedMessages(t *testing.T) {
+	target, mockDriver := createMockTarget(10)
+	target.Name = "mock"
+	router, _ := createSpillRouter(t, target)
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "fail-for-2"},
+		{Data: []byte("message2"), PartitionKey: "fail-for-3"},
+	}
+
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+
+	// The first replay only gets message1 through, message2 stays in the log
+	spillLog := router.spillLogs[target]
+	router.drainSpill(target, spillLog, make(chan struct{}))
+
+	segment, err := spillLog.Oldest()
+	assert.Nil(t, err)
+	if assert.NotNil(t, segment) && assert.Equal(t, 1, len(segment.Messages)) {
+		assert.Equal(t, "message2", string(segment.Messages[0].Data))
+	}
+
+	// The next replay gets message2 through
+	router.drainSpill(target, spillLog, make(chan struct{}))
+	assert.Zero(t, spillLog.Size())
+
+	receivedBatches := mockDriver.GetReceivedBatches()
+	last := receivedBatches[len(receivedBatches)-1]
+	if assert.Equal(t, 1, len(last)) {
+		assert.Equal(t, "message2", string(last[0].Data))
+	}
+}
+
+func TestWriteBatch_SpillSkipsFatalErrors(t *testing.T) {
+	target, _ := createMockTarget(10)
+	target.Name = "mock"
+	router, wasCancelCalled := createSpillRouter(t, target)
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "fatal"},
+	}
+	_, nackedMessages, mu := addAckNackTracking(testMessages)
+
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+
+	assert.True(t, wasCancelCalled())
+	assert.Zero(t, router.spillLogs[target].Size())
+	mu.Lock()
+	assert.True(t, nackedMessages["message1"])
+	mu.Unlock()
+}
+
+func TestWriteBatch_SpillFull(t *testing.T) {
+	target, _ := createMockTarget(10)
+	target.Name = "mock"
+	router, wasCancelCalled := createSpillRouter(t, target)
+
+	spillLog, err := spill.New(t.TempDir(), 10, "")
+	if err != nil {
+		t.Fatalf("failed to create spill log: %s", err)
+	}
+	router.spillLogs[target] = spillLog
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "failed"},
+	}
+
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+
+	// Once the log is full, failures are unrecoverable again
+	assert.True(t, wasCancelCalled())
+}
+
+func TestWriteBatch_SpillSkipsFilterTargetWithSameName(t *testing.T) {
+	target, _ := createMockTarget(10)
+	target.Name = "mock"
+	filterTarget, _ := createMockTarget(10)
+	filterTarget.Name = "mock"
+	router, wasCancelCalled := createSpillRouter(t, target)
+	router.FilterTarget = filterTarget
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "failed"},
+	}
+
+	router.WriteBatch(testMessages, filterTarget, func(*models.TargetWriteResult) {})
+	filterTarget.WaitGroup.Wait()
+
+	// Filtered messages are never spilled into the log of the good target, which would replay them into it
+	assert.True(t, wasCancelCalled())
+	assert.Zero(t, router.spillLogs[target].Size())
+}
+
+func TestSpillDirName(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("kafka-output_1.v2", spillDirName("kafka-output_1.v2"))
+
+	// Names which could escape the spill path or clash with another are given a directory of their own
+	for _, name := range []string{"../escape", "a/b", "a_b/", "..", ".", ""} {
+		dir := spillDirName(name)
+		assert.NotContains(dir, "/", name)
+		assert.NotEqual("..", dir, name)
+		assert.NotEqual(".", dir, name)
+		assert.NotEmpty(dir, name)
+	}
+	assert.NotEqual(spillDirName("a/b"), spillDirName("a_b"))
+	assert.NotEqual(spillDirName("a/b"), spillDirName("a:b"))
+}
+
+func TestDrainSpill_SkipsOpenBreaker(t *testing.T) {
+	target, mockDriver := createMockTarget(10)
+	target.Name = "mock"
+	router, _ := createSpillRouter(t, target)
+
+	testMessages := []*models.Message{{Data: []byte("message1"), PartitionKey: "fail-for-2"}}
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+	writes := len(mockDriver.GetReceivedBatches())
+
+	// A breaker open for longer than the test would block the replay until it lets writes through again
+	target.Breaker = targetiface.NewCircuitBreaker(targetiface.CircuitBreakerConfig{FailureRate: 1, MinRequests: 1, Window: time.Hour, OpenDuration: time.Hour}, nil)
+	target.Breaker.Record(false, false)
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		router.drainSpill(target, router.spillLogs[target], make(chan struct{}))
+	}()
+	select {
+	case <-drained:
+	case <-time.After(5 * time.Second):
+		t.Fatal("replay blocked on an open breaker")
+	}
+
+	assert.Len(t, mockDriver.GetReceivedBatches(), writes)
+	assert.Positive(t, router.spillLogs[target].Size())
+}
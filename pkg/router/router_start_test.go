@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"errors"
@@ -61,7 +61,7 @@ func TestRouter_Start_GracefulShutdownWithPartialBatches(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		FailureTarget:        failureTarget,
 		FailureParser:        failureParser,
@@ -203,7 +203,7 @@ func TestRouter_Start_GracefulShutdownWithInFlightWrites(t *testing.T) {
 		transformationOutput: transformationOutput,
 		invalidChannel:       invalidChannel,
 		cancel:               mockCancel,
-		Target:               target,
+		Targets:              []*targetiface.Target{target},
 		FilterTarget:         filterTarget,
 		FailureTarget:        failureTarget,
 		FailureParser:        failureParser,
@@ -298,3 +298,49 @@ func TestRouter_Start_GracefulShutdownWithInFlightWrites(t *testing.T) {
 	_, ok := <-invalidChannel
 	assert.False(t, ok, "invalidChannel should be closed after shutdown")
 }
+
+func TestRouter_HealthState(t *testing.T) {
+	assert := assert.New(t)
+
+	target, _ := createMockTarget(10)
+	filterTarget, _ := createMockTarget(10)
+	failureTarget, _ := createMockTarget(10)
+	failureParser, err := failure.NewEventForwardingFailure(1000000, "test", "0.1.0")
+	if err != nil {
+		t.Fatalf("Failed to create failure parser: %v", err)
+	}
+
+	transformationOutput := make(chan *models.TransformationResult, 10)
+	mockCancel, _ := createMockCancel()
+	router := &Router{
+		transformationOutput: transformationOutput,
+		invalidChannel:       make(chan *invalidMessages, 10),
+		cancel:               mockCancel,
+		Targets:              []*targetiface.Target{target},
+		FilterTarget:         filterTarget,
+		FailureTarget:        failureTarget,
+		FailureParser:        failureParser,
+		maxTargetSize:        1000000,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 100, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 100, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 100, MaxAttempts: 1},
+		},
+		metrics: createMockMetrics(),
+	}
+
+	assert.EqualError(router.TargetsOpen(), "targets are not open yet")
+	assert.True(router.LastActivity().IsZero())
+
+	var startWg sync.WaitGroup
+	startWg.Go(router.Start)
+
+	assert.Eventually(func() bool { return router.TargetsOpen() == nil }, time.Second, 10*time.Millisecond)
+
+	before := time.Now()
+	transformationOutput <- models.NewTransformationResult(nil, &models.Message{Data: []byte("filtered"), PartitionKey: "success"}, nil)
+	assert.Eventually(func() bool { return !router.LastActivity().Before(before) }, time.Second, 10*time.Millisecond)
+
+	close(transformationOutput)
+	startWg.Wait()
+}
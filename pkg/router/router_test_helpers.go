@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"errors"
@@ -353,6 +353,12 @@ func (m *mockMetrics) TargetWriteFiltered(r *models.TargetWriteResult) {
 	m.filteredCount.Add(int32(len(r.Sent)))
 }
 
+func (m *mockMetrics) TargetBreakerState(targetName string, state int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writeBuffer.AppendBreakerState(targetName, state)
+}
+
 func (m *mockMetrics) GetTargetWriteCount() int {
 	return int(m.targetWriteCount.Load())
 }
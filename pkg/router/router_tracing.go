@@ -0,0 +1,51 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/tracing"
+)
+
+// tracingMetrics records the trace of every message which settles before passing the result on to the metrics.
+// Failed messages are retried, and invalid ones are passed on to the failure target,
+// so they are traced once they are finally sent to a target.
+type tracingMetrics struct {
+	RouterMetrics
+	tracer *tracing.Tracer
+}
+
+// WithTracing wraps the router metrics with the tracer, if tracing is enabled
+func WithTracing(metrics RouterMetrics, tracer *tracing.Tracer) RouterMetrics {
+	if tracer == nil {
+		return metrics
+	}
+	return &tracingMetrics{RouterMetrics: metrics, tracer: tracer}
+}
+
+// TargetWrite traces the messages sent to a good target
+func (m *tracingMetrics) TargetWrite(r *models.TargetWriteResult) {
+	m.tracer.Record(r.TargetName, tracing.OutcomeSent, r.Sent)
+	m.RouterMetrics.TargetWrite(r)
+}
+
+// TargetWriteInvalid traces the messages written to the failure target
+func (m *tracingMetrics) TargetWriteInvalid(r *models.TargetWriteResult) {
+	m.tracer.Record(r.TargetName, tracing.OutcomeInvalid, r.Sent)
+	m.RouterMetrics.TargetWriteInvalid(r)
+}
+
+// TargetWriteFiltered traces the messages written to the filter target
+func (m *tracingMetrics) TargetWriteFiltered(r *models.TargetWriteResult) {
+	m.tracer.Record(r.TargetName, tracing.OutcomeFiltered, r.Sent)
+	m.RouterMetrics.TargetWriteFiltered(r)
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/tracing"
+)
+
+// recordingMetrics counts the results passed to it
+type recordingMetrics struct {
+	written, invalid, filtered int
+}
+
+func (m *recordingMetrics) TargetWrite(r *models.TargetWriteResult)           { m.written++ }
+func (m *recordingMetrics) TargetWriteInvalid(r *models.TargetWriteResult)    { m.invalid++ }
+func (m *recordingMetrics) TargetWriteFiltered(r *models.TargetWriteResult)   { m.filtered++ }
+func (m *recordingMetrics) TargetBreakerState(targetName string, state int64) {}
+
+// keptSpansExporter keeps the exported spans after shutdown, so that stopping the tracer flushes them for the test to read
+type keptSpansExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (e keptSpansExporter) Shutdown(ctx context.Context) error { return nil }
+
+func TestWithTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	inner := &recordingMetrics{}
+	assert.Same(inner, WithTracing(inner, nil))
+
+	exporter := keptSpansExporter{tracetest.NewInMemoryExporter()}
+	tracer := tracing.NewTracer(exporter, "snowbridge", "0.0.0", 1)
+	metrics := WithTracing(inner, tracer)
+
+	msg := func() *models.Message { return &models.Message{TimePulled: time.Now().UTC()} }
+	metrics.TargetWrite(&models.TargetWriteResult{TargetName: "primary", Sent: []*models.Message{msg()}, Failed: []*models.Message{msg()}, Invalid: []*models.Message{msg()}})
+	metrics.TargetWriteInvalid(&models.TargetWriteResult{Sent: []*models.Message{msg()}})
+	metrics.TargetWriteFiltered(&models.TargetWriteResult{TargetName: "filter", Sent: []*models.Message{msg()}})
+
+	assert.Equal(&recordingMetrics{written: 1, invalid: 1, filtered: 1}, inner)
+	assert.Nil(tracer.Stop())
+
+	// Only messages which settled on a target are traced, failed and invalid ones are traced once they do
+	var outcomes []string
+	for _, s := range exporter.GetSpans() {
+		if s.Name != "message" {
+			continue
+		}
+		for _, a := range s.Attributes {
+			if a.Key == "snowbridge.outcome" {
+				outcomes = append(outcomes, a.Value.AsString())
+			}
+		}
+	}
+	assert.ElementsMatch([]string{tracing.OutcomeSent, tracing.OutcomeInvalid, tracing.OutcomeFiltered}, outcomes)
+}
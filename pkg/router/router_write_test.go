@@ -9,7 +9,7 @@
  * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
  */
 
-package cli
+package router
 
 import (
 	"testing"
@@ -19,6 +19,7 @@ import (
 
 	"github.com/snowplow/snowbridge/v5/config"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 )
 
 func TestWriteBatch_Basic(t *testing.T) {
@@ -29,7 +30,7 @@ func TestWriteBatch_Basic(t *testing.T) {
 	mockCancel, _ := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 10),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -104,7 +105,7 @@ func TestWriteBatch_FatalError(t *testing.T) {
 	mockCancel, wasCancelCalled := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 10),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -164,7 +165,7 @@ func TestWriteBatch_FatalWriteError_Transient(t *testing.T) {
 	mockCancel, wasCancelCalled := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 1),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -207,7 +208,7 @@ func TestWriteBatch_Retry(t *testing.T) {
 	mockCancel, _ := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 10),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -303,7 +304,7 @@ func TestWriteBatch_RetryExhausted(t *testing.T) {
 	mockCancel, wasCancelCalled := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 10),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -365,7 +366,7 @@ func TestWriteBatch_RetryExhausted_InvalidAfterMax(t *testing.T) {
 	mockCancel, wasCancelCalled := createMockCancel()
 
 	router := &Router{
-		Target:         target,
+		Targets:        []*targetiface.Target{target},
 		invalidChannel: make(chan *invalidMessages, 10),
 		cancel:         mockCancel,
 		retryConfig: &config.RetryConfig{
@@ -428,3 +429,75 @@ func TestWriteBatch_RetryExhausted_InvalidAfterMax(t *testing.T) {
 	assert.False(t, ackedMessages["message1"], "Message should not be acked when sent to invalid")
 	assert.False(t, nackedMessages["message1"], "Message should not be nacked when InvalidAfterMax is true")
 }
+
+func TestWriteBatch_CircuitBreaker(t *testing.T) {
+	target, mockDriver := createMockTarget(10)
+	target.Name = "mock"
+	mockCancel, wasCancelCalled := createMockCancel()
+	metrics := createMockMetrics()
+	alertChan := make(chan error, 10)
+
+	router := &Router{
+		Targets:        []*targetiface.Target{target},
+		invalidChannel: make(chan *invalidMessages, 10),
+		AlertChannel:   alertChan,
+		cancel:         mockCancel,
+		retryConfig: &config.RetryConfig{
+			Setup:     &config.SetupRetryConfig{Delay: 10, MaxAttempts: 1},
+			Transient: &config.TransientRetryConfig{Delay: 10, MaxAttempts: 1},
+			Throttle:  &config.ThrottleRetryConfig{Delay: 10, MaxAttempts: 1},
+		},
+		metrics: metrics,
+	}
+	err := router.enableCircuitBreakers(&config.CircuitBreakerConfig{
+		Enabled:        true,
+		FailureRate:    1,
+		MinRequests:    1,
+		WindowMs:       60000,
+		OpenDurationMs: 200,
+	})
+	if err != nil {
+		t.Fatalf("failed to enable circuit breakers: %s", err)
+	}
+
+	testMessages := []*models.Message{
+		{Data: []byte("message1"), PartitionKey: "fail-for-1"},
+	}
+	ackedMessages, _, mu := addAckNackTracking(testMessages)
+
+	start := time.Now()
+	router.WriteBatch(testMessages, target, func(*models.TargetWriteResult) {})
+	target.WaitGroup.Wait()
+
+	// The first failure opens the breaker, so the retry waits for it to let a probe through
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	assert.Equal(t, 2, len(mockDriver.GetReceivedBatches()))
+	assert.Equal(t, targetiface.BreakerClosed, target.Breaker.State())
+	assert.False(t, wasCancelCalled())
+
+	mu.Lock()
+	assert.True(t, ackedMessages["message1"])
+	mu.Unlock()
+
+	// Transitions are reported to metrics and webhook monitoring
+	buffer := metrics.GetWriteBuffer()
+	assert.Equal(t, map[string]int64{"mock": int64(targetiface.BreakerClosed)}, buffer.BreakerStates)
+	assert.Equal(t, int64(3), buffer.Targets["mock"].BreakerStateChanges)
+
+	if assert.Equal(t, 2, len(alertChan)) {
+		assert.EqualError(t, <-alertChan, `circuit breaker opened for target "mock" after repeated write failures`)
+		assert.Nil(t, <-alertChan)
+	}
+}
+
+func TestEnableCircuitBreakers_InvalidFailureRate(t *testing.T) {
+	target, _ := createMockTarget(10)
+	router := &Router{Targets: []*targetiface.Target{target}}
+
+	err := router.enableCircuitBreakers(&config.CircuitBreakerConfig{Enabled: true, FailureRate: 1.5})
+	assert.EqualError(t, err, "invalid circuit_breaker failure_rate 1.5, must be greater than 0 and at most 1")
+	assert.Nil(t, target.Breaker)
+
+	// Disabled breakers are not validated
+	assert.Nil(t, router.enableCircuitBreakers(&config.CircuitBreakerConfig{FailureRate: 1.5}))
+}
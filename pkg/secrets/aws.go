@@ -0,0 +1,67 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+
+	"github.com/snowplow/snowbridge/v5/pkg/common"
+)
+
+// awsResolver reads secrets from AWS Secrets Manager.
+// Unless a client is given, one is created on first use from the default AWS configuration, such as `AWS_REGION`.
+type awsResolver struct {
+	mu     sync.Mutex
+	client common.SecretsManagerV2API
+}
+
+// NewAWSResolver returns a Resolver reading secrets from AWS Secrets Manager with client
+func NewAWSResolver(client common.SecretsManagerV2API) Resolver {
+	return &awsResolver{client: client}
+}
+
+// Resolve returns the string value of the current version of the secret with the given name or ARN
+func (r *awsResolver) Resolve(ctx context.Context, id string) (string, error) {
+	client, err := r.getClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(id)})
+	if err != nil {
+		return "", err
+	}
+	if res.SecretString == nil {
+		return "", errors.New("secret has no string value")
+	}
+	return *res.SecretString, nil
+}
+
+func (r *awsResolver) getClient(ctx context.Context) (common.SecretsManagerV2API, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client == nil {
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithDefaultsMode(aws.DefaultsModeAuto))
+		if err != nil {
+			return nil, err
+		}
+		r.client = secretsmanager.NewFromConfig(cfg)
+	}
+	return r.client, nil
+}
@@ -0,0 +1,50 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/testutil"
+)
+
+func TestAWSResolver(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	assert := assert.New(t)
+
+	client := testutil.GetAWSLocalstackSecretsManagerClient()
+	secretName := "snowbridge-test-secret"
+	_, err := testutil.CreateAWSLocalstackSecret(client, secretName, `{"client_secret":"aws-client-secret"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if _, err := testutil.DeleteAWSLocalstackSecret(client, secretName); err != nil {
+			t.Log(err)
+		}
+	}()
+
+	RegisterResolver(SchemeAWSSecretsManager, NewAWSResolver(client))
+	t.Cleanup(func() { RegisterResolver(SchemeAWSSecretsManager, &awsResolver{}) })
+
+	value, err := Resolve("aws-sm://" + secretName + "#client_secret")
+	assert.NoError(err)
+	assert.Equal("aws-client-secret", value)
+
+	_, err = Resolve("aws-sm://snowbridge-missing-secret")
+	assert.ErrorContains(err, `failed to resolve secret "aws-sm://snowbridge-missing-secret"`)
+}
@@ -0,0 +1,69 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+
+	"github.com/snowplow/snowbridge/v5/pkg/common/gcp"
+)
+
+// gcpSecretAccessor describes the methods of a GCP Secret Manager client used to read secrets
+type gcpSecretAccessor interface {
+	AccessSecretVersion(ctx context.Context, req *secretmanagerpb.AccessSecretVersionRequest, opts ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error)
+}
+
+// gcpResolver reads secrets from GCP Secret Manager.
+// Unless a client is given, one is created on first use with the application default credentials.
+type gcpResolver struct {
+	mu     sync.Mutex
+	client gcpSecretAccessor
+}
+
+// Resolve returns the payload of a secret version, or of the latest version when name is that of a secret
+func (r *gcpResolver) Resolve(ctx context.Context, name string) (string, error) {
+	client, err := r.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	res, err := client.AccessSecretVersion(ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	if err != nil {
+		return "", err
+	}
+	return string(res.GetPayload().GetData()), nil
+}
+
+func (r *gcpResolver) getClient() (gcpSecretAccessor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.client == nil {
+		// The client lives as long as the process, as secrets can be resolved again at any time
+		client, err := secretmanager.NewClient(context.Background(), option.WithUserAgent(gcp.UserAgent))
+		if err != nil {
+			return nil, err
+		}
+		r.client = client
+	}
+	return r.client, nil
+}
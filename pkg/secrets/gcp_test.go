@@ -0,0 +1,44 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/secretmanager/apiv1/secretmanagerpb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSecretAccessor returns the name of the secret version it is asked for as its payload
+type fakeSecretAccessor struct{}
+
+func (f *fakeSecretAccessor) AccessSecretVersion(_ context.Context, req *secretmanagerpb.AccessSecretVersionRequest, _ ...gax.CallOption) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    req.GetName(),
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(req.GetName())},
+	}, nil
+}
+
+func TestGCPResolver(t *testing.T) {
+	assert := assert.New(t)
+	resolver := &gcpResolver{client: &fakeSecretAccessor{}}
+
+	value, err := resolver.Resolve(context.Background(), "projects/test/secrets/password")
+	assert.NoError(err)
+	assert.Equal("projects/test/secrets/password/versions/latest", value)
+
+	value, err = resolver.Resolve(context.Background(), "projects/test/secrets/password/versions/3")
+	assert.NoError(err)
+	assert.Equal("projects/test/secrets/password/versions/3", value)
+}
@@ -0,0 +1,189 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// SchemeFile references a file, as in `file:///run/secrets/password`
+	SchemeFile = "file"
+
+	// SchemeAWSSecretsManager references an AWS Secrets Manager secret by name or ARN, as in `aws-sm://my-secret`
+	SchemeAWSSecretsManager = "aws-sm"
+
+	// SchemeGCPSecretManager references a GCP Secret Manager secret by resource name, as in `gcp-sm://projects/my-project/secrets/my-secret`.
+	// The latest version is used unless the name includes one.
+	SchemeGCPSecretManager = "gcp-sm"
+
+	// resolveTimeout bounds the time spent resolving a single reference
+	resolveTimeout = 30 * time.Second
+)
+
+// Resolver resolves the secrets referenced under a scheme
+type Resolver interface {
+	// Resolve returns the value of the secret referenced by id, which is the reference without its scheme
+	Resolve(ctx context.Context, id string) (string, error)
+}
+
+var (
+	mu sync.RWMutex
+
+	resolvers = map[string]Resolver{
+		SchemeFile:              &fileResolver{},
+		SchemeAWSSecretsManager: &awsResolver{},
+		SchemeGCPSecretManager:  &gcpResolver{},
+	}
+)
+
+// RegisterResolver sets the resolver used for references under scheme, replacing any existing one
+func RegisterResolver(scheme string, resolver Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	resolvers[scheme] = resolver
+}
+
+// Resolve returns the value of the secret referenced by ref, in the form `<scheme>://<id>`.
+// When ref ends with `#<key>` the secret must hold a JSON object, and the value of key in it is returned.
+// File references take the path verbatim, `#` included.
+// Errors never include the value of the secret.
+func Resolve(ref string) (string, error) {
+	resolver, id, key, err := parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	value, err := resolver.Resolve(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %q: %w", ref, err)
+	}
+
+	if key != "" {
+		if value, err = jsonKey(value, key); err != nil {
+			return "", fmt.Errorf("failed to resolve secret %q: %w", ref, err)
+		}
+	}
+	return value, nil
+}
+
+// Check reports whether ref is a well formed reference under a supported scheme, without resolving it
+func Check(ref string) error {
+	_, _, _, err := parse(ref)
+	return err
+}
+
+// parse splits a reference into the resolver of its scheme, the id of the secret, and the JSON key to look up if any
+func parse(ref string) (resolver Resolver, id string, key string, err error) {
+	scheme, id, ok := strings.Cut(ref, "://")
+	if !ok || id == "" {
+		return nil, "", "", fmt.Errorf("invalid secret reference %q, expected <scheme>://<id>", ref)
+	}
+
+	mu.RLock()
+	resolver, ok = resolvers[scheme]
+	mu.RUnlock()
+	if !ok {
+		return nil, "", "", fmt.Errorf("unsupported secret reference scheme %q in %q", scheme, ref)
+	}
+
+	if scheme != SchemeFile {
+		if i := strings.LastIndex(id, "#"); i >= 0 {
+			id, key = id[:i], id[i+1:]
+		}
+	}
+	return resolver, id, key, nil
+}
+
+// Tracker tracks the config fields set from a secret, for the config decoded along with it.
+// It holds the reference each field was resolved from, keyed by the address of the field.
+// The reference is empty when the secret is only part of the value of the field, which then can't be resolved again.
+// A nil Tracker tracks nothing.
+type Tracker struct {
+	mu     sync.RWMutex
+	fields map[any]string
+}
+
+// NewTracker returns a Tracker tracking no field yet
+func NewTracker() *Tracker {
+	return &Tracker{fields: make(map[any]string)}
+}
+
+// Refreshable is implemented by configs which resolve their secrets again once they are rotated.
+// The decoder hands them the Tracker of the config they are decoded from.
+type Refreshable interface {
+	SetSecretTracker(t *Tracker)
+}
+
+// Track records that the config field at the address field, such as a *string or a *map[string]string,
+// was set from a secret. ref is the reference the field was resolved from, when the secret is the whole value of the field.
+func (t *Tracker) Track(field any, ref string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fields[field] = ref
+}
+
+// IsTracked reports whether the config field at the address field was set from a secret
+func (t *Tracker) IsTracked(field any) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, ok := t.fields[field]
+	return ok
+}
+
+// Refresh resolves again the reference the config field at the address field was set from, to pick up a rotated secret.
+// The value of a field which wasn't set from a reference alone is returned unchanged.
+func (t *Tracker) Refresh(field *string) (string, error) {
+	if t == nil {
+		return *field, nil
+	}
+	t.mu.RLock()
+	ref := t.fields[field]
+	t.mu.RUnlock()
+	if ref == "" {
+		return *field, nil
+	}
+	return Resolve(ref)
+}
+
+// jsonKey returns the value of key in a secret holding a JSON object.
+// Parsing errors are not wrapped, as they may quote the secret.
+func jsonKey(secret string, key string) (string, error) {
+	var object map[string]any
+	if err := json.Unmarshal([]byte(secret), &object); err != nil {
+		return "", errors.New("secret is not a JSON object")
+	}
+
+	value, ok := object[key]
+	if !ok {
+		return "", fmt.Errorf("secret has no key %q", key)
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode key %q of secret", key)
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,152 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mapResolver resolves secrets from a map, standing in for a secret manager
+type mapResolver map[string]string
+
+func (r mapResolver) Resolve(_ context.Context, id string) (string, error) {
+	value, ok := r[id]
+	if !ok {
+		return "", errors.New("secret not found")
+	}
+	return value, nil
+}
+
+func TestResolve(t *testing.T) {
+	RegisterResolver("test", mapResolver{
+		"plain": "plain-value",
+		"json":  `{"password":"json-password","port":5432}`,
+		"bad":   "not-json-value",
+	})
+
+	testCases := []struct {
+		Name     string
+		Ref      string
+		Expected string
+		Error    string
+	}{
+		{Name: "plain", Ref: "test://plain", Expected: "plain-value"},
+		{Name: "JSON string key", Ref: "test://json#password", Expected: "json-password"},
+		{Name: "JSON number key", Ref: "test://json#port", Expected: "5432"},
+		{Name: "missing JSON key", Ref: "test://json#user", Error: `failed to resolve secret "test://json#user": secret has no key "user"`},
+		{Name: "not JSON", Ref: "test://bad#password", Error: `failed to resolve secret "test://bad#password": secret is not a JSON object`},
+		{Name: "missing secret", Ref: "test://missing", Error: `failed to resolve secret "test://missing": secret not found`},
+		{Name: "unknown scheme", Ref: "vault://plain", Error: `unsupported secret reference scheme "vault" in "vault://plain"`},
+		{Name: "no scheme", Ref: "plain", Error: `invalid secret reference "plain", expected <scheme>://<id>`},
+		{Name: "no id", Ref: "test://", Error: `invalid secret reference "test://", expected <scheme>://<id>`},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			value, err := Resolve(tt.Ref)
+			if tt.Error != "" {
+				assert.EqualError(err, tt.Error)
+				assert.NotContains(err.Error(), "-value")
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(tt.Expected, value)
+			assert.NoError(Check(tt.Ref))
+		})
+	}
+}
+
+func TestResolve_File(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "password")
+	assert.NoError(os.WriteFile(path, []byte("file-password\n"), 0600))
+
+	value, err := Resolve("file://" + path)
+	assert.NoError(err)
+	assert.Equal("file-password", value)
+
+	_, err = Resolve("file://" + path + ".missing")
+	assert.ErrorIs(err, os.ErrNotExist)
+
+	// A path is taken verbatim, it never names a JSON key
+	path = filepath.Join(t.TempDir(), "pass#word")
+	assert.NoError(os.WriteFile(path, []byte("hashed-password"), 0600))
+	value, err = Resolve("file://" + path)
+	assert.NoError(err)
+	assert.Equal("hashed-password", value)
+}
+
+func TestCheck(t *testing.T) {
+	assert := assert.New(t)
+
+	// References are checked without being resolved
+	assert.NoError(Check("aws-sm://my-secret#password"))
+	assert.NoError(Check("file:///run/secrets/missing"))
+	assert.EqualError(Check("vault://password"), `unsupported secret reference scheme "vault" in "vault://password"`)
+	assert.EqualError(Check("password"), `invalid secret reference "password", expected <scheme>://<id>`)
+}
+
+func TestRefresh(t *testing.T) {
+	assert := assert.New(t)
+
+	secrets := mapResolver{"rotated": "before-rotation"}
+	RegisterResolver("test", secrets)
+
+	var config struct {
+		Password string
+		Other    string
+		Composed string
+	}
+	value, err := Resolve("test://rotated")
+	assert.NoError(err)
+	config.Password = value
+	config.Other = value
+	config.Composed = "user:" + value
+	tracker := NewTracker()
+	tracker.Track(&config.Password, "test://rotated")
+	tracker.Track(&config.Composed, "")
+
+	// Fields are tracked by address, another field with the same value isn't
+	assert.True(tracker.IsTracked(&config.Password))
+	assert.True(tracker.IsTracked(&config.Composed))
+	assert.False(tracker.IsTracked(&config.Other))
+
+	// Fields are only tracked for the config they were decoded with
+	var other *Tracker
+	assert.False(NewTracker().IsTracked(&config.Password))
+	assert.False(other.IsTracked(&config.Password))
+
+	secrets["rotated"] = "after-rotation"
+	refreshed, err := tracker.Refresh(&config.Password)
+	assert.NoError(err)
+	assert.Equal("after-rotation", refreshed)
+
+	// Fields which don't hold a secret alone are left as they are
+	unchanged, err := tracker.Refresh(&config.Composed)
+	assert.NoError(err)
+	assert.Equal("user:before-rotation", unchanged)
+	unchanged, err = tracker.Refresh(&config.Other)
+	assert.NoError(err)
+	assert.Equal("before-rotation", unchanged)
+	unchanged, err = other.Refresh(&config.Password)
+	assert.NoError(err)
+	assert.Equal("before-rotation", unchanged)
+}
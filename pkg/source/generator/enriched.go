@@ -0,0 +1,188 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package generatorsource
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Indexes of the fields of an enriched event which are generated, the others are left empty
+const (
+	enrichedFieldCount = 131
+
+	fieldAppID             = 0
+	fieldPlatform          = 1
+	fieldEtlTstamp         = 2
+	fieldCollectorTstamp   = 3
+	fieldDvceCreatedTstamp = 4
+	fieldEvent             = 5
+	fieldEventID           = 6
+	fieldNameTracker       = 8
+	fieldVTracker          = 9
+	fieldVCollector        = 10
+	fieldVEtl              = 11
+	fieldUserIPAddress     = 13
+	fieldDomainUserID      = 15
+	fieldDomainSessionIdx  = 16
+	fieldNetworkUserID     = 17
+	fieldContexts          = 52
+	fieldUnstructEvent     = 58
+	fieldDvceSentTstamp    = 119
+	fieldDomainSessionID   = 123
+	fieldDerivedTstamp     = 124
+	fieldEventVendor       = 125
+	fieldEventName         = 126
+	fieldEventFormat       = 127
+	fieldEventVersion      = 128
+)
+
+const (
+	enrichedTimestampLayout = "2006-01-02 15:04:05.000"
+	contextsSchema          = "iglu:com.snowplowanalytics.snowplow/contexts/jsonschema/1-0-0"
+	unstructEventSchema     = "iglu:com.snowplowanalytics.snowplow/unstruct_event/jsonschema/1-0-0"
+)
+
+// enrichedGenerator builds enriched events with a random app ID, a random subset of the contexts, and a random unstruct event.
+// Events are page views when there is no unstruct event to pick from.
+type enrichedGenerator struct {
+	random         *random
+	appIDs         []string
+	contexts       []*payloadTemplate
+	unstructEvents []*payloadTemplate
+}
+
+func newEnrichedGenerator(r *random, appIDs, contexts, unstructEvents []string) (*enrichedGenerator, error) {
+	if len(appIDs) == 0 {
+		return nil, errors.New("format 'enriched' requires at least one app ID")
+	}
+
+	g := &enrichedGenerator{random: r, appIDs: appIDs}
+	for i, text := range contexts {
+		tmpl, err := r.parseSelfDescribing(fmt.Sprintf("contexts[%d]", i), text)
+		if err != nil {
+			return nil, err
+		}
+		g.contexts = append(g.contexts, tmpl)
+	}
+	for i, text := range unstructEvents {
+		tmpl, err := r.parseSelfDescribing(fmt.Sprintf("unstruct_events[%d]", i), text)
+		if err != nil {
+			return nil, err
+		}
+		g.unstructEvents = append(g.unstructEvents, tmpl)
+	}
+	return g, nil
+}
+
+// parseSelfDescribing parses a template, and fails on startup unless it renders self-describing JSON
+func (r *random) parseSelfDescribing(name, text string) (*payloadTemplate, error) {
+	tmpl, err := r.parseTemplate(name, text)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := tmpl.renderSelfDescribing(); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// selfDescribing is the envelope of a context or an unstruct event
+type selfDescribing struct {
+	Schema string          `json:"schema"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// renderSelfDescribing renders self-describing JSON, returned along with the parts of its schema URI
+func (t *payloadTemplate) renderSelfDescribing() (string, []string, error) {
+	rendered, err := t.renderJSON()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sd selfDescribing
+	if err := json.Unmarshal([]byte(rendered), &sd); err != nil || len(sd.Data) == 0 {
+		return "", nil, fmt.Errorf("%s does not render self-describing JSON, with a schema and data", t.name)
+	}
+	// A schema URI is iglu:vendor/name/format/version
+	schema := strings.Split(strings.TrimPrefix(sd.Schema, "iglu:"), "/")
+	if !strings.HasPrefix(sd.Schema, "iglu:") || len(schema) != 4 {
+		return "", nil, fmt.Errorf("%s has an invalid schema URI %q, expected iglu:vendor/name/format/version", t.name, sd.Schema)
+	}
+	return rendered, schema, nil
+}
+
+// next returns an enriched event in TSV
+func (g *enrichedGenerator) next() (string, error) {
+	r := g.random
+	now := time.Now().UTC()
+	tstamp := now.Format(enrichedTimestampLayout)
+
+	fields := make([]string, enrichedFieldCount)
+	fields[fieldAppID] = g.appIDs[r.Intn(len(g.appIDs))]
+	fields[fieldPlatform] = "web"
+	fields[fieldEtlTstamp] = tstamp
+	fields[fieldCollectorTstamp] = tstamp
+	fields[fieldDvceCreatedTstamp] = tstamp
+	fields[fieldDvceSentTstamp] = tstamp
+	fields[fieldDerivedTstamp] = tstamp
+	fields[fieldEventID] = r.uuid()
+	fields[fieldNameTracker] = SupportedSourceGenerator
+	fields[fieldVTracker] = "snowbridge-generator"
+	fields[fieldVCollector] = SupportedSourceGenerator
+	fields[fieldVEtl] = SupportedSourceGenerator
+	fields[fieldUserIPAddress] = fmt.Sprintf("10.%d.%d.%d", r.Intn(256), r.Intn(256), r.Intn(256))
+	fields[fieldDomainUserID] = r.uuid()
+	fields[fieldDomainSessionIdx] = fmt.Sprint(1 + r.Intn(20))
+	fields[fieldDomainSessionID] = r.uuid()
+	fields[fieldNetworkUserID] = r.uuid()
+
+	var contexts []string
+	for _, tmpl := range g.contexts {
+		if r.Intn(2) == 0 {
+			continue
+		}
+		context, _, err := tmpl.renderSelfDescribing()
+		if err != nil {
+			return "", err
+		}
+		contexts = append(contexts, context)
+	}
+	if len(contexts) > 0 {
+		fields[fieldContexts] = fmt.Sprintf(`{"schema":"%s","data":[%s]}`, contextsSchema, strings.Join(contexts, ","))
+	}
+
+	if len(g.unstructEvents) == 0 {
+		fields[fieldEvent] = "page_view"
+		setEventSchema(fields, []string{"com.snowplowanalytics.snowplow", "page_view", "jsonschema", "1-0-0"})
+		return strings.Join(fields, "\t"), nil
+	}
+
+	event, schema, err := g.unstructEvents[r.Intn(len(g.unstructEvents))].renderSelfDescribing()
+	if err != nil {
+		return "", err
+	}
+	fields[fieldEvent] = "unstruct"
+	fields[fieldUnstructEvent] = fmt.Sprintf(`{"schema":"%s","data":%s}`, unstructEventSchema, event)
+	setEventSchema(fields, schema)
+	return strings.Join(fields, "\t"), nil
+}
+
+func setEventSchema(fields []string, schema []string) {
+	fields[fieldEventVendor] = schema[0]
+	fields[fieldEventName] = schema[1]
+	fields[fieldEventFormat] = schema[2]
+	fields[fieldEventVersion] = schema[3]
+}
@@ -0,0 +1,300 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package generatorsource
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+)
+
+const SupportedSourceGenerator = "generator"
+
+const (
+	// FormatEnriched generates Snowplow enriched events in TSV
+	FormatEnriched = "enriched"
+	// FormatJSON generates JSON rendered from a template
+	FormatJSON = "json"
+	// FormatFile replays the lines of a sample file
+	FormatFile = "file"
+)
+
+// Configuration configures the synthetic messages to generate
+type Configuration struct {
+	Format string `hcl:"format,optional"`
+
+	// MessagesPerSecond is the requested throughput, messages are generated as fast as they are consumed when 0
+	MessagesPerSecond float64 `hcl:"messages_per_second,optional"`
+	// Count is the number of messages after which the source stops, it never stops when 0
+	Count int `hcl:"count,optional"`
+	// Seed makes the generated payloads reproducible, they differ on every run when 0
+	Seed              int64 `hcl:"seed,optional"`
+	ReportIntervalSec int   `hcl:"report_interval_sec,optional"`
+
+	// AppIDs, Contexts and UnstructEvents are picked from at random for every enriched event
+	AppIDs         []string `hcl:"app_ids,optional"`
+	Contexts       []string `hcl:"contexts,optional"`
+	UnstructEvents []string `hcl:"unstruct_events,optional"`
+
+	JSONTemplate string `hcl:"json_template,optional"`
+
+	File string `hcl:"file,optional"`
+}
+
+// DefaultConfiguration returns the default configuration for generator source
+func DefaultConfiguration() Configuration {
+	return Configuration{
+		Format:            FormatEnriched,
+		MessagesPerSecond: 1000,
+		ReportIntervalSec: 10,
+		AppIDs:            []string{"generator"},
+		Contexts: []string{
+			`{"schema":"iglu:com.snowplowanalytics.snowplow/web_page/jsonschema/1-0-0","data":{"id":"{{ uuid }}"}}`,
+			`{"schema":"iglu:com.acme/device/jsonschema/1-0-0","data":{"model":"{{ randChoice "phone" "tablet" "desktop" }}","screenWidth":{{ randInt 320 2560 }}}}`,
+		},
+		UnstructEvents: []string{
+			`{"schema":"iglu:com.snowplowanalytics.snowplow/link_click/jsonschema/1-0-1","data":{"targetUrl":"https://example.com/{{ randString 8 }}"}}`,
+			`{"schema":"iglu:com.acme/purchase/jsonschema/1-0-0","data":{"orderId":"{{ uuid }}","total":{{ randInt 1 500 }}}}`,
+		},
+		JSONTemplate: `{"id":"{{ uuid }}","createdAt":"{{ now }}","value":{{ randInt 0 1000 }}}`,
+	}
+}
+
+// BuildFromConfig creates a generator source from decoded configuration
+func BuildFromConfig(cfg *Configuration) (sourceiface.Source, error) {
+	if cfg.MessagesPerSecond < 0 {
+		return nil, fmt.Errorf("invalid messages_per_second %v, must not be negative", cfg.MessagesPerSecond)
+	}
+	if cfg.Count < 0 {
+		return nil, fmt.Errorf("invalid count %d, must not be negative", cfg.Count)
+	}
+	if cfg.ReportIntervalSec <= 0 {
+		return nil, fmt.Errorf("invalid report_interval_sec %d, must be greater than 0", cfg.ReportIntervalSec)
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := newRandom(seed)
+
+	var payload func() (string, error)
+	switch cfg.Format {
+	case FormatEnriched:
+		enriched, err := newEnrichedGenerator(r, cfg.AppIDs, cfg.Contexts, cfg.UnstructEvents)
+		if err != nil {
+			return nil, err
+		}
+		payload = enriched.next
+	case FormatJSON:
+		tmpl, err := r.parseTemplate("json_template", cfg.JSONTemplate)
+		if err != nil {
+			return nil, err
+		}
+		// Fail on startup rather than on the first message when the template does not render valid JSON
+		if _, err := tmpl.renderJSON(); err != nil {
+			return nil, err
+		}
+		payload = tmpl.renderJSON
+	case FormatFile:
+		lines, err := readLines(cfg.File)
+		if err != nil {
+			return nil, err
+		}
+		next := 0
+		payload = func() (string, error) {
+			line := lines[next]
+			next = (next + 1) % len(lines)
+			return line, nil
+		}
+	default:
+		return nil, fmt.Errorf("invalid format %q, expected one of '%s', '%s', '%s'", cfg.Format, FormatEnriched, FormatJSON, FormatFile)
+	}
+
+	return &generatorSourceDriver{
+		payload:           payload,
+		random:            r,
+		messagesPerSecond: cfg.MessagesPerSecond,
+		count:             cfg.Count,
+		reportInterval:    time.Duration(cfg.ReportIntervalSec) * time.Second,
+		log:               log.WithFields(log.Fields{"source": SupportedSourceGenerator, "format": cfg.Format}),
+	}, nil
+}
+
+// readLines returns the non-empty lines of a sample file
+func readLines(path string) ([]string, error) {
+	if path == "" {
+		return nil, errors.New("format 'file' requires a file to replay")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file to replay")
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to read file to replay")
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("file to replay %q has no lines", path)
+	}
+	return lines, nil
+}
+
+// generatorSourceDriver emits synthetic messages at a requested rate
+type generatorSourceDriver struct {
+	sourceiface.SourceChannels
+
+	payload           func() (string, error)
+	random            *random
+	messagesPerSecond float64
+	count             int
+	reportInterval    time.Duration
+
+	emitted atomic.Int64
+	log     *log.Entry
+}
+
+// Start will generate messages until the count is reached or context is cancelled
+func (gs *generatorSourceDriver) Start(ctx context.Context) {
+	defer close(gs.MessageChannel)
+	gs.log.Infof("Generating messages at %s...", gs.requested())
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	reportCtx, stopReporting := context.WithCancel(ctx)
+	wg.Go(func() { gs.reportThroughput(reportCtx, started) })
+	defer func() {
+		stopReporting()
+		wg.Wait()
+		gs.logThroughput(gs.emitted.Load(), time.Since(started), "in total")
+	}()
+
+	// Messages are scheduled from scheduleStart, which moves forward when the generator falls behind
+	scheduleStart, scheduled := started, 0
+	for sent := 0; gs.count == 0 || sent < gs.count; sent, scheduled = sent+1, scheduled+1 {
+		if gs.messagesPerSecond > 0 {
+			// Wait until the message is due, so that the rate holds on average however fine grained the timer is
+			due := scheduleStart.Add(time.Duration(float64(scheduled) / gs.messagesPerSecond * float64(time.Second)))
+
+			// After a stall, such as downstream backpressure, the messages which were due are not caught up on in a burst:
+			// the schedule starts over once it is more than a report interval behind
+			if time.Since(due) > gs.reportInterval {
+				scheduleStart, scheduled = time.Now(), 0
+				due = scheduleStart
+			}
+			if wait := time.Until(due); wait > 0 {
+				select {
+				case <-ctx.Done():
+					gs.log.Info("Context cancelled, stopping generator source")
+					return
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		data, err := gs.payload()
+		if err != nil {
+			gs.log.WithError(err).Error("Failed to generate message")
+			return
+		}
+		timeNow := time.Now().UTC()
+		message := &models.Message{
+			Data:         []byte(data),
+			PartitionKey: gs.random.uuid(),
+			TimeCreated:  timeNow,
+			TimePulled:   timeNow,
+		}
+
+		select {
+		case <-ctx.Done():
+			gs.log.Info("Context cancelled, stopping generator source")
+			return
+		case gs.MessageChannel <- message:
+			gs.emitted.Add(1)
+		}
+	}
+	gs.log.Infof("Generated %d messages, stopping generator source", gs.count)
+}
+
+// reportThroughput logs the achieved throughput of every report interval, against the requested one
+func (gs *generatorSourceDriver) reportThroughput(ctx context.Context, started time.Time) {
+	ticker := time.NewTicker(gs.reportInterval)
+	defer ticker.Stop()
+
+	previous := int64(0)
+	periodStart := started
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			emitted := gs.emitted.Load()
+			gs.logThroughput(emitted-previous, now.Sub(periodStart), "over the last interval")
+			previous = emitted
+			periodStart = now
+		}
+	}
+}
+
+func (gs *generatorSourceDriver) logThroughput(emitted int64, elapsed time.Duration, period string) {
+	achieved := 0.0
+	if elapsed > 0 {
+		achieved = float64(emitted) / elapsed.Seconds()
+	}
+	gs.log.WithFields(log.Fields{
+		"emitted":                       emitted,
+		"achieved_messages_per_second":  achieved,
+		"requested_messages_per_second": gs.messagesPerSecond,
+	}).Infof("Generated %d messages %s: %.1f msg/s achieved, %s requested", emitted, period, achieved, gs.requested())
+}
+
+func (gs *generatorSourceDriver) requested() string {
+	if gs.messagesPerSecond == 0 {
+		return "unlimited msg/s"
+	}
+	return fmt.Sprintf("%.1f msg/s", gs.messagesPerSecond)
+}
+
+// random draws the values of the generated payloads, from a seeded source so that they can be reproduced
+type random struct {
+	*rand.Rand
+}
+
+func newRandom(seed int64) *random {
+	return &random{rand.New(rand.NewSource(seed))}
+}
+
+func (r *random) uuid() string {
+	// Reading from a math/rand source never fails
+	return uuid.Must(uuid.NewRandomFromReader(r)).String()
+}
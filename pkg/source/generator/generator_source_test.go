@@ -0,0 +1,290 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package generatorsource
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/snowplow/snowplow-golang-analytics-sdk/analytics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/testutil"
+)
+
+// generate runs a source built from cfg until it has generated every message
+func generate(t *testing.T, cfg Configuration) []*models.Message {
+	t.Helper()
+
+	source, err := BuildFromConfig(&cfg)
+	require.NoError(t, err)
+
+	output := make(chan *models.Message, cfg.Count)
+	source.SetChannels(output)
+	source.Start(context.Background())
+
+	messages := testutil.ReadSourceOutput(output)
+	require.Len(t, messages, cfg.Count)
+	return messages
+}
+
+func TestGeneratorSource_Enriched(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := DefaultConfiguration()
+	cfg.MessagesPerSecond = 0
+	cfg.Count = 50
+	cfg.AppIDs = []string{"app-1", "app-2"}
+
+	appIDs := map[string]bool{}
+	events := map[string]bool{}
+	withContexts := 0
+	for _, message := range generate(t, cfg) {
+		event, err := analytics.ParseEvent(string(message.Data))
+		require.NoError(t, err)
+
+		// The event must be valid enough to be turned into JSON, as the enriched transformations do
+		_, err = event.ToJson()
+		assert.NoError(err)
+
+		appID, err := event.GetValue("app_id")
+		assert.NoError(err)
+		appIDs[appID.(string)] = true
+
+		name, err := event.GetValue("event_name")
+		assert.NoError(err)
+		events[name.(string)] = true
+
+		if contexts, err := event.GetValue("contexts"); err == nil && contexts != nil {
+			withContexts++
+		}
+		assert.False(message.TimePulled.IsZero())
+	}
+
+	assert.Equal(map[string]bool{"app-1": true, "app-2": true}, appIDs)
+	assert.Equal(map[string]bool{"link_click": true, "purchase": true}, events)
+	assert.Positive(withContexts)
+}
+
+func TestGeneratorSource_EnrichedPageViews(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.Count = 5
+	cfg.MessagesPerSecond = 0
+	cfg.Contexts = nil
+	cfg.UnstructEvents = nil
+
+	for _, message := range generate(t, cfg) {
+		event, err := analytics.ParseEvent(string(message.Data))
+		require.NoError(t, err)
+
+		name, err := event.GetValue("event")
+		assert.NoError(t, err)
+		assert.Equal(t, "page_view", name)
+	}
+}
+
+func TestGeneratorSource_JSON(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.Format = FormatJSON
+	cfg.Count = 10
+	cfg.MessagesPerSecond = 0
+	cfg.JSONTemplate = `{
+	  "user": "{{ randChoice "alice" "bob" }}",
+	  "score": {{ randInt 1 3 }}
+	}`
+
+	for _, message := range generate(t, cfg) {
+		var payload struct {
+			User  string `json:"user"`
+			Score int    `json:"score"`
+		}
+		assert.NoError(t, json.Unmarshal(message.Data, &payload))
+		assert.Contains(t, []string{"alice", "bob"}, payload.User)
+		assert.Contains(t, []int{1, 2, 3}, payload.Score)
+		assert.NotContains(t, string(message.Data), "\n")
+	}
+}
+
+func TestGeneratorSource_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.txt")
+	require.NoError(t, os.WriteFile(path, []byte("first\n\nsecond\nthird\n"), 0o600))
+
+	cfg := DefaultConfiguration()
+	cfg.Format = FormatFile
+	cfg.File = path
+	cfg.Count = 7
+	cfg.MessagesPerSecond = 0
+
+	var data []string
+	for _, message := range generate(t, cfg) {
+		data = append(data, string(message.Data))
+	}
+	assert.Equal(t, []string{"first", "second", "third", "first", "second", "third", "first"}, data)
+}
+
+func TestGeneratorSource_Seed(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.Format = FormatJSON
+	cfg.JSONTemplate = `{"id":"{{ uuid }}","value":{{ randInt 0 1000000 }}}`
+	cfg.Count = 5
+	cfg.MessagesPerSecond = 0
+	cfg.Seed = 42
+
+	first := generate(t, cfg)
+	second := generate(t, cfg)
+	for i := range first {
+		assert.Equal(t, string(first[i].Data), string(second[i].Data))
+		assert.Equal(t, first[i].PartitionKey, second[i].PartitionKey)
+	}
+
+	cfg.Seed = 43
+	third := generate(t, cfg)
+	assert.NotEqual(t, string(first[0].Data), string(third[0].Data))
+}
+
+func TestGeneratorSource_Rate(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.Format = FormatJSON
+	cfg.Count = 51
+	cfg.MessagesPerSecond = 200
+
+	// The last of 51 messages at 200 per second is due after 250ms
+	started := time.Now()
+	generate(t, cfg)
+	assert.GreaterOrEqual(t, time.Since(started), 250*time.Millisecond)
+}
+
+func TestGeneratorSource_StopsOnCancel(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.MessagesPerSecond = 100
+
+	source, err := BuildFromConfig(&cfg)
+	require.NoError(t, err)
+	output := make(chan *models.Message)
+	source.SetChannels(output)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		source.Start(ctx)
+	}()
+
+	<-output
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("source did not stop once its context was cancelled")
+	}
+	_, ok := <-output
+	assert.False(t, ok, "source must close its output channel")
+}
+
+func TestGeneratorSource_InvalidConfiguration(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Modify func(cfg *Configuration)
+		Error  string
+	}{
+		{
+			Name:   "unknown format",
+			Modify: func(cfg *Configuration) { cfg.Format = "csv" },
+			Error:  `invalid format "csv", expected one of 'enriched', 'json', 'file'`,
+		},
+		{
+			Name:   "negative rate",
+			Modify: func(cfg *Configuration) { cfg.MessagesPerSecond = -1 },
+			Error:  "invalid messages_per_second -1, must not be negative",
+		},
+		{
+			Name:   "no app IDs",
+			Modify: func(cfg *Configuration) { cfg.AppIDs = nil },
+			Error:  "format 'enriched' requires at least one app ID",
+		},
+		{
+			Name:   "context without schema",
+			Modify: func(cfg *Configuration) { cfg.Contexts = []string{`{"data":{}}`} },
+			Error:  `contexts[0] has an invalid schema URI "", expected iglu:vendor/name/format/version`,
+		},
+		{
+			Name:   "unstruct event which is not JSON",
+			Modify: func(cfg *Configuration) { cfg.UnstructEvents = []string{`{{ uuid }}`} },
+			Error:  "unstruct_events[0] does not render valid JSON: invalid character",
+		},
+		{
+			Name: "template which does not parse",
+			Modify: func(cfg *Configuration) {
+				cfg.Format = FormatJSON
+				cfg.JSONTemplate = `{{ unknown }}`
+			},
+			Error: "failed to parse json_template",
+		},
+		{
+			Name:   "file without path",
+			Modify: func(cfg *Configuration) { cfg.Format = FormatFile },
+			Error:  "format 'file' requires a file to replay",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			cfg := DefaultConfiguration()
+			tt.Modify(&cfg)
+
+			source, err := BuildFromConfig(&cfg)
+			assert.Nil(t, source)
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), tt.Error), err.Error())
+		})
+	}
+}
+
+func TestGeneratorSource_NoBurstAfterStall(t *testing.T) {
+	cfg := DefaultConfiguration()
+	cfg.Format = FormatJSON
+	cfg.MessagesPerSecond = 100
+
+	source, err := BuildFromConfig(&cfg)
+	require.NoError(t, err)
+	source.(*generatorSourceDriver).reportInterval = 50 * time.Millisecond
+	output := make(chan *models.Message)
+	source.SetChannels(output)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go source.Start(ctx)
+
+	// Nothing is read for long enough that about 50 messages fall due
+	<-output
+	time.Sleep(500 * time.Millisecond)
+
+	// Generating resumes at the requested rate, rather than catching up on them in a burst
+	window := time.After(100 * time.Millisecond)
+	received := 0
+	for collecting := true; collecting; {
+		select {
+		case <-output:
+			received++
+		case <-window:
+			collecting = false
+		}
+	}
+	assert.LessOrEqual(t, received, 20)
+}
@@ -0,0 +1,73 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package generatorsource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+)
+
+const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// payloadTemplate renders a JSON payload with random values
+type payloadTemplate struct {
+	name string
+	tmpl *template.Template
+}
+
+// parseTemplate parses a payload template, in which these functions draw random values:
+// uuid, randInt min max, randString length, randChoice options... and now, the current time in RFC3339.
+func (r *random) parseTemplate(name, text string) (*payloadTemplate, error) {
+	if text == "" {
+		return nil, fmt.Errorf("%s must not be empty", name)
+	}
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"uuid": r.uuid,
+		"randInt": func(min, max int) int {
+			return min + r.Intn(max-min+1)
+		},
+		"randString": func(length int) string {
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = charset[r.Intn(len(charset))]
+			}
+			return string(b)
+		},
+		"randChoice": func(options ...string) string {
+			return options[r.Intn(len(options))]
+		},
+		"now": func() string {
+			return time.Now().UTC().Format(time.RFC3339Nano)
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return &payloadTemplate{name: name, tmpl: tmpl}, nil
+}
+
+// renderJSON renders the template, which must produce valid JSON, compacted to a single line
+func (t *payloadTemplate) renderJSON() (string, error) {
+	var rendered bytes.Buffer
+	if err := t.tmpl.Execute(&rendered, nil); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", t.name, err)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, rendered.Bytes()); err != nil {
+		return "", fmt.Errorf("%s does not render valid JSON: %w", t.name, err)
+	}
+	return compacted.String(), nil
+}
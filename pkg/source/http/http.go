@@ -24,6 +24,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+	"github.com/snowplow/snowbridge/v5/pkg/tracing"
 )
 
 // Note: This is an experimental source
@@ -130,6 +131,7 @@ func (hs *httpSourceDriver) Start(ctx context.Context) {
 				PartitionKey: uuid.New().String(),
 				TimeCreated:  timeNow,
 				TimePulled:   timeNow,
+				TraceParent:  r.Header.Get(tracing.TraceParentHeader),
 			}
 
 			// Send message with context awareness
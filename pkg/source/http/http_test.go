@@ -385,3 +385,50 @@ func TestHttpSource_Cancellation(t *testing.T) {
 	_, ok := <-outputChannel
 	assert.False(ok, "Output channel should be closed")
 }
+
+func TestHttpSource_TraceParent(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &Configuration{
+		RequestBatchLimit: 2,
+		URL:               "localhost:18087",
+		Path:              "/webhook",
+	}
+
+	source, err := BuildFromConfig(config)
+	require.NoError(t, err)
+
+	outputChannel := make(chan *models.Message, 10)
+	source.SetChannels(outputChannel)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go source.Start(ctx)
+
+	// Give server time to start
+	time.Sleep(200 * time.Millisecond)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	httpSrc := source.(*httpSourceDriver)
+	req, err := http.NewRequest(http.MethodPost, "http://"+httpSrc.url+httpSrc.path, bytes.NewBufferString("line 1\nline 2"))
+	require.NoError(t, err)
+	req.Header.Set("traceparent", traceParent)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	if err := resp.Body.Close(); err != nil {
+		t.Logf("failed to close response body: %s", err)
+	}
+
+	// Every message of the request carries its trace parent
+	for range 2 {
+		select {
+		case msg := <-outputChannel:
+			assert.Equal(traceParent, msg.TraceParent)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for message")
+		}
+	}
+}
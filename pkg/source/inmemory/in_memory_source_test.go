@@ -19,6 +19,7 @@ import (
 
 	"github.com/snowplow/snowbridge/v5/pkg/common"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
 	"github.com/snowplow/snowbridge/v5/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -66,3 +67,19 @@ func TestInMemorySource(t *testing.T) {
 	cancel()
 	assert.True(common.WaitWithTimeout(&wg, 10*time.Second))
 }
+
+func TestInMemorySource_Conformance(t *testing.T) {
+	input := make(chan []string)
+	testutil.RunSourceConformance(t, testutil.SourceConformance{
+		NewSource: func(t *testing.T) sourceiface.Source {
+			source, err := Build(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return source
+		},
+		Send: func(t *testing.T, data []string) {
+			input <- data
+		},
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/snowplow/snowbridge/v5/pkg/common"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+	"github.com/snowplow/snowbridge/v5/pkg/tracing"
 )
 
 const SupportedSourceKafka = "kafka"
@@ -39,7 +40,7 @@ type Configuration struct {
 	TargetVersion string `hcl:"target_version,optional"`
 	EnableSASL    bool   `hcl:"enable_sasl,optional"`
 	SASLUsername  string `hcl:"sasl_username,optional" `
-	SASLPassword  string `hcl:"sasl_password,optional"`
+	SASLPassword  string `hcl:"sasl_password,optional" sensitive:"true"`
 	SASLAlgorithm string `hcl:"sasl_algorithm,optional"`
 	SASLVersion   int16  `hcl:"sasl_version,optional"`
 	EnableTLS     bool   `hcl:"enable_tls,optional"`
@@ -205,6 +206,7 @@ func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, clai
 			PartitionKey: uuid.New().String(),
 			TimeCreated:  message.Timestamp,
 			TimePulled:   time.Now().UTC(),
+			TraceParent:  traceParent(message),
 		}
 		if session != nil {
 			// Create the sequenced ack function that will enforce ordering
@@ -315,3 +317,13 @@ func (s *kafkaOffsetSequencer) createSequencedAck(
 		})
 	}
 }
+
+// traceParent returns the W3C traceparent header of a record, if it has one
+func traceParent(message *sarama.ConsumerMessage) string {
+	for _, header := range message.Headers {
+		if header != nil && string(header.Key) == tracing.TraceParentHeader {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
@@ -286,3 +286,19 @@ func TestKafkaSource_AtLeastOnce(t *testing.T) {
 	assert.Equal("msg-8", string(secondBatch[8].Data))
 	assert.Equal("msg-9", string(secondBatch[9].Data))
 }
+
+func TestTraceParent(t *testing.T) {
+	assert := assert.New(t)
+
+	expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	message := &sarama.ConsumerMessage{
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("other"), Value: []byte("value")},
+			nil,
+			{Key: []byte("traceparent"), Value: []byte(expected)},
+		},
+	}
+	assert.Equal(expected, traceParent(message))
+
+	assert.Empty(traceParent(&sarama.ConsumerMessage{}))
+}
@@ -0,0 +1,109 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package sourceconfig
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	config "github.com/snowplow/snowbridge/v5/config"
+	generatorsource "github.com/snowplow/snowbridge/v5/pkg/source/generator"
+	httpsource "github.com/snowplow/snowbridge/v5/pkg/source/http"
+	kafkasource "github.com/snowplow/snowbridge/v5/pkg/source/kafka"
+	pubsubsource "github.com/snowplow/snowbridge/v5/pkg/source/pubsub"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+	sqssource "github.com/snowplow/snowbridge/v5/pkg/source/sqs"
+	stdinsource "github.com/snowplow/snowbridge/v5/pkg/source/stdin"
+)
+
+// kinesisSourceName is the name of the kinesis source, which is only supported in the aws-only build
+const kinesisSourceName = "kinesis"
+
+var (
+	registryMu sync.RWMutex
+
+	// registry holds the sources which can be configured in a `use` block, by name
+	registry = map[string]config.Pluggable{
+		stdinsource.SupportedSourceStdin:         adaptSource(stdinsource.DefaultConfiguration, stdinsource.BuildFromConfig),
+		kafkasource.SupportedSourceKafka:         adaptSource(kafkasource.DefaultConfiguration, kafkasource.BuildFromConfig),
+		pubsubsource.SupportedSourcePubsub:       adaptSource(pubsubsource.DefaultConfiguration, pubsubsource.BuildFromConfig),
+		sqssource.SupportedSourceSQS:             adaptSource(sqssource.DefaultConfiguration, sqssource.BuildFromConfig),
+		httpsource.SupportedSourceHTTP:           adaptSource(httpsource.DefaultConfiguration, httpsource.BuildFromConfig),
+		generatorsource.SupportedSourceGenerator: adaptSource(generatorsource.DefaultConfiguration, generatorsource.BuildFromConfig),
+	}
+)
+
+// RegisterSource makes a source available under name, so that it can be configured in a `use` block like the built in ones.
+// ProvideDefault must return a pointer to the configuration the block is decoded onto,
+// and Create must build a sourceiface.Source from it.
+// Sources are registered before the config is read, usually from an init function.
+func RegisterSource(name string, plug config.Pluggable) error {
+	if name == "" {
+		return errors.New("source name must not be empty")
+	}
+	if plug == nil {
+		return fmt.Errorf("source %q must not be nil", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	// The kinesis source is built in to the aws-only build, and reserved in others
+	if _, ok := registry[name]; ok || name == kinesisSourceName {
+		return fmt.Errorf("source %q is already registered", name)
+	}
+	registry[name] = plug
+	return nil
+}
+
+// lookupSource returns the source registered under name
+func lookupSource(name string) (config.Pluggable, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	plug, ok := registry[name]
+	return plug, ok
+}
+
+// sourceAdapter adapts the functions configuring and building a built in source to the Pluggable interface
+type sourceAdapter struct {
+	provideDefault func() any
+	create         func(cfg any) (any, error)
+}
+
+// ProvideDefault implements the ComponentConfigurable interface.
+func (a sourceAdapter) ProvideDefault() (any, error) {
+	return a.provideDefault(), nil
+}
+
+// Create implements the ComponentCreator interface.
+func (a sourceAdapter) Create(cfg any) (any, error) {
+	return a.create(cfg)
+}
+
+// adaptSource returns the Pluggable of a built in source configured by a struct of type C
+func adaptSource[C any](defaultConfiguration func() C, buildFromConfig func(*C) (sourceiface.Source, error)) config.Pluggable {
+	return sourceAdapter{
+		provideDefault: func() any {
+			cfg := defaultConfiguration()
+			return &cfg
+		},
+		create: func(i any) (any, error) {
+			cfg, ok := i.(*C)
+			if !ok {
+				return nil, fmt.Errorf("invalid input, expected %T", cfg)
+			}
+			return buildFromConfig(cfg)
+		},
+	}
+}
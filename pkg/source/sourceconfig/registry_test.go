@@ -0,0 +1,95 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package sourceconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+)
+
+// customSourceConfig configures customSource
+type customSourceConfig struct {
+	Address string `hcl:"address"`
+	Workers int    `hcl:"workers,optional"`
+}
+
+// customSource stands in for a source implemented outside of snowbridge
+type customSource struct {
+	sourceiface.SourceChannels
+	cfg *customSourceConfig
+}
+
+func (s *customSource) Start(ctx context.Context) {}
+
+// customSourceAdapter implements the Pluggable interface for customSource
+type customSourceAdapter struct{}
+
+func (customSourceAdapter) ProvideDefault() (any, error) {
+	return &customSourceConfig{Workers: 2}, nil
+}
+
+func (customSourceAdapter) Create(i any) (any, error) {
+	cfg, ok := i.(*customSourceConfig)
+	if !ok {
+		return nil, errors.New("invalid input, expected customSourceConfig")
+	}
+	return &customSource{cfg: cfg}, nil
+}
+
+func TestRegisterSource(t *testing.T) {
+	assert := assert.New(t)
+	require.NoError(t, RegisterSource("custom", customSourceAdapter{}))
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, "custom")
+	})
+
+	c, err := config.NewHclConfig([]byte(`
+source {
+  use "custom" {
+    address = "localhost:1234"
+  }
+}
+`), "test.hcl")
+	require.NoError(t, err)
+
+	source, channel, err := GetSource(c, nil)
+	require.NoError(t, err)
+	assert.NotNil(channel)
+
+	custom, ok := source.(*customSource)
+	if assert.True(ok) {
+		assert.Equal(&customSourceConfig{Address: "localhost:1234", Workers: 2}, custom.cfg)
+	}
+
+	assert.NoError(ValidateSource(c))
+	defaults, err := SourceDefaults()
+	assert.NoError(err)
+	assert.Contains(defaults, "custom")
+}
+
+func TestRegisterSource_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.EqualError(RegisterSource("", customSourceAdapter{}), "source name must not be empty")
+	assert.EqualError(RegisterSource("custom", nil), `source "custom" must not be nil`)
+	assert.EqualError(RegisterSource("stdin", customSourceAdapter{}), `source "stdin" is already registered`)
+	assert.EqualError(RegisterSource("kinesis", customSourceAdapter{}), `source "kinesis" is already registered`)
+}
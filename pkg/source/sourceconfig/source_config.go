@@ -13,54 +13,101 @@ package sourceconfig
 
 import (
 	"fmt"
-	httpsource "github.com/snowplow/snowbridge/v5/pkg/source/http"
 
 	config "github.com/snowplow/snowbridge/v5/config"
-	kafkasource "github.com/snowplow/snowbridge/v5/pkg/source/kafka"
-	pubsubsource "github.com/snowplow/snowbridge/v5/pkg/source/pubsub"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/observer"
 	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
-	sqssource "github.com/snowplow/snowbridge/v5/pkg/source/sqs"
-	stdinsource "github.com/snowplow/snowbridge/v5/pkg/source/stdin"
 )
 
-func sourceCommon(c *config.Config) (sourceiface.Source, error) {
+// GetSource takes a config and some shared resources, and creates a new source, along with the message channel for the transformer to read from.
+func GetSource(
+	c *config.Config,
+	obs *observer.Observer,
+) (sourceiface.Source, chan *models.Message, error) {
+	source, err := NewSource(c, obs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The source is the sole producer to the output channel, so ownership clearly lies here.
+	outputChannel := make(chan *models.Message)
+
+	source.SetChannels(outputChannel)
+
+	return source, outputChannel, nil
+}
+
+// NewSource takes a config and some shared resources, and creates a new source, leaving its channels to be set by the caller.
+func NewSource(c *config.Config, obs *observer.Observer) (sourceiface.Source, error) {
+	_, build, err := decodeSource(c, obs)
+	if err != nil {
+		return nil, err
+	}
+	return build()
+}
+
+// SourceDefaults returns the default configuration of every source supported in this build, including registered ones, by name
+func SourceDefaults() (map[string]any, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	defaults := make(map[string]any, len(registry)+1)
+	for name, plug := range registry {
+		cfg, err := plug.ProvideDefault()
+		if err != nil {
+			return nil, fmt.Errorf("failed to provide the default configuration of %s source: %w", name, err)
+		}
+		defaults[name] = cfg
+	}
+	addBuildSourceDefaults(defaults)
+	return defaults, nil
+}
+
+// sourceBuilder builds a source from the configuration it was decoded with
+type sourceBuilder func() (sourceiface.Source, error)
+
+// ValidateSource decodes the configuration of the source, without building it, so nothing is connected to
+func ValidateSource(c *config.Config) error {
+	_, _, err := decodeSource(c, nil)
+	return err
+}
+
+// DecodeSourceConfig returns the decoded configuration of the source, with its defaults filled in
+func DecodeSourceConfig(c *config.Config) (any, error) {
+	cfg, _, err := decodeSource(c, nil)
+	return cfg, err
+}
+
+// decodeRegisteredSource decodes the configuration of a source from the registry
+func decodeRegisteredSource(c *config.Config) (any, sourceBuilder, error) {
 	useSource := c.Data.Source.Use
+
+	plug, ok := lookupSource(useSource.Name)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown source: %s", useSource.Name)
+	}
+
+	cfg, err := plug.ProvideDefault()
+	if err != nil {
+		return nil, nil, err
+	}
 	decoderOpts := &config.DecoderOptions{
 		Input: useSource.Body,
 	}
+	if err := c.Decoder.Decode(decoderOpts, cfg); err != nil {
+		return nil, nil, err
+	}
 
-	switch useSource.Name {
-	case stdinsource.SupportedSourceStdin:
-		cfg := stdinsource.DefaultConfiguration()
-		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
+	return cfg, func() (sourceiface.Source, error) {
+		source, err := plug.Create(cfg)
+		if err != nil {
 			return nil, err
 		}
-		return stdinsource.BuildFromConfig(&cfg)
-	case kafkasource.SupportedSourceKafka:
-		cfg := kafkasource.DefaultConfiguration()
-		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
-			return nil, err
-		}
-		return kafkasource.BuildFromConfig(&cfg)
-	case pubsubsource.SupportedSourcePubsub:
-		cfg := pubsubsource.DefaultConfiguration()
-		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
-			return nil, err
-		}
-		return pubsubsource.BuildFromConfig(&cfg)
-	case sqssource.SupportedSourceSQS:
-		cfg := sqssource.DefaultConfiguration()
-		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
-			return nil, err
-		}
-		return sqssource.BuildFromConfig(&cfg)
-	case httpsource.SupportedSourceHTTP:
-		cfg := httpsource.DefaultConfiguration()
-		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
-			return nil, err
+		s, ok := source.(sourceiface.Source)
+		if !ok {
+			return nil, fmt.Errorf("%s source did not create a sourceiface.Source", useSource.Name)
 		}
-		return httpsource.BuildFromConfig(&cfg)
-	default:
-		return nil, fmt.Errorf("unknown source: %s", useSource.Name)
-	}
+		return s, nil
+	}, nil
 }
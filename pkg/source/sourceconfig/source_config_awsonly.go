@@ -17,24 +17,17 @@ package sourceconfig
 
 import (
 	config "github.com/snowplow/snowbridge/v5/config"
-	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/observer"
 	kinesissource "github.com/snowplow/snowbridge/v5/pkg/source/kinesis"
 	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
 )
 
-// GetSource creates and returns the source that is configured, along with the message channel for the transformer to read from.
-func GetSource(
-	c *config.Config,
-	obs *observer.Observer,
-) (sourceiface.Source, chan *models.Message, error) {
+// decodeSource decodes the configuration of the source, returning it along with a function to build the source
+func decodeSource(c *config.Config, obs *observer.Observer) (any, sourceBuilder, error) {
 	useSource := c.Data.Source.Use
 
-	var source sourceiface.Source
-	var err error
-
 	switch useSource.Name {
-	case "kinesis":
+	case kinesisSourceName:
 		decoderOpts := &config.DecoderOptions{
 			Input: useSource.Body,
 		}
@@ -42,21 +35,14 @@ func GetSource(
 		if err := c.Decoder.Decode(decoderOpts, &cfg); err != nil {
 			return nil, nil, err
 		}
-		source, err = kinesissource.BuildFromConfig(&cfg, obs)
-		if err != nil {
-			return nil, nil, err
-		}
+		return &cfg, func() (sourceiface.Source, error) { return kinesissource.BuildFromConfig(&cfg, obs) }, nil
 	default:
-		source, err = sourceCommon(c)
-		if err != nil {
-			return nil, nil, err
-		}
+		return decodeRegisteredSource(c)
 	}
+}
 
-	// The source is the sole producer to the output channel, so ownership clearly lies here.
-	outputChannel := make(chan *models.Message)
-
-	source.SetChannels(outputChannel)
-
-	return source, outputChannel, nil
+// addBuildSourceDefaults adds the default configuration of the sources only supported in this build
+func addBuildSourceDefaults(defaults map[string]any) {
+	kinesisCfg := kinesissource.DefaultConfiguration()
+	defaults[kinesisSourceName] = &kinesisCfg
 }
@@ -56,3 +56,9 @@ func TestGetSource_WithKinesisSource(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(kinesisSource)
 }
+
+func TestSourceDefaults_WithKinesisSource(t *testing.T) {
+	defaults, err := SourceDefaults()
+	assert.NoError(t, err)
+	assert.Contains(t, defaults, "kinesis")
+}
@@ -19,37 +19,18 @@ import (
 	"fmt"
 
 	config "github.com/snowplow/snowbridge/v5/config"
-	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/observer"
-	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
 )
 
-// GetSource takes a config and some shared resources, and creates a new source, along with the message channel for the transformer to read from.
-func GetSource(
-	c *config.Config,
-	obs *observer.Observer,
-) (sourceiface.Source, chan *models.Message, error) {
-	useSource := c.Data.Source.Use
-
-	var source sourceiface.Source
-	var err error
-
-	switch useSource.Name {
-
-	case "kinesis":
+// decodeSource decodes the configuration of the source, returning it along with a function to build the source
+func decodeSource(c *config.Config, _ *observer.Observer) (any, sourceBuilder, error) {
+	switch c.Data.Source.Use.Name {
+	case kinesisSourceName:
 		return nil, nil, fmt.Errorf("kinesis source is not supported in this build, use the aws-only build instead")
-
 	default:
-		source, err = sourceCommon(c)
-		if err != nil {
-			return nil, nil, err
-		}
+		return decodeRegisteredSource(c)
 	}
-
-	// The source is the sole producer to the output channel, so ownership clearly lies here.
-	outputChannel := make(chan *models.Message)
-
-	source.SetChannels(outputChannel)
-
-	return source, outputChannel, nil
 }
+
+// addBuildSourceDefaults adds the default configuration of the sources only supported in this build, there are none
+func addBuildSourceDefaults(map[string]any) {}
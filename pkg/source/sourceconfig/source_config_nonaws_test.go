@@ -40,3 +40,9 @@ func TestGetSource_WithKinesisSource(t *testing.T) {
 	assert.ErrorContains(err, "kinesis source is not supported in this build, use the aws-only build instead")
 	assert.Nil(kinesisSource)
 }
+
+func TestSourceDefaults_WithoutKinesisSource(t *testing.T) {
+	defaults, err := SourceDefaults()
+	assert.NoError(t, err)
+	assert.NotContains(t, defaults, "kinesis")
+}
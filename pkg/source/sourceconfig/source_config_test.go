@@ -44,6 +44,42 @@ func TestGetSource_InvalidSource(t *testing.T) {
 	assert.Contains(err.Error(), "unknown source: fake_invalid_source")
 }
 
+// TestValidateSource checks that sources are validated without connecting to them, so this passes without a broker
+func TestValidateSource(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		source {
+			use "kafka" {
+				brokers = "localhost:1"
+				topic_name = "some-topic"
+				consumer_name = "some-consumer"
+				offsets_initial = -2
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	assert.NoError(ValidateSource(c))
+}
+
+func TestValidateSource_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		source {
+			use "fake_invalid_source" {}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	assert.EqualError(ValidateSource(c), "unknown source: fake_invalid_source")
+}
+
 func TestGetSource_WithStdinSource(t *testing.T) {
 	assert := assert.New(t)
 
@@ -189,3 +225,13 @@ func TestGetSource_WithHTTPSource(t *testing.T) {
 	assert.NoError(err)
 	assert.NotNil(httpSource)
 }
+
+func TestSourceDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	defaults, err := SourceDefaults()
+	assert.NoError(err)
+	for _, name := range []string{"stdin", "kafka", "pubsub", "sqs", "http", "generator"} {
+		assert.Contains(defaults, name)
+	}
+}
@@ -0,0 +1,307 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package spill
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+const segmentExt = ".wal"
+
+// ErrFull is returned when appending a batch would take the log over its size cap
+var ErrFull = errors.New("spill log is full")
+
+// record is the on-disk representation of a spilled message
+type record struct {
+	Data            []byte            `json:"data"`
+	PartitionKey    string            `json:"partition_key,omitempty"`
+	HTTPHeaders     map[string]string `json:"http_headers,omitempty"`
+	Route           string            `json:"route,omitempty"`
+	TimeCreated     time.Time         `json:"time_created"`
+	TimePulled      time.Time         `json:"time_pulled"`
+	TimeTransformed time.Time         `json:"time_transformed"`
+}
+
+// Segment is a single spilled batch, as read back from the log
+type Segment struct {
+	Messages []*models.Message
+
+	name string
+	size int64
+}
+
+// Log is a directory-backed write-ahead log of batches which could not be written to a target.
+// Each batch is stored in its own segment file, and segments are read back in the order they were written.
+type Log struct {
+	dir      string
+	maxBytes int64
+	aead     cipher.AEAD
+
+	mu   sync.Mutex
+	size int64
+	seq  uint64
+}
+
+// NewCipher returns the AES-GCM cipher segments are encrypted with, from a base64 encoded AES key (16, 24 or 32 bytes).
+// It returns nil when encryptionKey is empty, as segments are then written in the clear.
+func NewCipher(encryptionKey string) (cipher.AEAD, error) {
+	if encryptionKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encryptionKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode spill encryption_key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid spill encryption_key")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create spill cipher")
+	}
+	return aead, nil
+}
+
+// New opens the log in dir, creating the directory if needed and picking up any segments left by a previous run.
+// encryptionKey is an optional base64 encoded AES key (16, 24 or 32 bytes); when set, segments are encrypted with AES-GCM.
+func New(dir string, maxBytes int64, encryptionKey string) (*Log, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("invalid spill max_bytes %d, must be greater than 0", maxBytes)
+	}
+
+	aead, err := NewCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{dir: dir, maxBytes: maxBytes, aead: aead}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create spill directory")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read spill directory")
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		// Leftovers from a write interrupted before it was renamed into place
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return nil, errors.Wrap(err, "failed to remove incomplete spill segment")
+			}
+			continue
+		}
+
+		seq, ok := segmentSeq(entry.Name())
+		if !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to stat spill segment")
+		}
+		l.size += info.Size()
+		if seq >= l.seq {
+			l.seq = seq + 1
+		}
+	}
+
+	return l, nil
+}
+
+// Append durably writes a batch to the log as a new segment.
+// It returns ErrFull if the segment doesn't fit under the size cap.
+func (l *Log) Append(messages []*models.Message) error {
+	records := make([]record, len(messages))
+	for i, msg := range messages {
+		records[i] = record{
+			Data:            msg.Data,
+			PartitionKey:    msg.PartitionKey,
+			HTTPHeaders:     msg.HTTPHeaders,
+			Route:           msg.Route,
+			TimeCreated:     msg.TimeCreated,
+			TimePulled:      msg.TimePulled,
+			TimeTransformed: msg.TimeTransformed,
+		}
+	}
+
+	payload, err := json.Marshal(records)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode spill segment")
+	}
+
+	if l.aead != nil {
+		nonce := make([]byte, l.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return errors.Wrap(err, "failed to generate spill nonce")
+		}
+		payload = l.aead.Seal(nonce, nonce, payload, nil)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.size+int64(len(payload)) > l.maxBytes {
+		return ErrFull
+	}
+
+	name := fmt.Sprintf("%020d%s", l.seq, segmentExt)
+	if err := writeFileSync(filepath.Join(l.dir, name), payload); err != nil {
+		return err
+	}
+
+	l.seq++
+	l.size += int64(len(payload))
+	return nil
+}
+
+// Oldest reads back the oldest segment in the log, or returns nil if the log is empty
+func (l *Log) Oldest() (*Segment, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read spill directory")
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if _, ok := segmentSeq(entry.Name()); ok && !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	sort.Strings(names)
+
+	payload, err := os.ReadFile(filepath.Join(l.dir, names[0]))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read spill segment")
+	}
+	size := int64(len(payload))
+
+	if l.aead != nil {
+		nonceSize := l.aead.NonceSize()
+		if len(payload) < nonceSize {
+			return nil, fmt.Errorf("spill segment %s is too short to be decrypted", names[0])
+		}
+		payload, err = l.aead.Open(nil, payload[:nonceSize], payload[nonceSize:], nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt spill segment %s", names[0])
+		}
+	}
+
+	var records []record
+	if err := json.Unmarshal(payload, &records); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode spill segment %s", names[0])
+	}
+
+	messages := make([]*models.Message, len(records))
+	for i, r := range records {
+		messages[i] = &models.Message{
+			Data:            r.Data,
+			PartitionKey:    r.PartitionKey,
+			HTTPHeaders:     r.HTTPHeaders,
+			Route:           r.Route,
+			TimeCreated:     r.TimeCreated,
+			TimePulled:      r.TimePulled,
+			TimeTransformed: r.TimeTransformed,
+		}
+	}
+
+	return &Segment{Messages: messages, name: names[0], size: size}, nil
+}
+
+// Remove deletes a segment once it has been replayed
+func (l *Log) Remove(segment *Segment) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.Remove(filepath.Join(l.dir, segment.name)); err != nil {
+		return errors.Wrap(err, "failed to remove spill segment")
+	}
+	l.size -= segment.size
+	return nil
+}
+
+// Size returns the number of bytes currently held in the log
+func (l *Log) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// writeFileSync writes data to a temporary file, syncs it and renames it into place,
+// so that a crash never leaves a partially written segment behind
+func writeFileSync(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create spill segment")
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to write spill segment")
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to sync spill segment")
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to close spill segment")
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return errors.Wrap(err, "failed to commit spill segment")
+	}
+	return nil
+}
+
+// segmentSeq parses the sequence number out of a segment file name
+func segmentSeq(name string) (uint64, bool) {
+	if !strings.HasSuffix(name, segmentExt) {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seq, true
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package spill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// 32 byte AES key, base64 encoded
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestLog_AppendAndReadBack(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := New(t.TempDir(), 1000000, "")
+	if err != nil {
+		t.Fatalf("failed to create log: %s", err)
+	}
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := []*models.Message{{
+		Data:         []byte("message1"),
+		PartitionKey: "pk1",
+		HTTPHeaders:  map[string]string{"foo": "bar"},
+		Route:        "kafka",
+		TimeCreated:  created,
+		AckFunc:      func() {},
+	}}
+	second := []*models.Message{{Data: []byte("message2")}}
+
+	assert.Nil(l.Append(first))
+	assert.Nil(l.Append(second))
+	assert.Positive(l.Size())
+
+	// Segments come back oldest first, without their ack funcs
+	segment, err := l.Oldest()
+	assert.Nil(err)
+	if assert.Equal(1, len(segment.Messages)) {
+		msg := segment.Messages[0]
+		assert.Equal("message1", string(msg.Data))
+		assert.Equal("pk1", msg.PartitionKey)
+		assert.Equal(map[string]string{"foo": "bar"}, msg.HTTPHeaders)
+		assert.Equal("kafka", msg.Route)
+		assert.Equal(created, msg.TimeCreated)
+		assert.Nil(msg.AckFunc)
+	}
+	assert.Nil(l.Remove(segment))
+
+	segment, err = l.Oldest()
+	assert.Nil(err)
+	assert.Equal("message2", string(segment.Messages[0].Data))
+	assert.Nil(l.Remove(segment))
+
+	segment, err = l.Oldest()
+	assert.Nil(err)
+	assert.Nil(segment)
+	assert.Zero(l.Size())
+}
+
+func TestLog_Full(t *testing.T) {
+	assert := assert.New(t)
+
+	l, err := New(t.TempDir(), 300, "")
+	if err != nil {
+		t.Fatalf("failed to create log: %s", err)
+	}
+
+	assert.Nil(l.Append([]*models.Message{{Data: []byte("small")}}))
+	assert.ErrorIs(l.Append([]*models.Message{{Data: make([]byte, 300)}}), ErrFull)
+}
+
+func TestLog_Reopen(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	l, err := New(dir, 1000000, testKey)
+	if err != nil {
+		t.Fatalf("failed to create log: %s", err)
+	}
+	assert.Nil(l.Append([]*models.Message{{Data: []byte("message1")}}))
+
+	// A write interrupted before being committed is discarded
+	assert.Nil(os.WriteFile(filepath.Join(dir, "00000000000000000001.wal.tmp"), []byte("partial"), 0o600))
+
+	reopened, err := New(dir, 1000000, testKey)
+	if err != nil {
+		t.Fatalf("failed to reopen log: %s", err)
+	}
+	assert.Equal(l.Size(), reopened.Size())
+	assert.NoFileExists(filepath.Join(dir, "00000000000000000001.wal.tmp"))
+
+	// New segments are written after the existing ones
+	assert.Nil(reopened.Append([]*models.Message{{Data: []byte("message2")}}))
+	segment, err := reopened.Oldest()
+	assert.Nil(err)
+	assert.Equal("message1", string(segment.Messages[0].Data))
+}
+
+func TestLog_Encryption(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	l, err := New(dir, 1000000, testKey)
+	if err != nil {
+		t.Fatalf("failed to create log: %s", err)
+	}
+	assert.Nil(l.Append([]*models.Message{{Data: []byte("secret-payload")}}))
+
+	raw, err := os.ReadFile(filepath.Join(dir, "00000000000000000000.wal"))
+	assert.Nil(err)
+	assert.NotContains(string(raw), "secret-payload")
+
+	segment, err := l.Oldest()
+	assert.Nil(err)
+	assert.Equal("secret-payload", string(segment.Messages[0].Data))
+
+	// Reading with the wrong key fails rather than returning garbage
+	wrongKey, err := New(dir, 1000000, "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA=")
+	if err != nil {
+		t.Fatalf("failed to reopen log: %s", err)
+	}
+	_, err = wrongKey.Oldest()
+	assert.ErrorContains(err, "failed to decrypt spill segment")
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := New(t.TempDir(), 0, "")
+	assert.EqualError(err, "invalid spill max_bytes 0, must be greater than 0")
+
+	_, err = New(t.TempDir(), 100, "not base64!")
+	assert.ErrorContains(err, "failed to decode spill encryption_key")
+
+	_, err = New(t.TempDir(), 100, "c2hvcnQ=")
+	assert.ErrorContains(err, "invalid spill encryption_key")
+}
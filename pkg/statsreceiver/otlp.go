@@ -0,0 +1,278 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package statsreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// OTLPStatsReceiverConfig configures the OpenTelemetry (OTLP over HTTP) metrics receiver
+type OTLPStatsReceiverConfig struct {
+	Endpoint    string            `hcl:"endpoint,optional"`
+	Headers     map[string]string `hcl:"headers,optional" sensitive:"true"`
+	ServiceName string            `hcl:"service_name,optional"`
+	Tags        string            `hcl:"tags,optional"`
+	TimeoutSec  int               `hcl:"timeout_sec,optional"`
+}
+
+// otlpStatsReceiver pushes the buffered metrics to an OTLP collector every time they are sent
+type otlpStatsReceiver struct {
+	reader   *sdkmetric.ManualReader
+	exporter sdkmetric.Exporter
+	timeout  time.Duration
+	attrs    metric.MeasurementOption
+
+	targetSuccess        metric.Int64Counter
+	targetFailed         metric.Int64Counter
+	targetRequestCount   metric.Int64Counter
+	messageFiltered      metric.Int64Counter
+	failureTargetSuccess metric.Int64Counter
+	failureTargetFailed  metric.Int64Counter
+
+	perTargetSuccess      metric.Int64Counter
+	perTargetFailed       metric.Int64Counter
+	perTargetRequestCount metric.Int64Counter
+	breakerStateChanges   metric.Int64Counter
+	breakerState          metric.Int64Gauge
+
+	processingLatency metric.Float64Histogram
+	messageLatency    metric.Float64Histogram
+	transformLatency  metric.Float64Histogram
+	filterLatency     metric.Float64Histogram
+	requestLatency    metric.Float64Histogram
+	e2eLatency        metric.Float64Histogram
+
+	kinsumerRecordsInMemory      metric.Int64Gauge
+	kinsumerRecordsInMemoryBytes metric.Int64Gauge
+
+	enableE2ELatency            bool
+	enableKinsumerMemoryMetrics bool
+}
+
+// newOTLPStatsReceiver creates the instruments and an exporter pushing them to the given endpoint
+func newOTLPStatsReceiver(endpoint string, headers map[string]string, serviceName string, tagsRaw string, tagsMapClient map[string]string, timeout time.Duration, enableE2ELatency bool, enableKinsumerMemoryMetrics bool) (*otlpStatsReceiver, error) {
+	tagsMap := map[string]string{}
+	err := json.Unmarshal([]byte(tagsRaw), &tagsMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshall tags to map")
+	}
+
+	resourceAttrs := []attribute.KeyValue{attribute.String("service.name", serviceName)}
+	var attrs []attribute.KeyValue
+	for key, value := range tagsMap {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	for key, value := range tagsMapClient {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	exporter, err := otlpmetrichttp.New(context.Background(),
+		otlpmetrichttp.WithEndpointURL(endpoint),
+		otlpmetrichttp.WithHeaders(headers),
+		otlpmetrichttp.WithTimeout(timeout),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create OTLP metrics exporter")
+	}
+
+	reader := sdkmetric.NewManualReader(sdkmetric.WithTemporalitySelector(exporter.Temporality))
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(resource.NewSchemaless(resourceAttrs...)),
+	)
+	meter := provider.Meter("github.com/snowplow/snowbridge/v5/pkg/statsreceiver")
+
+	// Instrument creation only fails on invalid names, so keeping the first error is enough to report it
+	var instrumentErr error
+	counter := func(name, description string) metric.Int64Counter {
+		c, err := meter.Int64Counter(name, metric.WithDescription(description))
+		if instrumentErr == nil {
+			instrumentErr = err
+		}
+		return c
+	}
+	gauge := func(name, description string) metric.Int64Gauge {
+		g, err := meter.Int64Gauge(name, metric.WithDescription(description))
+		if instrumentErr == nil {
+			instrumentErr = err
+		}
+		return g
+	}
+	histogram := func(name, description string) metric.Float64Histogram {
+		h, err := meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit("s"), metric.WithExplicitBucketBoundaries(latencyBuckets...))
+		if instrumentErr == nil {
+			instrumentErr = err
+		}
+		return h
+	}
+
+	s := &otlpStatsReceiver{
+		reader:   reader,
+		exporter: exporter,
+		timeout:  timeout,
+		attrs:    metric.WithAttributes(attrs...),
+
+		targetSuccess:        counter("target_success", "Messages successfully written to the target"),
+		targetFailed:         counter("target_failed", "Messages that failed to be written to the target"),
+		targetRequestCount:   counter("target_request_count", "Write requests made to the target"),
+		messageFiltered:      counter("message_filtered", "Messages dropped by a filter"),
+		failureTargetSuccess: counter("failure_target_success", "Invalid messages successfully written to the failure target"),
+		failureTargetFailed:  counter("failure_target_failed", "Invalid messages that failed to be written to the failure target"),
+
+		perTargetSuccess:      counter("per_target_success", "Messages successfully written, by target"),
+		perTargetFailed:       counter("per_target_failed", "Messages that failed to be written, by target"),
+		perTargetRequestCount: counter("per_target_request_count", "Write requests made, by target"),
+		breakerStateChanges:   counter("circuit_breaker_state_changes", "Circuit breaker state transitions, by target"),
+		breakerState:          gauge("circuit_breaker_state", "Circuit breaker state (0 closed, 1 half-open, 2 open), by target"),
+
+		processingLatency: histogram("processing_latency", "Time from the message being pulled from the source to it being written"),
+		messageLatency:    histogram("message_latency", "Time from the message arriving at the source to it being written"),
+		transformLatency:  histogram("transform_latency", "Time spent transforming the message"),
+		filterLatency:     histogram("filter_latency", "Time from the message being pulled from the source to it being filtered"),
+		requestLatency:    histogram("request_latency", "Time spent on the target request"),
+		e2eLatency:        histogram("e2e_latency", "Time from the collector timestamp to the message being written"),
+
+		kinsumerRecordsInMemory:      gauge("kinsumer_records_in_memory", "Records currently held in memory by kinsumer"),
+		kinsumerRecordsInMemoryBytes: gauge("kinsumer_records_in_memory_bytes", "Bytes of records currently held in memory by kinsumer"),
+
+		enableE2ELatency:            enableE2ELatency,
+		enableKinsumerMemoryMetrics: enableKinsumerMemoryMetrics,
+	}
+	if instrumentErr != nil {
+		return nil, errors.Wrap(instrumentErr, "Failed to create OTLP metric instrument")
+	}
+
+	return s, nil
+}
+
+// NewOTLPReceiverWithTags closes over a given tags map and returns a function
+// that creates an OTLPStatsReceiver given an OTLPStatsReceiverConfig.
+func NewOTLPReceiverWithTags(tags map[string]string, enableE2ELatency bool, enableKinsumerMemoryMetrics bool) func(c *OTLPStatsReceiverConfig) (*otlpStatsReceiver, error) {
+	return func(c *OTLPStatsReceiverConfig) (*otlpStatsReceiver, error) {
+		return newOTLPStatsReceiver(
+			c.Endpoint,
+			c.Headers,
+			c.ServiceName,
+			c.Tags,
+			tags,
+			time.Duration(c.TimeoutSec)*time.Second,
+			enableE2ELatency,
+			enableKinsumerMemoryMetrics,
+		)
+	}
+}
+
+// The OTLPStatsReceiverAdapter type is an adapter for functions to be used as
+// pluggable components for OTLP Stats Receiver.
+// It implements the Pluggable interface.
+type OTLPStatsReceiverAdapter func(i any) (any, error)
+
+// Create implements the ComponentCreator interface.
+func (f OTLPStatsReceiverAdapter) Create(i any) (any, error) {
+	return f(i)
+}
+
+// ProvideDefault implements the ComponentConfigurable interface.
+func (f OTLPStatsReceiverAdapter) ProvideDefault() (any, error) {
+	// Provide defaults for the optional parameters
+	// whose default is not their zero value.
+	cfg := &OTLPStatsReceiverConfig{
+		Endpoint:    "http://localhost:4318/v1/metrics",
+		ServiceName: "snowbridge",
+		Tags:        "{}",
+		TimeoutSec:  10,
+	}
+
+	return cfg, nil
+}
+
+// AdaptOTLPStatsReceiverFunc returns an OTLPStatsReceiverAdapter.
+func AdaptOTLPStatsReceiverFunc(f func(c *OTLPStatsReceiverConfig) (*otlpStatsReceiver, error)) OTLPStatsReceiverAdapter {
+	return func(i any) (any, error) {
+		cfg, ok := i.(*OTLPStatsReceiverConfig)
+		if !ok {
+			return nil, errors.New("invalid input, expected OTLPStatsReceiverConfig")
+		}
+
+		return f(cfg)
+	}
+}
+
+// Send records the bufferred metrics and pushes them to the collector
+func (s *otlpStatsReceiver) Send(b *models.ObserverBuffer) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// overall
+	s.targetSuccess.Add(ctx, b.MsgSent, s.attrs)
+	s.targetFailed.Add(ctx, b.MsgFailed, s.attrs)
+	s.targetRequestCount.Add(ctx, b.TargetResults, s.attrs)
+	s.messageFiltered.Add(ctx, b.MsgFiltered, s.attrs)
+
+	// per target
+	for name, stats := range b.Targets {
+		targetAttr := metric.WithAttributes(attribute.String("target", name))
+		s.perTargetSuccess.Add(ctx, stats.MsgSent, s.attrs, targetAttr)
+		s.perTargetFailed.Add(ctx, stats.MsgFailed, s.attrs, targetAttr)
+		s.perTargetRequestCount.Add(ctx, stats.TargetResults, s.attrs, targetAttr)
+		s.breakerStateChanges.Add(ctx, stats.BreakerStateChanges, s.attrs, targetAttr)
+	}
+	for name, state := range b.BreakerStates {
+		s.breakerState.Record(ctx, state, s.attrs, metric.WithAttributes(attribute.String("target", name)))
+	}
+
+	// unsendable
+	s.failureTargetSuccess.Add(ctx, b.InvalidMsgSent, s.attrs)
+	s.failureTargetFailed.Add(ctx, b.InvalidMsgFailed, s.attrs)
+
+	// latencies
+	record := func(h metric.Float64Histogram) func(float64) {
+		return func(v float64) { h.Record(ctx, v, s.attrs) }
+	}
+	observeLatency(record(s.processingLatency), &b.ProcLatencies)
+	observeLatency(record(s.messageLatency), &b.MsgLatencies)
+	observeLatency(record(s.transformLatency), &b.TransformLatencies)
+	observeLatency(record(s.filterLatency), &b.FilterLatencies)
+	observeLatency(record(s.requestLatency), &b.RequestLatencies)
+
+	if s.enableE2ELatency {
+		observeLatency(record(s.e2eLatency), &b.E2ELatencies)
+	}
+
+	// kinsumer metrics (only if enabled)
+	if s.enableKinsumerMemoryMetrics {
+		s.kinsumerRecordsInMemory.Record(ctx, b.KinsumerRecordsInMemory, s.attrs)
+		s.kinsumerRecordsInMemoryBytes.Record(ctx, b.KinsumerRecordsInMemoryBytes, s.attrs)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := s.reader.Collect(ctx, &rm); err != nil {
+		log.WithError(err).Error("Failed to collect OTLP metrics")
+		return
+	}
+	if err := s.exporter.Export(ctx, &rm); err != nil {
+		log.WithError(err).Error("Failed to export OTLP metrics")
+	}
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package statsreceiver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collectormetrics "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// otlpCollector records the metrics export requests it receives
+type otlpCollector struct {
+	mu       sync.Mutex
+	requests []*collectormetrics.ExportMetricsServiceRequest
+	headers  []http.Header
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := &collectormetrics.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.headers = append(c.headers, r.Header)
+	c.mu.Unlock()
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestOTLPStatsReceiver_Send(t *testing.T) {
+	assert := assert.New(t)
+
+	collector := &otlpCollector{}
+	server := httptest.NewServer(collector)
+	defer server.Close()
+
+	s, err := newOTLPStatsReceiver(server.URL+"/v1/metrics", map[string]string{"Authorization": "Bearer token"}, "snowbridge", `{"env": "test"}`, map[string]string{"app": "bridge"}, 5*time.Second, false, false)
+	require.NoError(t, err)
+
+	b := &models.ObserverBuffer{
+		MsgSent:       3,
+		MsgFailed:     1,
+		Targets:       map[string]*models.TargetStats{"primary": {MsgSent: 3}},
+		BreakerStates: map[string]int64{"primary": 0},
+	}
+	b.ProcLatencies.Record(8 * time.Millisecond)
+	b.ProcLatencies.Record(2 * time.Second)
+	s.Send(b)
+
+	require.Len(t, collector.requests, 1)
+	assert.Equal("Bearer token", collector.headers[0].Get("Authorization"))
+
+	resourceMetrics := collector.requests[0].ResourceMetrics
+	require.Len(t, resourceMetrics, 1)
+	assert.Equal("service.name", resourceMetrics[0].Resource.Attributes[0].Key)
+	assert.Equal("snowbridge", resourceMetrics[0].Resource.Attributes[0].Value.GetStringValue())
+
+	names := map[string]bool{}
+	for _, scope := range resourceMetrics[0].ScopeMetrics {
+		for _, m := range scope.Metrics {
+			names[m.Name] = true
+
+			switch m.Name {
+			case "target_success":
+				point := m.GetSum().DataPoints[0]
+				assert.Equal(int64(3), point.GetAsInt())
+				attrs := map[string]string{}
+				for _, a := range point.Attributes {
+					attrs[a.Key] = a.Value.GetStringValue()
+				}
+				assert.Equal(map[string]string{"app": "bridge", "env": "test"}, attrs)
+			case "processing_latency":
+				assert.Equal(uint64(2), m.GetHistogram().DataPoints[0].Count)
+			}
+		}
+	}
+	assert.True(names["target_success"])
+	assert.True(names["per_target_success"])
+	assert.True(names["circuit_breaker_state"])
+	assert.True(names["processing_latency"])
+
+	// Empty windows and disabled metrics don't record anything
+	assert.False(names["request_latency"])
+	assert.False(names["e2e_latency"])
+	assert.False(names["kinsumer_records_in_memory"])
+}
+
+func TestOTLPStatsReceiver_InvalidTags(t *testing.T) {
+	s, err := newOTLPStatsReceiver("http://localhost:4318/v1/metrics", nil, "snowbridge", `not json`, nil, time.Second, false, false)
+	assert.Nil(t, s)
+	assert.ErrorContains(t, err, "Failed to unmarshall tags to map")
+}
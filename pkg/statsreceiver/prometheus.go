@@ -0,0 +1,274 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package statsreceiver
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// PrometheusStatsReceiverConfig configures the Prometheus metrics receiver
+type PrometheusStatsReceiverConfig struct {
+	Address   string `hcl:"address,optional"`
+	Path      string `hcl:"path,optional"`
+	Namespace string `hcl:"namespace,optional"`
+	Tags      string `hcl:"tags,optional"`
+}
+
+// latencyBuckets are the histogram buckets (in seconds) used for every latency metric
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300, 900, 3600}
+
+// prometheusStatsReceiver exposes the buffered metrics on an HTTP endpoint for Prometheus to scrape
+type prometheusStatsReceiver struct {
+	server   *http.Server
+	listener net.Listener
+
+	targetSuccess        prometheus.Counter
+	targetFailed         prometheus.Counter
+	targetRequestCount   prometheus.Counter
+	messageFiltered      prometheus.Counter
+	failureTargetSuccess prometheus.Counter
+	failureTargetFailed  prometheus.Counter
+
+	perTargetSuccess      *prometheus.CounterVec
+	perTargetFailed       *prometheus.CounterVec
+	perTargetRequestCount *prometheus.CounterVec
+	breakerStateChanges   *prometheus.CounterVec
+	breakerState          *prometheus.GaugeVec
+
+	processingLatency prometheus.Histogram
+	messageLatency    prometheus.Histogram
+	transformLatency  prometheus.Histogram
+	filterLatency     prometheus.Histogram
+	requestLatency    prometheus.Histogram
+	e2eLatency        prometheus.Histogram
+
+	kinsumerRecordsInMemory      prometheus.Gauge
+	kinsumerRecordsInMemoryBytes prometheus.Gauge
+
+	enableE2ELatency            bool
+	enableKinsumerMemoryMetrics bool
+}
+
+// newPrometheusStatsReceiver registers the metrics and starts serving them on the given address and path
+func newPrometheusStatsReceiver(address string, path string, namespace string, tagsRaw string, tagsMapClient map[string]string, enableE2ELatency bool, enableKinsumerMemoryMetrics bool) (*prometheusStatsReceiver, error) {
+	tagsMap := map[string]string{}
+	err := json.Unmarshal([]byte(tagsRaw), &tagsMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshall tags to map")
+	}
+
+	labels := prometheus.Labels{}
+	for key, value := range tagsMap {
+		labels[key] = value
+	}
+	for key, value := range tagsMapClient {
+		labels[key] = value
+	}
+
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Namespace: namespace, Name: name, Help: help, ConstLabels: labels})
+	}
+	targetCounter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: namespace, Name: name, Help: help, ConstLabels: labels}, []string{"target"})
+	}
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Namespace: namespace, Name: name, Help: help, ConstLabels: labels})
+	}
+	histogram := func(name, help string) prometheus.Histogram {
+		return prometheus.NewHistogram(prometheus.HistogramOpts{Namespace: namespace, Name: name, Help: help, ConstLabels: labels, Buckets: latencyBuckets})
+	}
+
+	s := &prometheusStatsReceiver{
+		targetSuccess:        counter("target_success_total", "Messages successfully written to the target"),
+		targetFailed:         counter("target_failed_total", "Messages that failed to be written to the target"),
+		targetRequestCount:   counter("target_request_count_total", "Write requests made to the target"),
+		messageFiltered:      counter("message_filtered_total", "Messages dropped by a filter"),
+		failureTargetSuccess: counter("failure_target_success_total", "Invalid messages successfully written to the failure target"),
+		failureTargetFailed:  counter("failure_target_failed_total", "Invalid messages that failed to be written to the failure target"),
+
+		perTargetSuccess:      targetCounter("per_target_success_total", "Messages successfully written, by target"),
+		perTargetFailed:       targetCounter("per_target_failed_total", "Messages that failed to be written, by target"),
+		perTargetRequestCount: targetCounter("per_target_request_count_total", "Write requests made, by target"),
+		breakerStateChanges:   targetCounter("circuit_breaker_state_changes_total", "Circuit breaker state transitions, by target"),
+		breakerState: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{Namespace: namespace, Name: "circuit_breaker_state", Help: "Circuit breaker state (0 closed, 1 half-open, 2 open), by target", ConstLabels: labels},
+			[]string{"target"},
+		),
+
+		processingLatency: histogram("processing_latency_seconds", "Time from the message being pulled from the source to it being written"),
+		messageLatency:    histogram("message_latency_seconds", "Time from the message arriving at the source to it being written"),
+		transformLatency:  histogram("transform_latency_seconds", "Time spent transforming the message"),
+		filterLatency:     histogram("filter_latency_seconds", "Time from the message being pulled from the source to it being filtered"),
+		requestLatency:    histogram("request_latency_seconds", "Time spent on the target request"),
+		e2eLatency:        histogram("e2e_latency_seconds", "Time from the collector timestamp to the message being written"),
+
+		kinsumerRecordsInMemory:      gauge("kinsumer_records_in_memory", "Records currently held in memory by kinsumer"),
+		kinsumerRecordsInMemoryBytes: gauge("kinsumer_records_in_memory_bytes", "Bytes of records currently held in memory by kinsumer"),
+
+		enableE2ELatency:            enableE2ELatency,
+		enableKinsumerMemoryMetrics: enableKinsumerMemoryMetrics,
+	}
+
+	collectors := []prometheus.Collector{
+		s.targetSuccess, s.targetFailed, s.targetRequestCount, s.messageFiltered, s.failureTargetSuccess, s.failureTargetFailed,
+		s.perTargetSuccess, s.perTargetFailed, s.perTargetRequestCount, s.breakerStateChanges, s.breakerState,
+		s.processingLatency, s.messageLatency, s.transformLatency, s.filterLatency, s.requestLatency,
+	}
+	if enableE2ELatency {
+		collectors = append(collectors, s.e2eLatency)
+	}
+	if enableKinsumerMemoryMetrics {
+		collectors = append(collectors, s.kinsumerRecordsInMemory, s.kinsumerRecordsInMemoryBytes)
+	}
+
+	registry := prometheus.NewRegistry()
+	for _, c := range collectors {
+		if err := registry.Register(c); err != nil {
+			return nil, errors.Wrap(err, "Failed to register Prometheus metric")
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to listen on Prometheus metrics address")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	s.listener = listener
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Prometheus metrics server failed")
+		}
+	}()
+
+	return s, nil
+}
+
+// NewPrometheusReceiverWithTags closes over a given tags map and returns a function
+// that creates a PrometheusStatsReceiver given a PrometheusStatsReceiverConfig.
+func NewPrometheusReceiverWithTags(tags map[string]string, enableE2ELatency bool, enableKinsumerMemoryMetrics bool) func(c *PrometheusStatsReceiverConfig) (*prometheusStatsReceiver, error) {
+	return func(c *PrometheusStatsReceiverConfig) (*prometheusStatsReceiver, error) {
+		return newPrometheusStatsReceiver(
+			c.Address,
+			c.Path,
+			c.Namespace,
+			c.Tags,
+			tags,
+			enableE2ELatency,
+			enableKinsumerMemoryMetrics,
+		)
+	}
+}
+
+// The PrometheusStatsReceiverAdapter type is an adapter for functions to be used as
+// pluggable components for Prometheus Stats Receiver.
+// It implements the Pluggable interface.
+type PrometheusStatsReceiverAdapter func(i any) (any, error)
+
+// Create implements the ComponentCreator interface.
+func (f PrometheusStatsReceiverAdapter) Create(i any) (any, error) {
+	return f(i)
+}
+
+// ProvideDefault implements the ComponentConfigurable interface.
+func (f PrometheusStatsReceiverAdapter) ProvideDefault() (any, error) {
+	// Provide defaults for the optional parameters
+	// whose default is not their zero value.
+	cfg := &PrometheusStatsReceiverConfig{
+		Address:   ":9090",
+		Path:      "/metrics",
+		Namespace: "snowbridge",
+		Tags:      "{}",
+	}
+
+	return cfg, nil
+}
+
+// AdaptPrometheusStatsReceiverFunc returns a PrometheusStatsReceiverAdapter.
+func AdaptPrometheusStatsReceiverFunc(f func(c *PrometheusStatsReceiverConfig) (*prometheusStatsReceiver, error)) PrometheusStatsReceiverAdapter {
+	return func(i any) (any, error) {
+		cfg, ok := i.(*PrometheusStatsReceiverConfig)
+		if !ok {
+			return nil, errors.New("invalid input, expected PrometheusStatsReceiverConfig")
+		}
+
+		return f(cfg)
+	}
+}
+
+// Send adds the bufferred metrics to the values exposed on the scrape endpoint
+func (s *prometheusStatsReceiver) Send(b *models.ObserverBuffer) {
+	// overall
+	s.targetSuccess.Add(float64(b.MsgSent))
+	s.targetFailed.Add(float64(b.MsgFailed))
+	s.targetRequestCount.Add(float64(b.TargetResults))
+	s.messageFiltered.Add(float64(b.MsgFiltered))
+
+	// per target
+	for name, stats := range b.Targets {
+		s.perTargetSuccess.WithLabelValues(name).Add(float64(stats.MsgSent))
+		s.perTargetFailed.WithLabelValues(name).Add(float64(stats.MsgFailed))
+		s.perTargetRequestCount.WithLabelValues(name).Add(float64(stats.TargetResults))
+		s.breakerStateChanges.WithLabelValues(name).Add(float64(stats.BreakerStateChanges))
+	}
+	for name, state := range b.BreakerStates {
+		s.breakerState.WithLabelValues(name).Set(float64(state))
+	}
+
+	// unsendable
+	s.failureTargetSuccess.Add(float64(b.InvalidMsgSent))
+	s.failureTargetFailed.Add(float64(b.InvalidMsgFailed))
+
+	// latencies
+	observeLatency(s.processingLatency.Observe, &b.ProcLatencies)
+	observeLatency(s.messageLatency.Observe, &b.MsgLatencies)
+	observeLatency(s.transformLatency.Observe, &b.TransformLatencies)
+	observeLatency(s.filterLatency.Observe, &b.FilterLatencies)
+	observeLatency(s.requestLatency.Observe, &b.RequestLatencies)
+
+	if s.enableE2ELatency {
+		observeLatency(s.e2eLatency.Observe, &b.E2ELatencies)
+	}
+
+	// kinsumer metrics (only if enabled)
+	if s.enableKinsumerMemoryMetrics {
+		s.kinsumerRecordsInMemory.Set(float64(b.KinsumerRecordsInMemory))
+		s.kinsumerRecordsInMemoryBytes.Set(float64(b.KinsumerRecordsInMemoryBytes))
+	}
+}
+
+// observeLatency records every latency of the window's distribution
+func observeLatency(observe func(float64), h *models.LatencyHistogram) {
+	h.ForEach(func(latency time.Duration, count int64) bool {
+		for range count {
+			observe(latency.Seconds())
+		}
+		return true
+	})
+}
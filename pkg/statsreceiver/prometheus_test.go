@@ -0,0 +1,93 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package statsreceiver
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+func scrape(t *testing.T, s *prometheusStatsReceiver, path string) (int, string) {
+	t.Helper()
+	res, err := http.Get("http://" + s.listener.Addr().String() + path)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(body)
+}
+
+func TestPrometheusStatsReceiver_Send(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := newPrometheusStatsReceiver("127.0.0.1:0", "/metrics", "snowbridge", `{"env": "test"}`, map[string]string{"app": "bridge"}, false, true)
+	require.NoError(t, err)
+	defer s.server.Close()
+
+	b := &models.ObserverBuffer{
+		MsgSent:          3,
+		MsgFailed:        1,
+		MsgFiltered:      2,
+		TargetResults:    4,
+		InvalidMsgSent:   5,
+		InvalidMsgFailed: 6,
+		Targets: map[string]*models.TargetStats{
+			"primary": {MsgSent: 3, MsgFailed: 1, TargetResults: 4, BreakerStateChanges: 1},
+		},
+		BreakerStates:           map[string]int64{"primary": 2},
+		KinsumerRecordsInMemory: 7,
+	}
+	b.ProcLatencies.Record(8 * time.Millisecond)
+	b.ProcLatencies.Record(2 * time.Second)
+	s.Send(b)
+	s.Send(b)
+
+	status, body := scrape(t, s, "/metrics")
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, `snowbridge_target_success_total{app="bridge",env="test"} 6`)
+	assert.Contains(body, `snowbridge_target_failed_total{app="bridge",env="test"} 2`)
+	assert.Contains(body, `snowbridge_message_filtered_total{app="bridge",env="test"} 4`)
+	assert.Contains(body, `snowbridge_failure_target_success_total{app="bridge",env="test"} 10`)
+	assert.Contains(body, `snowbridge_failure_target_failed_total{app="bridge",env="test"} 12`)
+	assert.Contains(body, `snowbridge_per_target_success_total{app="bridge",env="test",target="primary"} 6`)
+	assert.Contains(body, `snowbridge_circuit_breaker_state{app="bridge",env="test",target="primary"} 2`)
+	assert.Contains(body, `snowbridge_processing_latency_seconds_count{app="bridge",env="test"} 4`)
+	assert.Contains(body, `snowbridge_processing_latency_seconds_bucket{app="bridge",env="test",le="0.01"} 2`)
+	assert.Contains(body, `snowbridge_kinsumer_records_in_memory{app="bridge",env="test"} 7`)
+
+	// Empty windows don't record latencies, and disabled metrics aren't exposed
+	assert.Contains(body, `snowbridge_request_latency_seconds_count{app="bridge",env="test"} 0`)
+	assert.NotContains(body, "snowbridge_e2e_latency_seconds")
+
+	status, _ = scrape(t, s, "/other")
+	assert.Equal(http.StatusNotFound, status)
+}
+
+func TestPrometheusStatsReceiver_InvalidTags(t *testing.T) {
+	assert := assert.New(t)
+
+	s, err := newPrometheusStatsReceiver("127.0.0.1:0", "/metrics", "snowbridge", `{"bad-label": "x"}`, nil, false, false)
+	assert.Nil(s)
+	assert.ErrorContains(err, "Failed to register Prometheus metric")
+
+	s, err = newPrometheusStatsReceiver("127.0.0.1:0", "/metrics", "snowbridge", `not json`, nil, false, false)
+	assert.Nil(s)
+	assert.ErrorContains(err, "Failed to unmarshall tags to map")
+}
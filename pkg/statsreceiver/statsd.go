@@ -125,6 +125,18 @@ func (s *statsDStatsReceiver) Send(b *models.ObserverBuffer) {
 	s.client.Incr("target_request_count", b.TargetResults)
 	s.client.Incr("message_filtered", b.MsgFiltered)
 
+	// per target
+	for name, stats := range b.Targets {
+		targetTag := statsd.StringTag("target", name)
+		s.client.Incr("per_target_success", stats.MsgSent, targetTag)
+		s.client.Incr("per_target_failed", stats.MsgFailed, targetTag)
+		s.client.Incr("per_target_request_count", stats.TargetResults, targetTag)
+		s.client.Incr("circuit_breaker_state_changes", stats.BreakerStateChanges, targetTag)
+	}
+	for name, state := range b.BreakerStates {
+		s.client.Gauge("circuit_breaker_state", state, statsd.StringTag("target", name))
+	}
+
 	// unsendable
 	s.client.Incr("failure_target_success", b.InvalidMsgSent)
 	s.client.Incr("failure_target_failed", b.InvalidMsgFailed)
@@ -132,22 +144,28 @@ func (s *statsDStatsReceiver) Send(b *models.ObserverBuffer) {
 	// latencies
 	s.client.PrecisionTiming("min_processing_latency", b.MinProcLatency)
 	s.client.PrecisionTiming("max_processing_latency", b.MaxProcLatency)
+	s.timingPercentiles("processing_latency", &b.ProcLatencies)
 
 	s.client.PrecisionTiming("min_message_latency", b.MinMsgLatency)
 	s.client.PrecisionTiming("max_message_latency", b.MaxMsgLatency)
+	s.timingPercentiles("message_latency", &b.MsgLatencies)
 
 	s.client.PrecisionTiming("min_transform_latency", b.MinTransformLatency)
 	s.client.PrecisionTiming("max_transform_latency", b.MaxTransformLatency)
+	s.timingPercentiles("transform_latency", &b.TransformLatencies)
 
 	s.client.PrecisionTiming("min_filter_latency", b.MinFilterLatency)
 	s.client.PrecisionTiming("max_filter_latency", b.MaxFilterLatency)
+	s.timingPercentiles("filter_latency", &b.FilterLatencies)
 
 	s.client.PrecisionTiming("min_request_latency", b.MinRequestLatency)
 	s.client.PrecisionTiming("max_request_latency", b.MaxRequestLatency)
+	s.timingPercentiles("request_latency", &b.RequestLatencies)
 
 	if s.enableE2ELatency {
 		s.client.PrecisionTiming("min_e2e_latency", b.MinE2ELatency)
 		s.client.PrecisionTiming("max_e2e_latency", b.MaxE2ELatency)
+		s.timingPercentiles("e2e_latency", &b.E2ELatencies)
 	}
 
 	// kinsumer metrics (only if enabled)
@@ -156,3 +174,10 @@ func (s *statsDStatsReceiver) Send(b *models.ObserverBuffer) {
 		s.client.Gauge("kinsumer_records_in_memory_bytes", b.KinsumerRecordsInMemoryBytes)
 	}
 }
+
+// timingPercentiles emits the p50, p95 and p99 of a latency
+func (s *statsDStatsReceiver) timingPercentiles(name string, h *models.LatencyHistogram) {
+	s.client.PrecisionTiming("p50_"+name, h.Quantile(0.5))
+	s.client.PrecisionTiming("p95_"+name, h.Quantile(0.95))
+	s.client.PrecisionTiming("p99_"+name, h.Quantile(0.99))
+}
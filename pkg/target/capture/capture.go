@@ -0,0 +1,133 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package capture
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+const SupportedTargetCapture = "capture"
+
+// CaptureTargetConfig contains configurable options for the capture target
+type CaptureTargetConfig struct {
+	BatchingConfig *targetiface.BatchingConfig `hcl:"batching,block"`
+}
+
+// CaptureTargetDriver keeps every message written to it in memory, for pipelines embedded in tests or other programs.
+// It is not available in config files.
+type CaptureTargetDriver struct {
+	BatchingConfig targetiface.BatchingConfig
+
+	mu       sync.Mutex
+	messages []*models.Message
+}
+
+// New returns a capture target driver initialised with the default configuration
+func New() *CaptureTargetDriver {
+	driver := &CaptureTargetDriver{}
+	driver.SetBatchingConfig(*driver.GetDefaultConfiguration().(*CaptureTargetConfig).BatchingConfig)
+	return driver
+}
+
+// GetDefaultConfiguration returns the default configuration for Capture target
+func (ct *CaptureTargetDriver) GetDefaultConfiguration() any {
+	return &CaptureTargetConfig{
+		BatchingConfig: &targetiface.BatchingConfig{
+			MaxBatchMessages:     500,
+			MaxBatchBytes:        100000000000,
+			MaxMessageBytes:      100000000000,
+			MaxConcurrentBatches: 5,
+			FlushPeriodMillis:    200,
+		},
+	}
+}
+
+func (ct *CaptureTargetDriver) SetBatchingConfig(batchingConfig targetiface.BatchingConfig) {
+	ct.BatchingConfig = batchingConfig
+}
+
+func (ct *CaptureTargetDriver) GetBatchingConfig() targetiface.BatchingConfig {
+	return ct.BatchingConfig
+}
+
+// InitFromConfig creates a Capture target from decoded configuration
+func (ct *CaptureTargetDriver) InitFromConfig(c any) error {
+	cfg, ok := c.(*CaptureTargetConfig)
+	if !ok {
+		return fmt.Errorf("invalid configuration type")
+	}
+
+	// Set the batching config
+	ct.SetBatchingConfig(*cfg.BatchingConfig)
+
+	return nil
+}
+
+// Batcher combines new data with current batch and returns batches ready to send, new current batch, and oversized messages
+func (ct *CaptureTargetDriver) Batcher(currentBatch targetiface.CurrentBatch, message *models.Message) (batchToSend []*models.Message, newCurrentBatch targetiface.CurrentBatch, oversized *models.Message) {
+	return targetiface.DefaultBatcher(currentBatch, message, ct.BatchingConfig)
+}
+
+// Write keeps the messages and acks them
+func (ct *CaptureTargetDriver) Write(messages []*models.Message) (*models.TargetWriteResult, error) {
+	now := time.Now().UTC()
+
+	ct.mu.Lock()
+	for _, msg := range messages {
+		msg.TimeRequestStarted = now
+		msg.TimeRequestFinished = now
+		ct.messages = append(ct.messages, msg)
+	}
+	ct.mu.Unlock()
+
+	for _, msg := range messages {
+		if msg.AckFunc != nil {
+			msg.AckFunc()
+		}
+	}
+
+	return models.NewTargetWriteResult(
+		messages,
+		nil,
+		nil,
+	), nil
+}
+
+// Messages returns the messages written so far, in the order they were written
+func (ct *CaptureTargetDriver) Messages() []*models.Message {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return append([]*models.Message(nil), ct.messages...)
+}
+
+// Data returns the data of the messages written so far, in the order they were written
+func (ct *CaptureTargetDriver) Data() []string {
+	messages := ct.Messages()
+	data := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		data = append(data, string(msg.Data))
+	}
+	return data
+}
+
+// Open does not do anything for this target
+func (ct *CaptureTargetDriver) Open() error {
+	return nil
+}
+
+// Close does not do anything for this target
+func (ct *CaptureTargetDriver) Close() {}
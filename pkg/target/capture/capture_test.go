@@ -0,0 +1,65 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package capture
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+	"github.com/snowplow/snowbridge/v5/pkg/testutil"
+)
+
+func TestCaptureTarget_WriteSuccess(t *testing.T) {
+	assert := assert.New(t)
+
+	target := New()
+	assert.Equal(500, target.GetBatchingConfig().MaxBatchMessages)
+
+	defer target.Close()
+	err := target.Open()
+	assert.Nil(err)
+
+	var ackOps int64
+	ackFunc := func() {
+		atomic.AddInt64(&ackOps, 1)
+	}
+
+	messages := testutil.GetTestMessages(2, "Hello World!", ackFunc)
+
+	writeRes, err := target.Write(messages[:1])
+	assert.Nil(err)
+	assert.Equal(1, len(writeRes.Sent))
+	writeRes, err = target.Write(messages[1:])
+	assert.Nil(err)
+	assert.Equal(1, len(writeRes.Sent))
+
+	// Check that Ack is called, and the messages are kept in order
+	assert.Equal(int64(2), ackOps)
+	assert.Equal(messages, target.Messages())
+	assert.Equal([]string{"Hello World!", "Hello World!"}, target.Data())
+}
+
+func TestCaptureTarget_Conformance(t *testing.T) {
+	var target *CaptureTargetDriver
+	testutil.RunTargetConformance(t, testutil.TargetConformance{
+		NewDriver: func(t *testing.T) targetiface.TargetDriver {
+			target = New()
+			return target
+		},
+		Received: func() []string {
+			return target.Data()
+		},
+	})
+}
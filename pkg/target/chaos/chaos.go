@@ -0,0 +1,240 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+const SupportedTargetChaos = "chaos"
+
+// ChaosTargetConfig configures the failures injected into the writes of the wrapped target.
+// Probabilities range from 0 to 1, and the probabilities of whole batch errors must not add up to more than 1.
+type ChaosTargetConfig struct {
+	// Target is the target to wrap, configured as a target of its own in a nested block
+	Target *config.TargetConfig `hcl:"target,block"`
+
+	// LatencyMs is added to a write with LatencyProbability
+	LatencyMs          int     `hcl:"latency_ms,optional"`
+	LatencyProbability float64 `hcl:"latency_probability,optional"`
+
+	// Whole batch errors, returned without writing to the wrapped target
+	TransientErrorProbability float64 `hcl:"transient_error_probability,optional"`
+	ThrottleErrorProbability  float64 `hcl:"throttle_error_probability,optional"`
+	SetupErrorProbability     float64 `hcl:"setup_error_probability,optional"`
+	FatalErrorProbability     float64 `hcl:"fatal_error_probability,optional"`
+
+	// PartialFailureProbability is the probability of failing about half of a batch with a transient error
+	PartialFailureProbability float64 `hcl:"partial_failure_probability,optional"`
+	// InvalidProbability is the probability of each message being returned as invalid
+	InvalidProbability float64 `hcl:"invalid_probability,optional"`
+
+	// Seed makes the injected failures reproducible, they differ on every run when 0
+	Seed int64 `hcl:"seed,optional"`
+
+	wrapped       targetiface.TargetDriver
+	wrappedConfig any
+}
+
+// WrappedTarget returns the nested target block, and the decoded configuration of the wrapped target
+func (c *ChaosTargetConfig) WrappedTarget() (*config.TargetConfig, any) {
+	return c.Target, c.wrappedConfig
+}
+
+// Wrap sets the driver of the wrapped target, along with the configuration it is initialised from
+func (c *ChaosTargetConfig) Wrap(driver targetiface.TargetDriver, cfg any) {
+	c.wrapped = driver
+	c.wrappedConfig = cfg
+}
+
+// ChaosTargetDriver injects failures into the writes of the target it wraps, to exercise retries and alerting
+type ChaosTargetDriver struct {
+	wrapped targetiface.TargetDriver
+	cfg     ChaosTargetConfig
+
+	mu     sync.Mutex
+	random *rand.Rand
+	log    *log.Entry
+}
+
+// GetDefaultConfiguration returns the default configuration for chaos target, which injects nothing
+func (ct *ChaosTargetDriver) GetDefaultConfiguration() any {
+	return &ChaosTargetConfig{}
+}
+
+// GetBatchingConfig returns the batching config of the wrapped target
+func (ct *ChaosTargetDriver) GetBatchingConfig() targetiface.BatchingConfig {
+	if ct.wrapped == nil {
+		return targetiface.BatchingConfig{}
+	}
+	return ct.wrapped.GetBatchingConfig()
+}
+
+// InitFromConfig initialises the wrapped target, and the chaos target from ChaosTargetConfig
+func (ct *ChaosTargetDriver) InitFromConfig(c any) error {
+	cfg, ok := c.(*ChaosTargetConfig)
+	if !ok {
+		return fmt.Errorf("invalid configuration type")
+	}
+	if cfg.wrapped == nil {
+		return errors.New("chaos target requires a target block to wrap")
+	}
+	if err := validate(cfg); err != nil {
+		return err
+	}
+	if err := cfg.wrapped.InitFromConfig(cfg.wrappedConfig); err != nil {
+		return err
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	ct.wrapped = cfg.wrapped
+	ct.cfg = *cfg
+	ct.random = rand.New(rand.NewSource(seed))
+	ct.log = log.WithFields(log.Fields{"target": SupportedTargetChaos})
+
+	ct.log.Warn("Chaos target is injecting failures, it must not be used in production")
+	return nil
+}
+
+// validate checks every probability is within range, and the whole batch errors can happen together
+func validate(cfg *ChaosTargetConfig) error {
+	for _, probability := range []struct {
+		name  string
+		value float64
+	}{
+		{"latency_probability", cfg.LatencyProbability},
+		{"transient_error_probability", cfg.TransientErrorProbability},
+		{"throttle_error_probability", cfg.ThrottleErrorProbability},
+		{"setup_error_probability", cfg.SetupErrorProbability},
+		{"fatal_error_probability", cfg.FatalErrorProbability},
+		{"partial_failure_probability", cfg.PartialFailureProbability},
+		{"invalid_probability", cfg.InvalidProbability},
+	} {
+		if probability.value < 0 || probability.value > 1 {
+			return fmt.Errorf("chaos target %s %v must be between 0 and 1", probability.name, probability.value)
+		}
+	}
+	if sum := cfg.TransientErrorProbability + cfg.ThrottleErrorProbability + cfg.SetupErrorProbability + cfg.FatalErrorProbability; sum > 1 {
+		return fmt.Errorf("chaos target error probabilities add up to %v, must not be more than 1", sum)
+	}
+	if cfg.LatencyMs < 0 {
+		return fmt.Errorf("chaos target latency_ms %d must not be negative", cfg.LatencyMs)
+	}
+	return nil
+}
+
+// Batcher batches as the wrapped target does
+func (ct *ChaosTargetDriver) Batcher(currentBatch targetiface.CurrentBatch, message *models.Message) (batchToSend []*models.Message, newCurrentBatch targetiface.CurrentBatch, oversized *models.Message) {
+	return ct.wrapped.Batcher(currentBatch, message)
+}
+
+// Write injects latency, whole batch errors, invalid messages and partial failures,
+// and writes the messages left to the wrapped target
+func (ct *ChaosTargetDriver) Write(messages []*models.Message) (*models.TargetWriteResult, error) {
+	if ct.chance(ct.cfg.LatencyProbability) {
+		time.Sleep(time.Duration(ct.cfg.LatencyMs) * time.Millisecond)
+	}
+
+	if err := ct.batchError(); err != nil {
+		ct.log.WithError(err).Debugf("Injected error into a batch of %d messages", len(messages))
+		return models.NewTargetWriteResult(nil, messages, nil), err
+	}
+
+	var toWrite, failed, invalid []*models.Message
+	partial := ct.chance(ct.cfg.PartialFailureProbability)
+	for _, msg := range messages {
+		switch {
+		case ct.chance(ct.cfg.InvalidProbability):
+			msg.SetError(errors.New("chaos target injected an invalid message"))
+			invalid = append(invalid, msg)
+		case partial && ct.chance(0.5):
+			failed = append(failed, msg)
+		default:
+			toWrite = append(toWrite, msg)
+		}
+	}
+
+	result := models.NewTargetWriteResult(nil, failed, invalid)
+	var err error
+	if len(toWrite) > 0 {
+		var wrappedResult *models.TargetWriteResult
+		wrappedResult, err = ct.wrapped.Write(toWrite)
+		if wrappedResult != nil {
+			result.Sent = wrappedResult.Sent
+			result.Failed = append(result.Failed, wrappedResult.Failed...)
+			result.Invalid = append(result.Invalid, wrappedResult.Invalid...)
+		}
+	}
+
+	// The error of the wrapped target takes precedence, as it tells how to retry its own failures
+	if err == nil && len(failed) > 0 {
+		err = fmt.Errorf("chaos target injected a partial failure of %d out of %d messages", len(failed), len(messages))
+	}
+	return result, err
+}
+
+// batchError returns the error to fail the whole batch with, if any
+func (ct *ChaosTargetDriver) batchError() error {
+	ct.mu.Lock()
+	draw := ct.random.Float64()
+	ct.mu.Unlock()
+
+	cumulative := 0.0
+	for _, injected := range []struct {
+		probability float64
+		err         error
+	}{
+		{ct.cfg.FatalErrorProbability, models.FatalWriteError{Err: errors.New("chaos target injected a fatal error")}},
+		{ct.cfg.SetupErrorProbability, models.SetupWriteError{Err: errors.New("chaos target injected a setup error")}},
+		{ct.cfg.ThrottleErrorProbability, models.ThrottleWriteError{Err: errors.New("chaos target injected a throttle error")}},
+		{ct.cfg.TransientErrorProbability, errors.New("chaos target injected a transient error")},
+	} {
+		cumulative += injected.probability
+		if draw < cumulative {
+			return injected.err
+		}
+	}
+	return nil
+}
+
+// chance returns true with probability p
+func (ct *ChaosTargetDriver) chance(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	return ct.random.Float64() < p
+}
+
+// Open opens the wrapped target
+func (ct *ChaosTargetDriver) Open() error {
+	return ct.wrapped.Open()
+}
+
+// Close closes the wrapped target
+func (ct *ChaosTargetDriver) Close() {
+	ct.wrapped.Close()
+}
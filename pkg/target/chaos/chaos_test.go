@@ -0,0 +1,183 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package chaos
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/capture"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+	"github.com/snowplow/snowbridge/v5/pkg/testutil"
+)
+
+// newChaosTarget returns a chaos target initialised from cfg, wrapping a capture target
+func newChaosTarget(t *testing.T, cfg ChaosTargetConfig) (*ChaosTargetDriver, *capture.CaptureTargetDriver) {
+	t.Helper()
+
+	wrapped := capture.New()
+	cfg.Wrap(wrapped, wrapped.GetDefaultConfiguration())
+
+	target := &ChaosTargetDriver{}
+	require.NoError(t, target.InitFromConfig(&cfg))
+	return target, wrapped
+}
+
+func TestChaosTarget_Conformance(t *testing.T) {
+	var wrapped *capture.CaptureTargetDriver
+	chaosTarget := func(cfg ChaosTargetConfig) func(t *testing.T) targetiface.TargetDriver {
+		return func(t *testing.T) targetiface.TargetDriver {
+			var target *ChaosTargetDriver
+			target, wrapped = newChaosTarget(t, cfg)
+			return target
+		}
+	}
+
+	testutil.RunTargetConformance(t, testutil.TargetConformance{
+		NewDriver: chaosTarget(ChaosTargetConfig{}),
+		Received: func() []string {
+			return wrapped.Data()
+		},
+		Failures: []testutil.TargetFailure{
+			{Name: "transient", NewDriver: chaosTarget(ChaosTargetConfig{TransientErrorProbability: 1}), Class: testutil.ErrorClassTransient},
+			{Name: "throttle", NewDriver: chaosTarget(ChaosTargetConfig{ThrottleErrorProbability: 1}), Class: testutil.ErrorClassThrottle},
+			{Name: "setup", NewDriver: chaosTarget(ChaosTargetConfig{SetupErrorProbability: 1}), Class: testutil.ErrorClassSetup},
+			{Name: "fatal", NewDriver: chaosTarget(ChaosTargetConfig{FatalErrorProbability: 1}), Class: testutil.ErrorClassFatal},
+			{Name: "invalid", NewDriver: chaosTarget(ChaosTargetConfig{InvalidProbability: 1}), Class: testutil.ErrorClassInvalid},
+		},
+	})
+}
+
+func TestChaosTarget_BatchError(t *testing.T) {
+	assert := assert.New(t)
+
+	target, wrapped := newChaosTarget(t, ChaosTargetConfig{ThrottleErrorProbability: 1})
+
+	messages := testutil.GetTestMessages(5, "Hello World!", nil)
+	writeRes, err := target.Write(messages)
+	assert.EqualError(err, "chaos target injected a throttle error")
+	assert.Equal(messages, writeRes.Failed)
+	assert.Empty(writeRes.Sent)
+
+	// A failed batch never reaches the wrapped target
+	assert.Empty(wrapped.Messages())
+}
+
+func TestChaosTarget_PartialFailure(t *testing.T) {
+	assert := assert.New(t)
+
+	target, wrapped := newChaosTarget(t, ChaosTargetConfig{PartialFailureProbability: 1, Seed: 1})
+
+	var ackOps int64
+	messages := testutil.GetTestMessages(100, "Hello World!", func() { atomic.AddInt64(&ackOps, 1) })
+	writeRes, err := target.Write(messages)
+	assert.ErrorContains(err, "chaos target injected a partial failure of")
+
+	// Messages are either sent to the wrapped target, or returned for a retry
+	assert.NotEmpty(writeRes.Sent)
+	assert.NotEmpty(writeRes.Failed)
+	assert.Empty(writeRes.Invalid)
+	assert.Len(messages, len(writeRes.Sent)+len(writeRes.Failed))
+	assert.Equal(writeRes.Sent, wrapped.Messages())
+	assert.Equal(int64(len(writeRes.Sent)), ackOps)
+}
+
+func TestChaosTarget_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	target, wrapped := newChaosTarget(t, ChaosTargetConfig{InvalidProbability: 0.5, Seed: 1})
+
+	messages := testutil.GetTestMessages(100, "Hello World!", nil)
+	writeRes, err := target.Write(messages)
+	assert.NoError(err)
+	assert.NotEmpty(writeRes.Sent)
+	assert.NotEmpty(writeRes.Invalid)
+	assert.Len(messages, len(writeRes.Sent)+len(writeRes.Invalid))
+	assert.Equal(writeRes.Sent, wrapped.Messages())
+	for _, msg := range writeRes.Invalid {
+		assert.EqualError(msg.GetError(), "chaos target injected an invalid message")
+	}
+}
+
+func TestChaosTarget_Latency(t *testing.T) {
+	target, _ := newChaosTarget(t, ChaosTargetConfig{LatencyMs: 100, LatencyProbability: 1})
+
+	started := time.Now()
+	_, err := target.Write(testutil.GetTestMessages(1, "Hello World!", nil))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(started), 100*time.Millisecond)
+}
+
+func TestChaosTarget_Seed(t *testing.T) {
+	// The same seed injects the same failures
+	outcomes := func(seed int64) []bool {
+		target, _ := newChaosTarget(t, ChaosTargetConfig{TransientErrorProbability: 0.5, Seed: seed})
+
+		var failed []bool
+		for range 20 {
+			_, err := target.Write(testutil.GetTestMessages(1, "Hello World!", nil))
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	assert.Equal(t, outcomes(42), outcomes(42))
+	assert.NotEqual(t, outcomes(42), outcomes(43))
+}
+
+func TestChaosTarget_InvalidConfiguration(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        ChaosTargetConfig
+		ExpectedError string
+	}{
+		{
+			Name:          "probability above 1",
+			Config:        ChaosTargetConfig{TransientErrorProbability: 1.5},
+			ExpectedError: "chaos target transient_error_probability 1.5 must be between 0 and 1",
+		},
+		{
+			Name:          "negative probability",
+			Config:        ChaosTargetConfig{PartialFailureProbability: -0.1},
+			ExpectedError: "chaos target partial_failure_probability -0.1 must be between 0 and 1",
+		},
+		{
+			Name:          "error probabilities above 1",
+			Config:        ChaosTargetConfig{TransientErrorProbability: 0.5, FatalErrorProbability: 0.75},
+			ExpectedError: "chaos target error probabilities add up to 1.25, must not be more than 1",
+		},
+		{
+			Name:          "negative latency",
+			Config:        ChaosTargetConfig{LatencyMs: -1},
+			ExpectedError: "chaos target latency_ms -1 must not be negative",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			wrapped := capture.New()
+			tt.Config.Wrap(wrapped, wrapped.GetDefaultConfiguration())
+
+			target := &ChaosTargetDriver{}
+			assert.EqualError(t, target.InitFromConfig(&tt.Config), tt.ExpectedError)
+		})
+	}
+
+	t.Run("no wrapped target", func(t *testing.T) {
+		target := &ChaosTargetDriver{}
+		assert.EqualError(t, target.InitFromConfig(&ChaosTargetConfig{}), "chaos target requires a target block to wrap")
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 
 	"github.com/snowplow/snowbridge/v5/pkg/common"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
 	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 
 	"golang.org/x/oauth2"
@@ -44,9 +46,9 @@ type HTTPTargetConfig struct {
 	URL                    string            `hcl:"url"`
 	RequestTimeoutInMillis int               `hcl:"request_timeout_in_millis,optional"`
 	ContentType            string            `hcl:"content_type,optional"`
-	Headers                map[string]string `hcl:"headers,optional"`
+	Headers                map[string]string `hcl:"headers,optional" sensitive:"true"`
 	BasicAuthUsername      string            `hcl:"basic_auth_username,optional"`
-	BasicAuthPassword      string            `hcl:"basic_auth_password,optional"`
+	BasicAuthPassword      string            `hcl:"basic_auth_password,optional" sensitive:"true"`
 
 	EnableTLS      bool   `hcl:"enable_tls,optional"`
 	CertFile       string `hcl:"cert_file,optional"`
@@ -56,8 +58,8 @@ type HTTPTargetConfig struct {
 	DynamicHeaders bool   `hcl:"dynamic_headers,optional"`
 
 	OAuth2ClientID     string `hcl:"oauth2_client_id,optional"`
-	OAuth2ClientSecret string `hcl:"oauth2_client_secret,optional"`
-	OAuth2RefreshToken string `hcl:"oauth2_refresh_token,optional"`
+	OAuth2ClientSecret string `hcl:"oauth2_client_secret,optional" sensitive:"true"`
+	OAuth2RefreshToken string `hcl:"oauth2_refresh_token,optional" sensitive:"true"`
 	OAuth2TokenURL     string `hcl:"oauth2_token_url,optional"`
 
 	TemplateFile     string         `hcl:"template_file,optional"`
@@ -66,6 +68,17 @@ type HTTPTargetConfig struct {
 
 	IncludeTimingHeaders       bool `hcl:"include_timing_headers,optional"`
 	RejectionThresholdInMillis int  `hcl:"rejection_threshold_in_millis,optional"`
+
+	RetryAfterHeader   string `hcl:"retry_after_header,optional"`
+	RetryAfterBodyPath string `hcl:"retry_after_body_path,optional"`
+
+	// secrets tracks the OAuth2 credentials set from a secret, so that they can be resolved again when rotated
+	secrets *secrets.Tracker
+}
+
+// SetSecretTracker implements secrets.Refreshable
+func (c *HTTPTargetConfig) SetSecretTracker(t *secrets.Tracker) {
+	c.secrets = t
 }
 
 // ResponseRules is part of HTTP target configuration. It provides rules how HTTP responses should be handled. Response can be categorized as 'invalid' (bad data), as setup error or (if none of the rules matches) as a transient error.
@@ -103,6 +116,7 @@ type response struct {
 	Status       int
 	StringStatus string
 	Body         string
+	Header       http.Header
 }
 
 // HTTPTargetDriver holds a new client for writing messages to HTTP endpoints
@@ -125,6 +139,9 @@ type HTTPTargetDriver struct {
 
 	includeTimingHeaders bool
 	rejectionThreshold   int
+
+	retryAfterHeader   string   // Extra header carrying a server-suggested retry delay
+	retryAfterBodyPath []string // Path to a server-suggested retry delay in a JSON response body
 }
 
 func addHeadersToRequest(request *http.Request, headers map[string]string, dynamicHeaders map[string]string) {
@@ -198,18 +215,27 @@ func ParseRequestTemplate(templateContent string) (*template.Template, error) {
 	return parsedTemplate, nil
 }
 
-func createHTTPClient(oAuth2ClientID string, oAuth2ClientSecret string, oAuth2TokenURL string, oAuth2RefreshToken string, transport *http.Transport) *http.Client {
+// createHTTPClient takes the client secret and refresh token as the config fields holding them,
+// so that they can be resolved again by tracker if they come from a secret
+func createHTTPClient(oAuth2ClientID string, oAuth2ClientSecret *string, oAuth2TokenURL string, oAuth2RefreshToken *string, tracker *secrets.Tracker, transport *http.Transport) *http.Client {
 	if oAuth2ClientID != "" {
 		oauth2Config := oauth2.Config{
 			ClientID:     oAuth2ClientID,
-			ClientSecret: oAuth2ClientSecret,
+			ClientSecret: *oAuth2ClientSecret,
 			Endpoint: oauth2.Endpoint{
 				TokenURL: oAuth2TokenURL,
 			},
 		}
 
-		token := &oauth2.Token{RefreshToken: oAuth2RefreshToken}
-		return oauth2Config.Client(context.Background(), token)
+		tokenSource := &secretRefreshingTokenSource{
+			config:            oauth2Config,
+			secrets:           tracker,
+			clientSecretField: oAuth2ClientSecret,
+			refreshToken:      *oAuth2RefreshToken,
+			refreshTokenField: oAuth2RefreshToken,
+			source:            oauth2Config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: *oAuth2RefreshToken}),
+		}
+		return oauth2.NewClient(context.Background(), tokenSource)
 	}
 
 	return &http.Client{
@@ -217,6 +243,54 @@ func createHTTPClient(oAuth2ClientID string, oAuth2ClientSecret string, oAuth2To
 	}
 }
 
+// secretRefreshingTokenSource gets OAuth2 tokens, and resolves the client secret and refresh token again
+// when the token endpoint rejects them, so that credentials rotated in a secret manager are picked up.
+type secretRefreshingTokenSource struct {
+	mu                sync.Mutex
+	config            oauth2.Config
+	secrets           *secrets.Tracker
+	clientSecretField *string
+	refreshToken      string
+	refreshTokenField *string
+	source            oauth2.TokenSource
+}
+
+// Token returns a valid token, refreshing it when it has expired
+func (s *secretRefreshingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.source.Token()
+	if !credentialsRejected(err) {
+		return token, err
+	}
+
+	clientSecret, secretErr := s.secrets.Refresh(s.clientSecretField)
+	refreshToken, tokenErr := s.secrets.Refresh(s.refreshTokenField)
+	if secretErr != nil || tokenErr != nil {
+		return nil, errors.Join(err, secretErr, tokenErr)
+	}
+	if clientSecret == s.config.ClientSecret && refreshToken == s.refreshToken {
+		return nil, err
+	}
+
+	log.Info("OAuth2 credentials were rejected, retrying with refreshed secrets")
+	s.config.ClientSecret = clientSecret
+	s.refreshToken = refreshToken
+	s.source = s.config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: refreshToken})
+	return s.source.Token()
+}
+
+// credentialsRejected reports whether err is the token endpoint rejecting the client secret or refresh token,
+// rather than e.g. the endpoint being unavailable
+func credentialsRejected(err error) bool {
+	var retrieveErr *oauth2.RetrieveError
+	if !errors.As(err, &retrieveErr) || retrieveErr.Response == nil {
+		return false
+	}
+	return retrieveErr.Response.StatusCode == http.StatusBadRequest || retrieveErr.Response.StatusCode == http.StatusUnauthorized
+}
+
 // GetDefaultConfiguration returns the default configuration for the HTTP target
 func (ht *HTTPTargetDriver) GetDefaultConfiguration() any {
 	return &HTTPTargetConfig{
@@ -278,7 +352,7 @@ func (ht *HTTPTargetDriver) InitFromConfig(cfg any) error {
 		transport.TLSClientConfig = tlsConfig
 	}
 
-	client := createHTTPClient(c.OAuth2ClientID, c.OAuth2ClientSecret, c.OAuth2TokenURL, c.OAuth2RefreshToken, transport)
+	client := createHTTPClient(c.OAuth2ClientID, &c.OAuth2ClientSecret, c.OAuth2TokenURL, &c.OAuth2RefreshToken, c.secrets, transport)
 	client.Timeout = time.Duration(c.RequestTimeoutInMillis) * time.Millisecond
 
 	var responseRules2XX *ResponseRules
@@ -317,6 +391,10 @@ func (ht *HTTPTargetDriver) InitFromConfig(cfg any) error {
 	ht.metadataSafeMode = c.MetadataSafeMode
 	ht.includeTimingHeaders = c.IncludeTimingHeaders
 	ht.rejectionThreshold = c.RejectionThresholdInMillis
+	ht.retryAfterHeader = c.RetryAfterHeader
+	if c.RetryAfterBodyPath != "" {
+		ht.retryAfterBodyPath = strings.Split(c.RetryAfterBodyPath, ".")
+	}
 
 	return nil
 }
@@ -359,7 +437,7 @@ func (ht *HTTPTargetDriver) Write(messages []*models.Message) (*models.TargetWri
 	request, err := http.NewRequest("POST", ht.httpURL, bytes.NewBuffer(reqBody))
 
 	if err != nil {
-		return models.NewTargetWriteResult(nil, nil, nil), models.FatalWriteError{Err: err}
+		return models.NewTargetWriteResult(nil, goodMsgs, invalid), models.FatalWriteError{Err: err}
 	}
 
 	request.Header.Add("Content-Type", ht.contentType)                        // Add content type
@@ -417,11 +495,11 @@ func (ht *HTTPTargetDriver) Write(messages []*models.Message) (*models.TargetWri
 				return models.NewTargetWriteResult(nil, failed, invalid), wrappedErr
 			}
 
-			res := response{Body: string(responseBody), Status: resp.StatusCode, StringStatus: resp.Status}
+			res := response{Body: string(responseBody), Status: resp.StatusCode, StringStatus: resp.Status, Header: resp.Header}
 			if rule := findMatchingRule(res, ht.responseRules2XX); rule != nil {
 				newInvalid, failed, wrappedError := applyMatchedRule(res, rule, goodMsgs, ht.metadataSafeMode)
 				invalid = append(invalid, newInvalid...)
-				return models.NewTargetWriteResult(nil, failed, invalid), wrappedError
+				return models.NewTargetWriteResult(nil, failed, invalid), ht.withRetryAfter(wrappedError, res, time.Now())
 			}
 		}
 
@@ -446,12 +524,96 @@ func (ht *HTTPTargetDriver) Write(messages []*models.Message) (*models.TargetWri
 		return models.NewTargetWriteResult(nil, failed, invalid), wrappedErr
 	}
 
-	response := response{Body: string(responseBody), Status: resp.StatusCode, StringStatus: resp.Status}
+	response := response{Body: string(responseBody), Status: resp.StatusCode, StringStatus: resp.Status, Header: resp.Header}
 
 	newInvalid, failed, wrappedErr := handleResponseRules(response, ht.responseRules, goodMsgs, ht.metadataSafeMode)
 	invalid = append(invalid, newInvalid...)
 
-	return models.NewTargetWriteResult(nil, failed, invalid), wrappedErr
+	return models.NewTargetWriteResult(nil, failed, invalid), ht.withRetryAfter(wrappedErr, response, time.Now())
+}
+
+// withRetryAfter attaches the retry delay suggested by the response to a throttle error.
+// Other errors are returned unchanged.
+func (ht *HTTPTargetDriver) withRetryAfter(err error, res response, now time.Time) error {
+	throttleErr, isThrottle := err.(models.ThrottleWriteError)
+	if !isThrottle {
+		return err
+	}
+	throttleErr.RetryAfter = ht.retryAfter(res, now)
+	return throttleErr
+}
+
+// retryAfter returns the retry delay suggested by the response, or zero if it has none.
+// The configured body path and header take precedence over the standard Retry-After and X-RateLimit-Reset headers.
+func (ht *HTTPTargetDriver) retryAfter(res response, now time.Time) time.Duration {
+	if len(ht.retryAfterBodyPath) > 0 {
+		if value, ok := jsonValueAtPath(res.Body, ht.retryAfterBodyPath); ok {
+			if delay, ok := parseRetryAfter(value, now); ok {
+				return delay
+			}
+		}
+	}
+
+	headers := []string{"Retry-After", "X-RateLimit-Reset"}
+	if ht.retryAfterHeader != "" {
+		headers = append([]string{ht.retryAfterHeader}, headers...)
+	}
+	for _, header := range headers {
+		if value := res.Header.Get(header); value != "" {
+			if delay, ok := parseRetryAfter(value, now); ok {
+				return delay
+			}
+		}
+	}
+	return 0
+}
+
+// unixTimestampThreshold separates delays in seconds from unix timestamps in rate-limit headers:
+// a number of seconds this large would be over 30 years.
+const unixTimestampThreshold = 1e9
+
+// parseRetryAfter parses a retry hint given either as a number of seconds, a unix timestamp or an HTTP date.
+// Hints in the past resolve to a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		if seconds >= unixTimestampThreshold {
+			return max(time.UnixMilli(int64(seconds*1000)).Sub(now), 0), true
+		}
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// jsonValueAtPath looks up a number or string in a JSON body by a path of object keys
+func jsonValueAtPath(body string, path []string) (string, bool) {
+	var current any
+	if err := json.Unmarshal([]byte(body), &current); err != nil {
+		return "", false
+	}
+	for _, key := range path {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if current, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case string:
+		return value, true
+	}
+	return "", false
 }
 
 func findMatchingRule(res response, rules *ResponseRules) *Rule {
@@ -0,0 +1,82 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+	"github.com/snowplow/snowbridge/v5/pkg/testutil"
+)
+
+func TestHTTP_Conformance(t *testing.T) {
+	var results [][]byte
+	var headers http.Header
+	healthy := createTestServer(&results)
+	defer healthy.Close()
+
+	newDriver := func(t *testing.T, url string) targetiface.TargetDriver {
+		driver := &HTTPTargetDriver{}
+		config := driver.GetDefaultConfiguration().(*HTTPTargetConfig)
+		config.URL = url
+		config.ResponseRules = &ResponseRules{
+			Rules: []Rule{
+				{Type: ResponseRuleTypeInvalid, MatchingHTTPCodes: []int{400}},
+				{Type: ResponseRuleTypeSetup, MatchingHTTPCodes: []int{401}},
+				{Type: ResponseRuleTypeFatal, MatchingHTTPCodes: []int{403}},
+				{Type: ResponseRuleTypeThrottle, MatchingHTTPCodes: []int{429}},
+			},
+		}
+		if err := driver.InitFromConfig(config); err != nil {
+			t.Fatal(err)
+		}
+		return driver
+	}
+	failure := func(name string, responseCode int, class testutil.ErrorClass) testutil.TargetFailure {
+		return testutil.TargetFailure{
+			Name: name,
+			NewDriver: func(t *testing.T) targetiface.TargetDriver {
+				var failed [][]byte
+				server := createTestServerWithResponseCode(&failed, &headers, responseCode, "", 0)
+				t.Cleanup(server.Close)
+				return newDriver(t, server.URL)
+			},
+			Class: class,
+		}
+	}
+
+	testutil.RunTargetConformance(t, testutil.TargetConformance{
+		NewDriver: func(t *testing.T) targetiface.TargetDriver {
+			return newDriver(t, healthy.URL)
+		},
+		Payload: func(id string) string {
+			return fmt.Sprintf(`{"id":"%s"}`, id)
+		},
+		// Writes are synchronous, so the stand-in has recorded the request by the time the write returns
+		Received: func() []string {
+			var received []string
+			for _, body := range results {
+				received = append(received, string(body))
+			}
+			return received
+		},
+		Failures: []testutil.TargetFailure{
+			failure("Transient", 500, testutil.ErrorClassTransient),
+			failure("Invalid", 400, testutil.ErrorClassInvalid),
+			failure("Setup", 401, testutil.ErrorClassSetup),
+			failure("Fatal", 403, testutil.ErrorClassFatal),
+			failure("Throttle", 429, testutil.ErrorClassThrottle),
+		},
+	})
+}
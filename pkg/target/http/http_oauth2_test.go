@@ -12,15 +12,20 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sirupsen/logrus"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/secrets"
 	"github.com/snowplow/snowbridge/v5/pkg/testutil"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 // that's what we configure in our target
@@ -118,6 +123,15 @@ func TestHTTP_OAuth2_CallTargetWithoutToken(t *testing.T) {
 }
 
 func runTest(t *testing.T, inputClientID string, inputClientSecret string, inputRefreshToken string) (*models.TargetWriteResult, error) {
+	return runTestWithConfig(t, func(config *HTTPTargetConfig) {
+		config.OAuth2ClientID = inputClientID
+		config.OAuth2ClientSecret = inputClientSecret
+		config.OAuth2RefreshToken = inputRefreshToken
+	})
+}
+
+// runTestWithConfig writes a message to a target whose OAuth2 settings are set by configure
+func runTestWithConfig(t *testing.T, configure func(config *HTTPTargetConfig)) (*models.TargetWriteResult, error) {
 	tokenServer := tokenServer()
 	server := targetServer()
 	defer tokenServer.Close()
@@ -126,10 +140,8 @@ func runTest(t *testing.T, inputClientID string, inputClientSecret string, input
 	driver := &HTTPTargetDriver{}
 	config := driver.GetDefaultConfiguration().(*HTTPTargetConfig)
 	config.URL = server.URL
-	config.OAuth2ClientID = inputClientID
-	config.OAuth2ClientSecret = inputClientSecret
-	config.OAuth2RefreshToken = inputRefreshToken
 	config.OAuth2TokenURL = tokenServer.URL
+	configure(config)
 	err := driver.InitFromConfig(config)
 
 	if err != nil {
@@ -139,3 +151,43 @@ func runTest(t *testing.T, inputClientID string, inputClientSecret string, input
 	message := testutil.GetTestMessages(1, `{"message": "Hello Server!!"}`, func() {})
 	return driver.Write(message)
 }
+
+func TestHTTP_OAuth2_RefreshesRotatedSecret(t *testing.T) {
+	assert := assert.New(t)
+
+	secretPath := filepath.Join(t.TempDir(), "client_secret")
+	assert.Nil(os.WriteFile(secretPath, []byte("CLIENT_SECRET_BEFORE_ROTATION\n"), 0600))
+
+	writeResult, err := runTestWithConfig(t, func(config *HTTPTargetConfig) {
+		ref := "file://" + secretPath
+		clientSecret, err := secrets.Resolve(ref)
+		assert.Nil(err)
+		config.OAuth2ClientID = validClientID
+		config.OAuth2ClientSecret = clientSecret
+		config.OAuth2RefreshToken = validRefreshToken
+		tracker := secrets.NewTracker()
+		tracker.Track(&config.OAuth2ClientSecret, ref)
+		config.SetSecretTracker(tracker)
+
+		// The secret is rotated after the target is configured
+		assert.Nil(os.WriteFile(secretPath, []byte(validClientSecret+"\n"), 0600))
+	})
+
+	assert.Nil(err)
+	assert.Equal(1, len(writeResult.Sent))
+	assert.Equal(0, len(writeResult.Failed))
+}
+
+func TestCredentialsRejected(t *testing.T) {
+	assert := assert.New(t)
+
+	retrieveErr := func(statusCode int) error {
+		return &oauth2.RetrieveError{Response: &http.Response{StatusCode: statusCode}}
+	}
+	assert.True(credentialsRejected(retrieveErr(http.StatusBadRequest)))
+	assert.True(credentialsRejected(retrieveErr(http.StatusUnauthorized)))
+	assert.False(credentialsRejected(retrieveErr(http.StatusServiceUnavailable)))
+	assert.False(credentialsRejected(&oauth2.RetrieveError{}))
+	assert.False(credentialsRejected(errors.New("connection refused")))
+	assert.False(credentialsRejected(nil))
+}
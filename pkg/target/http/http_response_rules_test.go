@@ -12,8 +12,12 @@
 package http
 
 import (
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
+	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -406,3 +410,79 @@ func TestHTTP_HandleResponseRules_ErrorDetails(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTP_ParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name     string
+		Value    string
+		Expected time.Duration
+		Ok       bool
+	}{
+		{Name: "seconds", Value: "30", Expected: 30 * time.Second, Ok: true},
+		{Name: "fractional seconds", Value: " 1.5 ", Expected: 1500 * time.Millisecond, Ok: true},
+		{Name: "unix timestamp", Value: "1714564845", Expected: 45 * time.Second, Ok: true},
+		{Name: "unix timestamp in the past", Value: "1714564000", Expected: 0, Ok: true},
+		{Name: "HTTP date", Value: "Wed, 01 May 2024 12:01:00 GMT", Expected: time.Minute, Ok: true},
+		{Name: "negative", Value: "-5", Ok: false},
+		{Name: "garbage", Value: "soon", Ok: false},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			delay, ok := parseRetryAfter(tt.Value, now)
+			assert.Equal(t, tt.Ok, ok)
+			assert.Equal(t, tt.Expected, delay)
+		})
+	}
+}
+
+func TestHTTP_RetryAfter_Precedence(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	header := http.Header{}
+	header.Set("Retry-After", "10")
+	header.Set("X-RateLimit-Reset", "20")
+	header.Set("X-Backoff", "30")
+	res := response{Status: 429, Body: `{"error": {"retry_after": 40}}`, Header: header}
+
+	driver := &HTTPTargetDriver{}
+	assert.Equal(10*time.Second, driver.retryAfter(res, now))
+
+	driver.retryAfterHeader = "X-Backoff"
+	assert.Equal(30*time.Second, driver.retryAfter(res, now))
+
+	driver.retryAfterBodyPath = []string{"error", "retry_after"}
+	assert.Equal(40*time.Second, driver.retryAfter(res, now))
+
+	// A path which doesn't resolve falls back to the headers
+	driver.retryAfterBodyPath = []string{"error", "missing"}
+	assert.Equal(30*time.Second, driver.retryAfter(res, now))
+
+	header.Del("X-Backoff")
+	header.Del("Retry-After")
+	assert.Equal(20*time.Second, driver.retryAfter(res, now))
+
+	header.Del("X-RateLimit-Reset")
+	assert.Equal(time.Duration(0), driver.retryAfter(res, now))
+}
+
+func TestHTTP_WithRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+
+	header := http.Header{}
+	header.Set("Retry-After", "5")
+	res := response{Status: 429, Header: header}
+	driver := &HTTPTargetDriver{}
+
+	throttled := driver.withRetryAfter(models.ThrottleWriteError{Err: errors.New("slow down")}, res, now)
+	assert.Equal(models.ThrottleWriteError{Err: errors.New("slow down"), RetryAfter: 5 * time.Second}, throttled)
+
+	// Other errors don't carry hints
+	setup := models.SetupWriteError{Err: errors.New("bad credentials")}
+	assert.Equal(setup, driver.withRetryAfter(setup, res, now))
+	assert.Nil(driver.withRetryAfter(nil, res, now))
+}
@@ -895,6 +895,34 @@ func TestHTTP_Write_2xx_ThrottleRule_BodyMatch(t *testing.T) {
 	assert.Equal(0, len(writeResult.Invalid))
 }
 
+func TestHTTP_Write_ThrottleRetryAfter(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	driver := &HTTPTargetDriver{}
+	config := driver.GetDefaultConfiguration().(*HTTPTargetConfig)
+	config.URL = server.URL
+	config.ResponseRules = &ResponseRules{
+		Rules: []Rule{{Type: ResponseRuleTypeThrottle, MatchingHTTPCodes: []int{429}}},
+	}
+	if err := driver.InitFromConfig(config); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := driver.Write([]*models.Message{{Data: []byte(`{"attribute": "value"}`)}})
+
+	throttleErr, isThrottle := err.(models.ThrottleWriteError)
+	if !assert.True(isThrottle) {
+		t.FailNow()
+	}
+	assert.Equal(7*time.Second, throttleErr.RetryAfter)
+}
+
 func TestHTTP_Write_2xx_BodyReadError(t *testing.T) {
 	assert := assert.New(t)
 
@@ -39,7 +39,7 @@ type KafkaConfig struct {
 	Idempotent     bool                        `hcl:"idempotent,optional"`
 	EnableSASL     bool                        `hcl:"enable_sasl,optional"`
 	SASLUsername   string                      `hcl:"sasl_username,optional"`
-	SASLPassword   string                      `hcl:"sasl_password,optional"`
+	SASLPassword   string                      `hcl:"sasl_password,optional" sensitive:"true"`
 	SASLAlgorithm  string                      `hcl:"sasl_algorithm,optional"`
 	SASLVersion    int16                       `hcl:"sasl_version,optional"`
 	EnableTLS      bool                        `hcl:"enable_tls,optional"`
@@ -17,6 +17,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 	"github.com/snowplow/snowbridge/v5/pkg/testutil"
 )
 
@@ -52,3 +53,15 @@ func TestSilentTarget_WriteSuccess(t *testing.T) {
 	assert.Equal(1, len(writeRes.Sent))
 	assert.Equal(0, len(writeRes.Failed))
 }
+
+func TestSilentTarget_Conformance(t *testing.T) {
+	testutil.RunTargetConformance(t, testutil.TargetConformance{
+		NewDriver: func(t *testing.T) targetiface.TargetDriver {
+			target := &SilentTargetDriver{}
+			if err := target.InitFromConfig(target.GetDefaultConfiguration()); err != nil {
+				t.Fatal(err)
+			}
+			return target
+		},
+	})
+}
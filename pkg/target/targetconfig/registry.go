@@ -0,0 +1,80 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetconfig
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/snowplow/snowbridge/v5/pkg/target/chaos"
+	"github.com/snowplow/snowbridge/v5/pkg/target/eventhub"
+	"github.com/snowplow/snowbridge/v5/pkg/target/http"
+	"github.com/snowplow/snowbridge/v5/pkg/target/kafka"
+	"github.com/snowplow/snowbridge/v5/pkg/target/kinesis"
+	"github.com/snowplow/snowbridge/v5/pkg/target/pubsub"
+	"github.com/snowplow/snowbridge/v5/pkg/target/silent"
+	"github.com/snowplow/snowbridge/v5/pkg/target/sqs"
+	"github.com/snowplow/snowbridge/v5/pkg/target/stdout"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// NewTargetDriver returns a new, uninitialised, driver of a target
+type NewTargetDriver func() targetiface.TargetDriver
+
+var (
+	registryMu sync.RWMutex
+
+	// registry holds the targets which can be configured in a `use` block, by name
+	registry = map[string]NewTargetDriver{
+		stdout.SupportedTargetStdout:     func() targetiface.TargetDriver { return &stdout.StdoutTargetDriver{} },
+		kafka.SupportedTargetKafka:       func() targetiface.TargetDriver { return &kafka.KafkaTargetDriver{} },
+		pubsub.SupportedTargetPubsub:     func() targetiface.TargetDriver { return &pubsub.PubSubTargetDriver{} },
+		kinesis.SupportedTargetKinesis:   func() targetiface.TargetDriver { return &kinesis.KinesisTargetDriver{} },
+		http.SupportedTargetHTTP:         func() targetiface.TargetDriver { return &http.HTTPTargetDriver{} },
+		sqs.SupportedTargetSQS:           func() targetiface.TargetDriver { return &sqs.SQSTargetDriver{} },
+		eventhub.SupportedTargetEventHub: func() targetiface.TargetDriver { return &eventhub.EventHubTargetDriver{} },
+		silent.SupportedTargetSilent:     func() targetiface.TargetDriver { return &silent.SilentTargetDriver{} },
+		chaos.SupportedTargetChaos:       func() targetiface.TargetDriver { return &chaos.ChaosTargetDriver{} },
+	}
+)
+
+// RegisterTarget makes a target available under name, so that it can be configured in a `use` block like the built in ones.
+// The block is decoded onto the configuration returned by GetDefaultConfiguration of a new driver,
+// which must hold a *targetiface.BatchingConfig, and the driver is then initialised from it.
+// Targets are registered before the config is read, usually from an init function.
+func RegisterTarget(name string, newDriver NewTargetDriver) error {
+	if name == "" {
+		return errors.New("target name must not be empty")
+	}
+	if newDriver == nil {
+		return fmt.Errorf("target %q must not be nil", name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		return fmt.Errorf("target %q is already registered", name)
+	}
+	registry[name] = newDriver
+	return nil
+}
+
+// lookupTarget returns the function creating drivers of the target registered under name
+func lookupTarget(name string) (NewTargetDriver, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	newDriver, ok := registry[name]
+	return newDriver, ok
+}
@@ -0,0 +1,208 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetconfig
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	config "github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/target/silent"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// customTargetConfig configures customTargetDriver
+type customTargetConfig struct {
+	BatchingConfig *targetiface.BatchingConfig `hcl:"batching,block"`
+	Endpoint       string                      `hcl:"endpoint"`
+}
+
+// customTargetDriver stands in for a target implemented outside of snowbridge
+type customTargetDriver struct {
+	silent.SilentTargetDriver
+	Endpoint string
+}
+
+func (d *customTargetDriver) GetDefaultConfiguration() any {
+	return &customTargetConfig{BatchingConfig: &targetiface.BatchingConfig{
+		MaxBatchMessages:     10,
+		MaxBatchBytes:        1000,
+		MaxMessageBytes:      1000,
+		MaxConcurrentBatches: 1,
+		FlushPeriodMillis:    100,
+	}}
+}
+
+func (d *customTargetDriver) InitFromConfig(c any) error {
+	cfg, ok := c.(*customTargetConfig)
+	if !ok {
+		return fmt.Errorf("invalid configuration type")
+	}
+	d.Endpoint = cfg.Endpoint
+	d.SetBatchingConfig(*cfg.BatchingConfig)
+	return nil
+}
+
+// noBatchingTargetDriver has a configuration without the required batching configuration
+type noBatchingTargetDriver struct {
+	customTargetDriver
+}
+
+func (d *noBatchingTargetDriver) GetDefaultConfiguration() any {
+	return &struct {
+		Endpoint string `hcl:"endpoint"`
+	}{}
+}
+
+// registerTestTarget registers a target for the duration of a test
+func registerTestTarget(t *testing.T, name string, newDriver NewTargetDriver) {
+	require.NoError(t, RegisterTarget(name, newDriver))
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		delete(registry, name)
+	})
+}
+
+func TestRegisterTarget(t *testing.T) {
+	assert := assert.New(t)
+	registerTestTarget(t, "custom", func() targetiface.TargetDriver { return &customTargetDriver{} })
+
+	c, err := config.NewHclConfig([]byte(`
+target {
+  use "custom" {
+    endpoint = "https://example.com"
+    batching {
+      max_batch_messages = 5
+    }
+  }
+}
+`), "test.hcl")
+	require.NoError(t, err)
+
+	target, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	require.NoError(t, err)
+	defer target.Ticker.Stop()
+
+	driver, ok := target.TargetDriver.(*customTargetDriver)
+	if assert.True(ok) {
+		assert.Equal("https://example.com", driver.Endpoint)
+	}
+	assert.Equal("custom", target.Name)
+	assert.Equal(5, target.GetBatchingConfig().MaxBatchMessages)
+	assert.Equal(1000, target.GetBatchingConfig().MaxBatchBytes)
+
+	assert.NoError(ValidateTargets(c.Data.Targets, c.Decoder))
+	assert.Contains(TargetDefaults(), "custom")
+}
+
+func TestRegisterTarget_Invalid(t *testing.T) {
+	assert := assert.New(t)
+	newDriver := func() targetiface.TargetDriver { return &customTargetDriver{} }
+
+	assert.EqualError(RegisterTarget("", newDriver), "target name must not be empty")
+	assert.EqualError(RegisterTarget("custom", nil), `target "custom" must not be nil`)
+	assert.EqualError(RegisterTarget(silent.SupportedTargetSilent, newDriver), `target "silent" is already registered`)
+}
+
+func TestRegisterTarget_NoBatchingConfig(t *testing.T) {
+	registerTestTarget(t, "no_batching", func() targetiface.TargetDriver { return &noBatchingTargetDriver{} })
+
+	c, err := config.NewHclConfig([]byte(`
+target {
+  use "no_batching" {
+    endpoint = "https://example.com"
+  }
+}
+`), "test.hcl")
+	require.NoError(t, err)
+
+	_, err = GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.EqualError(t, err, "no_batching target: configuration has no batching configuration")
+}
+
+// trackedTargetDriver records whether it was initialised and closed, and fails to initialise for the endpoint "fail"
+type trackedTargetDriver struct {
+	customTargetDriver
+	initialised, closed *int
+}
+
+func (d *trackedTargetDriver) InitFromConfig(c any) error {
+	if err := d.customTargetDriver.InitFromConfig(c); err != nil {
+		return err
+	}
+	if d.Endpoint == "fail" {
+		return fmt.Errorf("failed to connect")
+	}
+	*d.initialised++
+	return nil
+}
+
+func (d *trackedTargetDriver) Close() {
+	*d.closed++
+}
+
+func TestGetTargets_ReleasesTargetsOnError(t *testing.T) {
+	assert := assert.New(t)
+	var initialised, closed int
+	registerTestTarget(t, "tracked", func() targetiface.TargetDriver {
+		return &trackedTargetDriver{initialised: &initialised, closed: &closed}
+	})
+
+	getTargets := func(src string) error {
+		c, err := config.NewHclConfig([]byte(src), "test.hcl")
+		require.NoError(t, err)
+		targets, err := GetTargets(c.Data.Targets, c.Decoder)
+		assert.Nil(targets)
+		return err
+	}
+
+	// Duplicate names are rejected before any target is initialised
+	err := getTargets(`
+target {
+  use "tracked" {
+    endpoint = "a"
+  }
+}
+
+target {
+  use "tracked" {
+    endpoint = "b"
+  }
+}
+`)
+	assert.EqualError(err, `duplicate target name "tracked": set a unique 'name' for each target block`)
+	assert.Zero(initialised)
+
+	// Targets created before one fails are closed
+	err = getTargets(`
+target {
+  name = "first"
+  use "tracked" {
+    endpoint = "a"
+  }
+}
+
+target {
+  name = "second"
+  use "tracked" {
+    endpoint = "fail"
+  }
+}
+`)
+	assert.EqualError(err, "failed to connect")
+	assert.Equal(1, initialised)
+	assert.Equal(1, closed)
+}
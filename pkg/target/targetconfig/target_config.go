@@ -12,188 +12,246 @@
 package targetconfig
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
 	config "github.com/snowplow/snowbridge/v5/config"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
-	"github.com/snowplow/snowbridge/v5/pkg/target/eventhub"
-	"github.com/snowplow/snowbridge/v5/pkg/target/http"
-	"github.com/snowplow/snowbridge/v5/pkg/target/kafka"
-	"github.com/snowplow/snowbridge/v5/pkg/target/kinesis"
-	"github.com/snowplow/snowbridge/v5/pkg/target/pubsub"
-	"github.com/snowplow/snowbridge/v5/pkg/target/silent"
-	"github.com/snowplow/snowbridge/v5/pkg/target/sqs"
-	"github.com/snowplow/snowbridge/v5/pkg/target/stdout"
 	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 )
 
-// GetTarget creates and returns the target that is configured.
-func GetTarget(targetCfg *config.TargetConfig, decoder config.Decoder) (*targetiface.Target, error) {
-	useTarget := targetCfg.Target
-	decoderOpts := &config.DecoderOptions{
-		Input: useTarget.Body,
+// GetTargets creates and returns all the good targets that are configured.
+// Target names must be unique, as they identify each target in logs and metrics.
+func GetTargets(targetCfgs []*config.TargetConfig, decoder config.Decoder) ([]*targetiface.Target, error) {
+	if len(targetCfgs) == 0 {
+		return nil, fmt.Errorf("at least one target must be configured")
 	}
 
-	var driver targetiface.TargetDriver
-	var err error
-
-	switch useTarget.Name {
-	case stdout.SupportedTargetStdout:
-		driver = &stdout.StdoutTargetDriver{}
-
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*stdout.StdoutTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
+	// Names are checked before any target is created, so that nothing is connected to for a config which can't run
+	seen := make(map[string]bool, len(targetCfgs))
+	for _, targetCfg := range targetCfgs {
+		name := targetName(targetCfg)
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate target name %q: set a unique 'name' for each target block", name)
 		}
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
-		err = driver.InitFromConfig(cfg)
+		seen[name] = true
+	}
+
+	targets := make([]*targetiface.Target, 0, len(targetCfgs))
+	for _, targetCfg := range targetCfgs {
+		target, err := GetTarget(targetCfg, decoder)
 		if err != nil {
+			// Release the targets already created, which may hold clients and connections
+			for _, target := range targets {
+				target.Ticker.Stop()
+				target.Close()
+			}
 			return nil, err
 		}
-	case kafka.SupportedTargetKafka:
-		driver = &kafka.KafkaTargetDriver{}
+		targets = append(targets, target)
+	}
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*kafka.KafkaConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+	return targets, nil
+}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+// GetTarget creates and returns the target that is configured.
+func GetTarget(targetCfg *config.TargetConfig, decoder config.Decoder) (*targetiface.Target, error) {
+	driver, cfg, _, err := decodeTarget(targetCfg, decoder)
+	if err != nil {
+		return nil, err
+	}
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
-		}
-	case pubsub.SupportedTargetPubsub:
-		driver = &pubsub.PubSubTargetDriver{}
+	if err := driver.InitFromConfig(cfg); err != nil {
+		return nil, err
+	}
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*pubsub.PubSubTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+	target, err := newTarget(targetName(targetCfg), targetCfg.Target.Name, driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	return target, nil
+}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+// NewTarget wraps a driver which was already initialised in a Target, with the state for batching its writes.
+// The name identifies the target in logs and metrics.
+func NewTarget(name string, driver targetiface.TargetDriver) (*targetiface.Target, error) {
+	return newTarget(name, name, driver)
+}
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
-		}
-	case kinesis.SupportedTargetKinesis:
-		driver = &kinesis.KinesisTargetDriver{}
+// newTarget wraps an initialised driver of the given target type in a Target
+func newTarget(name string, targetType string, driver targetiface.TargetDriver) (*targetiface.Target, error) {
+	batchingConfig := driver.GetBatchingConfig()
+	if err := validateBatchingConfig(targetType, batchingConfig); err != nil {
+		return nil, err
+	}
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*kinesis.KinesisTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+	var limiter *targetiface.ConcurrencyLimiter
+	if batchingConfig.AdaptiveConcurrency {
+		limiter = targetiface.NewConcurrencyLimiter(max(batchingConfig.MinConcurrentBatches, 1), batchingConfig.MaxConcurrentBatches)
+	}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+	tickerPeriod := time.Duration(batchingConfig.FlushPeriodMillis) * time.Millisecond
+	ticker := time.NewTicker(tickerPeriod)
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
-		}
-	case http.SupportedTargetHTTP:
-		driver = &http.HTTPTargetDriver{}
+	// Wrap driver in Target with batching configuration
+	return &targetiface.Target{
+		TargetDriver: driver,
+		Name:         name,
+		CurrentBatch: targetiface.CurrentBatch{Messages: []*models.Message{}, DataBytes: 0},
+		WaitGroup:    &sync.WaitGroup{},
+		Throttle:     make(chan struct{}, batchingConfig.MaxConcurrentBatches),
+		Limiter:      limiter,
+		RateLimiter:  targetiface.NewRateLimiter(batchingConfig.RateLimit),
+		Lanes:        targetiface.NewLanes(batchingConfig.OrderedLanes),
+		Ticker:       ticker,
+		TickerPeriod: tickerPeriod,
+	}, nil
+}
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*http.HTTPTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+// ValidateTargets validates all the good targets that are configured, as GetTargets would create them
+func ValidateTargets(targetCfgs []*config.TargetConfig, decoder config.Decoder) error {
+	if len(targetCfgs) == 0 {
+		return fmt.Errorf("at least one target must be configured")
+	}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
+	seen := make(map[string]bool, len(targetCfgs))
+	for _, targetCfg := range targetCfgs {
+		if err := ValidateTarget(targetCfg, decoder); err != nil {
+			return err
 		}
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
+		name := targetName(targetCfg)
+		if seen[name] {
+			return fmt.Errorf("duplicate target name %q: set a unique 'name' for each target block", name)
 		}
-	case sqs.SupportedTargetSQS:
-		driver = &sqs.SQSTargetDriver{}
+		seen[name] = true
+	}
+	return nil
+}
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*sqs.SQSTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+// ValidateTarget decodes the configuration of a target and checks its batching configuration,
+// without initialising the target, so nothing is connected to.
+func ValidateTarget(targetCfg *config.TargetConfig, decoder config.Decoder) error {
+	_, _, batchingConfig, err := decodeTarget(targetCfg, decoder)
+	if err != nil {
+		return err
+	}
+	return validateBatchingConfig(targetCfg.Target.Name, *batchingConfig)
+}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+// DecodeTargetConfig returns the decoded configuration of a target, with its defaults filled in
+func DecodeTargetConfig(targetCfg *config.TargetConfig, decoder config.Decoder) (any, error) {
+	_, cfg, _, err := decodeTarget(targetCfg, decoder)
+	return cfg, err
+}
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
-		}
-	case eventhub.SupportedTargetEventHub:
-		driver = &eventhub.EventHubTargetDriver{}
+// TargetDefaults returns the default configuration of every target, including registered ones, by name
+func TargetDefaults() map[string]any {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*eventhub.EventHubConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+	defaults := make(map[string]any, len(registry))
+	for name, newDriver := range registry {
+		defaults[name] = newDriver().GetDefaultConfiguration()
+	}
+	return defaults
+}
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+// targetName returns the name identifying a target, which defaults to its type
+func targetName(targetCfg *config.TargetConfig) string {
+	if targetCfg.Name != "" {
+		return targetCfg.Name
+	}
+	return targetCfg.Target.Name
+}
 
-		err = driver.InitFromConfig(cfg)
-		if err != nil {
-			return nil, err
-		}
-	case silent.SupportedTargetSilent:
-		driver = &silent.SilentTargetDriver{}
+// decodeTarget returns the driver for the configured target along with its decoded configuration, and the batching configuration within it
+func decodeTarget(targetCfg *config.TargetConfig, decoder config.Decoder) (targetiface.TargetDriver, any, *targetiface.BatchingConfig, error) {
+	useTarget := targetCfg.Target
 
-		c := driver.GetDefaultConfiguration()
-		cfg, ok := c.(*silent.SilentTargetConfig)
-		if !ok {
-			return nil, fmt.Errorf("invalid configuration type")
-		}
+	newDriver, ok := lookupTarget(useTarget.Name)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unknown target: %s", useTarget.Name)
+	}
+	driver := newDriver()
 
-		if err := decoder.Decode(decoderOpts, cfg); err != nil {
-			return nil, err
-		}
+	cfg := driver.GetDefaultConfiguration()
+	decoderOpts := &config.DecoderOptions{
+		Input: useTarget.Body,
+	}
+	if err := decoder.Decode(decoderOpts, cfg); err != nil {
+		return nil, nil, nil, err
+	}
 
-		err = driver.InitFromConfig(cfg)
+	// A target wrapping another one batches as the wrapped target, which is decoded from its nested target block
+	if wrapper, ok := cfg.(wrapperConfig); ok {
+		wrappedCfg, _ := wrapper.WrappedTarget()
+		if wrappedCfg == nil || wrappedCfg.Target == nil {
+			return nil, nil, nil, fmt.Errorf("%s target requires a target block with a use block to wrap", useTarget.Name)
+		}
+		wrappedDriver, wrappedConfig, batchingConfig, err := decodeTarget(wrappedCfg, decoder)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, fmt.Errorf("%s target: %w", useTarget.Name, err)
 		}
-	default:
-		return nil, fmt.Errorf("unknown target: %s", useTarget.Name)
+		wrapper.Wrap(wrappedDriver, wrappedConfig)
+		return driver, cfg, batchingConfig, nil
 	}
 
-	batchingConfig := driver.GetBatchingConfig()
+	batchingConfig, err := batchingConfigOf(cfg)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s target: %w", useTarget.Name, err)
+	}
+	return driver, cfg, batchingConfig, nil
+}
+
+// wrapperConfig is the configuration of a target wrapping another one, which is configured in a nested target block
+type wrapperConfig interface {
+	config.WrappingConfig
+	Wrap(driver targetiface.TargetDriver, cfg any)
+}
+
+// batchingConfigOf returns the batching configuration every target configuration must hold
+func batchingConfigOf(cfg any) (*targetiface.BatchingConfig, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid configuration type %T, expected a pointer to a struct", cfg)
+	}
 
+	v = v.Elem()
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		if batchingConfig, ok := v.Field(i).Interface().(*targetiface.BatchingConfig); ok && batchingConfig != nil {
+			return batchingConfig, nil
+		}
+	}
+	return nil, errors.New("configuration has no batching configuration")
+}
+
+// validateBatchingConfig checks that the batching configuration of a target is consistent
+func validateBatchingConfig(targetType string, batchingConfig targetiface.BatchingConfig) error {
 	if batchingConfig.MaxMessageBytes > batchingConfig.MaxBatchBytes {
-		return nil, fmt.Errorf("%s target has invalid batching configuration: max_message_bytes (%d) must not be greater than max_batch_bytes (%d)", useTarget.Name, batchingConfig.MaxMessageBytes, batchingConfig.MaxBatchBytes)
+		return fmt.Errorf("%s target has invalid batching configuration: max_message_bytes (%d) must not be greater than max_batch_bytes (%d)", targetType, batchingConfig.MaxMessageBytes, batchingConfig.MaxBatchBytes)
 	}
 
-	tickerPeriod := time.Duration(batchingConfig.FlushPeriodMillis) * time.Millisecond
-	ticker := time.NewTicker(tickerPeriod)
+	if batchingConfig.AdaptiveConcurrency {
+		minConcurrency := max(batchingConfig.MinConcurrentBatches, 1)
+		if minConcurrency > batchingConfig.MaxConcurrentBatches {
+			return fmt.Errorf("%s target has invalid batching configuration: min_concurrent_batches (%d) must not be greater than max_concurrent_batches (%d)", targetType, minConcurrency, batchingConfig.MaxConcurrentBatches)
+		}
+	}
 
-	// Wrap driver in Target with batching configuration
-	return &targetiface.Target{
-		TargetDriver: driver,
-		CurrentBatch: targetiface.CurrentBatch{Messages: []*models.Message{}, DataBytes: 0},
-		WaitGroup:    &sync.WaitGroup{},
-		Throttle:     make(chan struct{}, batchingConfig.MaxConcurrentBatches),
-		Ticker:       ticker,
-		TickerPeriod: tickerPeriod,
-	}, nil
+	if rl := batchingConfig.RateLimit; rl != nil && (rl.MessagesPerSecond < 0 || rl.BytesPerSecond < 0) {
+		return fmt.Errorf("%s target has invalid batching configuration: rate_limit values must not be negative", targetType)
+	}
+
+	if batchingConfig.OrderedLanes < 0 {
+		return fmt.Errorf("%s target has invalid batching configuration: ordered_lanes must not be negative", targetType)
+	}
+	return nil
 }
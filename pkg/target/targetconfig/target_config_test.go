@@ -27,6 +27,7 @@ import (
 	httpTarget "github.com/snowplow/snowbridge/v5/pkg/target/http"
 	"github.com/snowplow/snowbridge/v5/pkg/target/kafka"
 	"github.com/snowplow/snowbridge/v5/pkg/target/stdout"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
 	"github.com/snowplow/snowbridge/v5/pkg/testutil"
 )
 
@@ -45,7 +46,7 @@ func TestGetTarget_Stdout(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -115,7 +116,7 @@ func TestGetTarget_Kafka(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -178,7 +179,7 @@ func TestGetTarget_Kinesis(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -220,7 +221,7 @@ func TestGetTarget_BatchingOverrides(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -266,7 +267,7 @@ func TestGetTarget_InvalidBatching(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.Nil(tar)
@@ -276,6 +277,108 @@ func TestGetTarget_InvalidBatching(t *testing.T) {
 	}
 }
 
+func TestGetTarget_AdaptiveConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					max_concurrent_batches = 8
+					adaptive_concurrency = true
+					min_concurrent_batches = 2
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(err)
+	if assert.NotNil(tar) && assert.NotNil(tar.Limiter) {
+		defer tar.Ticker.Stop()
+		// Concurrency starts at the minimum and grows from there
+		assert.Equal(2, tar.Limiter.Limit())
+		assert.Equal(8, cap(tar.Throttle))
+	}
+}
+
+func TestGetTarget_AdaptiveConcurrency_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					max_concurrent_batches = 2
+					adaptive_concurrency = true
+					min_concurrent_batches = 3
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(tar)
+	assert.EqualError(err, "stdout target has invalid batching configuration: min_concurrent_batches (3) must not be greater than max_concurrent_batches (2)")
+}
+
+func TestGetTarget_RateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					rate_limit {
+						messages_per_second = 100
+						bytes_per_second = 10000
+					}
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(err)
+	if assert.NotNil(tar) {
+		defer tar.Ticker.Stop()
+		assert.NotNil(tar.RateLimiter)
+		assert.Equal(&targetiface.RateLimitConfig{MessagesPerSecond: 100, BytesPerSecond: 10000}, tar.GetBatchingConfig().RateLimit)
+	}
+}
+
+func TestGetTarget_RateLimit_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					rate_limit {
+						bytes_per_second = -1
+					}
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(tar)
+	assert.EqualError(err, "stdout target has invalid batching configuration: rate_limit values must not be negative")
+}
+
 func TestGetTarget_PubSub(t *testing.T) {
 	assert := assert.New(t)
 
@@ -313,7 +416,7 @@ func TestGetTarget_PubSub(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid pubsub config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -362,7 +465,7 @@ func TestGetTarget_PubSub_DefaultBatching(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid pubsub config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -406,7 +509,7 @@ func TestGetTarget_HTTP(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid HTTP config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	if err != nil {
@@ -461,7 +564,7 @@ func TestGetTarget_SQS(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid SQS config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -500,7 +603,7 @@ func TestGetTarget_EventHub(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -534,7 +637,7 @@ func TestGetTarget_Silent(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with valid stdout config
-	tar, err := GetTarget(c.Data.Target, c.Decoder)
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns a valid target and no error
 	assert.NotNil(tar)
@@ -553,6 +656,86 @@ func TestGetTarget_Silent(t *testing.T) {
 	assert.Equal(200, batchingConfig.FlushPeriodMillis)
 }
 
+func TestGetTarget_Chaos(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "chaos" {
+				transient_error_probability = 0.1
+
+				target {
+					use "stdout" {
+						batching {
+							max_batch_messages = 10
+						}
+					}
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(err)
+	if assert.NotNil(tar) {
+		assert.Equal("chaos", tar.Name)
+
+		// The chaos target batches as the target it wraps
+		batchingConfig := tar.GetBatchingConfig()
+		assert.Equal(10, batchingConfig.MaxBatchMessages)
+		assert.Equal(1048576, batchingConfig.MaxBatchBytes)
+	}
+}
+
+func TestGetTarget_Chaos_Invalid(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        string
+		ExpectedError string
+	}{
+		{
+			Name:          "no wrapped target",
+			Config:        `use "chaos" {}`,
+			ExpectedError: "chaos target requires a target block with a use block to wrap",
+		},
+		{
+			Name: "unknown wrapped target",
+			Config: `use "chaos" {
+				target {
+					use "fakeHCL" {}
+				}
+			}`,
+			ExpectedError: "chaos target: unknown target: fakeHCL",
+		},
+		{
+			Name: "invalid probability",
+			Config: `use "chaos" {
+				invalid_probability = 2
+				target {
+					use "stdout" {}
+				}
+			}`,
+			ExpectedError: "chaos target invalid_probability 2 must be between 0 and 1",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			c, err := config.NewHclConfig(fmt.Appendf(nil, "target {\n%s\n}", tt.Config), "test.hcl")
+			assert.NoError(t, err)
+
+			tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+			assert.Nil(t, tar)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.ExpectedError)
+			}
+		})
+	}
+}
+
 func TestGetTarget_InvalidTarget(t *testing.T) {
 	assert := assert.New(t)
 
@@ -568,7 +751,7 @@ func TestGetTarget_InvalidTarget(t *testing.T) {
 	assert.NotNil(c)
 
 	// Call GetTarget with invalid config
-	target, err := GetTarget(c.Data.Target, c.Decoder)
+	target, err := GetTarget(c.Data.Targets[0], c.Decoder)
 
 	// Assert that it returns nil target and non-nil error
 	assert.Nil(target)
@@ -578,3 +761,189 @@ func TestGetTarget_InvalidTarget(t *testing.T) {
 		assert.Contains(err.Error(), "fakeHCL")
 	}
 }
+
+func TestGetTargets(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {}
+		}
+
+		target {
+			name = "audit"
+			use "stdout" {}
+		}
+
+		target {
+			use "silent" {}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	targets, err := GetTargets(c.Data.Targets, c.Decoder)
+	assert.Nil(err)
+	if assert.Equal(3, len(targets)) {
+		// Name defaults to the name of the target used
+		assert.Equal("stdout", targets[0].Name)
+		assert.Equal("audit", targets[1].Name)
+		assert.Equal("silent", targets[2].Name)
+
+		// Each target keeps its own batching state
+		assert.NotSame(targets[0].WaitGroup, targets[1].WaitGroup)
+		assert.NotEqual(reflect.ValueOf(targets[0].Throttle).Pointer(), reflect.ValueOf(targets[1].Throttle).Pointer())
+	}
+}
+
+func TestGetTargets_DuplicateName(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {}
+		}
+
+		target {
+			use "stdout" {}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	targets, err := GetTargets(c.Data.Targets, c.Decoder)
+	assert.Nil(targets)
+	if assert.NotNil(err) {
+		assert.Equal(`duplicate target name "stdout": set a unique 'name' for each target block`, err.Error())
+	}
+}
+
+func TestGetTarget_OrderedLanes(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					ordered_lanes = 8
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(err)
+	if assert.NotNil(tar) && assert.NotNil(tar.Lanes) {
+		defer tar.Ticker.Stop()
+		assert.Equal(8, tar.Lanes.Count())
+	}
+}
+
+func TestGetTarget_OrderedLanes_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "stdout" {
+				batching {
+					ordered_lanes = -1
+				}
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	tar, err := GetTarget(c.Data.Targets[0], c.Decoder)
+	assert.Nil(tar)
+	assert.EqualError(err, "stdout target has invalid batching configuration: ordered_lanes must not be negative")
+}
+
+// TestValidateTarget checks that targets are validated without connecting to them, so this passes without a broker
+func TestValidateTarget(t *testing.T) {
+	assert := assert.New(t)
+
+	hclConfig := []byte(`
+		target {
+			use "kafka" {
+				brokers = "localhost:1"
+				topic_name = "snowplow-enriched-good"
+			}
+		}
+	`)
+
+	c, err := config.NewHclConfig(hclConfig, "test.hcl")
+	assert.NoError(err)
+
+	assert.NoError(ValidateTarget(c.Data.Targets[0], c.Decoder))
+}
+
+func TestValidateTarget_Invalid(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Config        string
+		ExpectedError string
+	}{
+		{
+			Name: "invalid batching",
+			Config: `
+				target {
+					use "kafka" {
+						brokers = "localhost:1"
+						topic_name = "snowplow-enriched-good"
+						batching {
+							max_batch_bytes = 99999
+							max_message_bytes = 100000
+						}
+					}
+				}`,
+			ExpectedError: "kafka target has invalid batching configuration: max_message_bytes (100000) must not be greater than max_batch_bytes (99999)",
+		},
+		{
+			Name: "unknown target",
+			Config: `
+				target {
+					use "fake_invalid_target" {}
+				}`,
+			ExpectedError: "unknown target: fake_invalid_target",
+		},
+		{
+			Name: "duplicate names",
+			Config: `
+				target {
+					use "stdout" {}
+				}
+				target {
+					use "stdout" {}
+				}`,
+			ExpectedError: `duplicate target name "stdout": set a unique 'name' for each target block`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			c, err := config.NewHclConfig([]byte(tt.Config), "test.hcl")
+			assert.NoError(err)
+
+			assert.EqualError(ValidateTargets(c.Data.Targets, c.Decoder), tt.ExpectedError)
+		})
+	}
+}
+
+func TestTargetDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	defaults := TargetDefaults()
+	assert.Len(defaults, 9)
+
+	assert.Equal((&kafka.KafkaTargetDriver{}).GetDefaultConfiguration(), defaults[kafka.SupportedTargetKafka])
+	assert.Equal((&stdout.StdoutTargetDriver{}).GetDefaultConfiguration(), defaults[stdout.SupportedTargetStdout])
+}
@@ -0,0 +1,190 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int64
+
+const (
+	// BreakerClosed lets writes through and tracks their failure rate
+	BreakerClosed BreakerState = iota
+	// BreakerHalfOpen lets a single probe write through to test whether the target has recovered
+	BreakerHalfOpen
+	// BreakerOpen pauses all writes until the open duration has elapsed
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig holds the thresholds of a circuit breaker
+type CircuitBreakerConfig struct {
+	// FailureRate is the fraction of failed writes within the window which opens the breaker
+	FailureRate float64
+	// MinRequests is the number of writes needed within the window before the failure rate is considered
+	MinRequests int
+	// Window is the period over which writes are counted
+	Window time.Duration
+	// OpenDuration is how long the breaker stays open before letting a probe write through
+	OpenDuration time.Duration
+}
+
+// CircuitBreaker pauses writes to a target which keeps failing.
+// It opens when the failure rate within a window crosses the threshold, and after the open duration
+// lets a single probe write through: the breaker closes if it succeeds, and opens again if it fails.
+type CircuitBreaker struct {
+	config        CircuitBreakerConfig
+	onStateChange func(from, to BreakerState)
+	now           func() time.Time
+
+	mu          sync.Mutex
+	state       BreakerState
+	changed     chan struct{}
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+}
+
+// NewCircuitBreaker returns a closed circuit breaker.
+// onStateChange, if not nil, is called on every state transition, outside of the breaker's lock.
+func NewCircuitBreaker(config CircuitBreakerConfig, onStateChange func(from, to BreakerState)) *CircuitBreaker {
+	return &CircuitBreaker{
+		config:        config,
+		onStateChange: onStateChange,
+		now:           time.Now,
+		changed:       make(chan struct{}),
+	}
+}
+
+// State returns the current state of the breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow blocks until a write may go through, and reports whether that write is the half-open probe.
+// The result must be passed to Record once the write has completed.
+func (b *CircuitBreaker) Allow() (probe bool) {
+	for {
+		b.mu.Lock()
+		switch b.state {
+		case BreakerClosed:
+			b.mu.Unlock()
+			return false
+
+		case BreakerOpen:
+			wait := b.openedAt.Add(b.config.OpenDuration).Sub(b.now())
+			if wait <= 0 {
+				notify := b.setState(BreakerHalfOpen)
+				b.mu.Unlock()
+				notify()
+				return true
+			}
+			changed := b.changed
+			b.mu.Unlock()
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-changed:
+			case <-timer.C:
+			}
+			timer.Stop()
+
+		case BreakerHalfOpen:
+			// Only one probe at a time, everyone else waits for its outcome
+			changed := b.changed
+			b.mu.Unlock()
+			<-changed
+		}
+	}
+}
+
+// Record registers the outcome of a write let through by Allow
+func (b *CircuitBreaker) Record(probe bool, success bool) {
+	b.mu.Lock()
+
+	notify := func() {}
+	switch b.state {
+	case BreakerHalfOpen:
+		// Writes which started before the breaker opened don't tell us anything about recovery
+		if !probe {
+			break
+		}
+		if success {
+			notify = b.setState(BreakerClosed)
+		} else {
+			notify = b.setState(BreakerOpen)
+		}
+
+	case BreakerClosed:
+		now := b.now()
+		if now.Sub(b.windowStart) > b.config.Window {
+			b.windowStart = now
+			b.requests = 0
+			b.failures = 0
+		}
+
+		b.requests++
+		if !success {
+			b.failures++
+		}
+
+		if b.requests >= b.config.MinRequests && float64(b.failures)/float64(b.requests) >= b.config.FailureRate {
+			notify = b.setState(BreakerOpen)
+		}
+	}
+
+	b.mu.Unlock()
+	notify()
+}
+
+// setState transitions the breaker, waking up any blocked writers.
+// It must be called with the lock held, and returns the notification to run once the lock is released.
+func (b *CircuitBreaker) setState(to BreakerState) func() {
+	from := b.state
+	b.state = to
+
+	switch to {
+	case BreakerOpen:
+		b.openedAt = b.now()
+	case BreakerClosed:
+		b.windowStart = b.now()
+		b.requests = 0
+		b.failures = 0
+	}
+
+	close(b.changed)
+	b.changed = make(chan struct{})
+
+	return func() {
+		if b.onStateChange != nil {
+			b.onStateChange(from, to)
+		}
+	}
+}
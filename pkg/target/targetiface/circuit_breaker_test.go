@@ -0,0 +1,177 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced clock for driving breaker timings in tests
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestBreaker(config CircuitBreakerConfig) (*CircuitBreaker, *fakeClock, *[]BreakerState) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	var mu sync.Mutex
+	transitions := []BreakerState{}
+
+	b := NewCircuitBreaker(config, func(from, to BreakerState) {
+		mu.Lock()
+		defer mu.Unlock()
+		transitions = append(transitions, to)
+	})
+	b.now = clock.Now
+	b.windowStart = clock.Now()
+	return b, clock, &transitions
+}
+
+func TestCircuitBreaker_OpensOnFailureRate(t *testing.T) {
+	assert := assert.New(t)
+
+	b, _, transitions := newTestBreaker(CircuitBreakerConfig{FailureRate: 0.5, MinRequests: 4, Window: time.Minute, OpenDuration: time.Minute})
+
+	// Not enough requests yet, even though they all failed
+	for range 3 {
+		b.Record(b.Allow(), false)
+	}
+	assert.Equal(BreakerClosed, b.State())
+
+	b.Record(b.Allow(), true)
+	assert.Equal(BreakerOpen, b.State())
+	assert.Equal([]BreakerState{BreakerOpen}, *transitions)
+}
+
+func TestCircuitBreaker_StaysClosedBelowFailureRate(t *testing.T) {
+	assert := assert.New(t)
+
+	b, _, _ := newTestBreaker(CircuitBreakerConfig{FailureRate: 0.5, MinRequests: 4, Window: time.Minute, OpenDuration: time.Minute})
+
+	for range 10 {
+		b.Record(b.Allow(), true)
+	}
+	for range 9 {
+		b.Record(b.Allow(), false)
+	}
+	assert.Equal(BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_WindowResets(t *testing.T) {
+	assert := assert.New(t)
+
+	b, clock, _ := newTestBreaker(CircuitBreakerConfig{FailureRate: 0.5, MinRequests: 2, Window: time.Minute, OpenDuration: time.Minute})
+
+	b.Record(b.Allow(), false)
+	clock.Advance(2 * time.Minute)
+
+	// The earlier failure has fallen out of the window
+	b.Record(b.Allow(), true)
+	b.Record(b.Allow(), true)
+	assert.Equal(BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_ProbeRecovers(t *testing.T) {
+	assert := assert.New(t)
+
+	b, clock, transitions := newTestBreaker(CircuitBreakerConfig{FailureRate: 1, MinRequests: 1, Window: time.Minute, OpenDuration: time.Minute})
+
+	b.Record(b.Allow(), false)
+	assert.Equal(BreakerOpen, b.State())
+
+	clock.Advance(time.Minute)
+	probe := b.Allow()
+	assert.True(probe)
+	assert.Equal(BreakerHalfOpen, b.State())
+
+	b.Record(probe, true)
+	assert.Equal(BreakerClosed, b.State())
+	assert.Equal([]BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, *transitions)
+}
+
+func TestCircuitBreaker_ProbeFailureReopens(t *testing.T) {
+	assert := assert.New(t)
+
+	b, clock, transitions := newTestBreaker(CircuitBreakerConfig{FailureRate: 1, MinRequests: 1, Window: time.Minute, OpenDuration: time.Minute})
+
+	b.Record(b.Allow(), false)
+	clock.Advance(time.Minute)
+	b.Record(b.Allow(), false)
+
+	assert.Equal(BreakerOpen, b.State())
+	assert.Equal([]BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen}, *transitions)
+}
+
+func TestCircuitBreaker_NonProbeIgnoredWhileHalfOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	b, clock, _ := newTestBreaker(CircuitBreakerConfig{FailureRate: 1, MinRequests: 1, Window: time.Minute, OpenDuration: time.Minute})
+
+	// A write let through while closed, which completes after the breaker has moved on
+	inFlight := b.Allow()
+
+	b.Record(b.Allow(), false)
+	clock.Advance(time.Minute)
+	probe := b.Allow()
+
+	b.Record(inFlight, true)
+	assert.Equal(BreakerHalfOpen, b.State())
+
+	b.Record(probe, true)
+	assert.Equal(BreakerClosed, b.State())
+}
+
+func TestCircuitBreaker_BlocksWhileHalfOpen(t *testing.T) {
+	assert := assert.New(t)
+
+	b, clock, _ := newTestBreaker(CircuitBreakerConfig{FailureRate: 1, MinRequests: 1, Window: time.Minute, OpenDuration: time.Minute})
+
+	b.Record(b.Allow(), false)
+	clock.Advance(time.Minute)
+	probe := b.Allow()
+
+	released := make(chan bool)
+	go func() {
+		released <- b.Allow()
+	}()
+
+	select {
+	case <-released:
+		t.Fatal("write went through while the probe was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	b.Record(probe, true)
+
+	select {
+	case isProbe := <-released:
+		assert.False(isProbe)
+	case <-time.After(time.Second):
+		t.Fatal("write was not released once the breaker closed")
+	}
+}
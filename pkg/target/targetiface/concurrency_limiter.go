@@ -0,0 +1,116 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// limiterBackoffRatio is the factor the limit is multiplied by when the target is overloaded
+	limiterBackoffRatio = 0.7
+	// limiterLatencyTolerance is how many times the usual latency a request may take before the target is considered overloaded
+	limiterLatencyTolerance = 2.0
+	// limiterLatencySmoothing is the weight of each new sample in the usual latency
+	limiterLatencySmoothing = 0.05
+)
+
+// ConcurrencyLimiter adapts the number of batches written concurrently to a target with AIMD:
+// the limit grows additively while requests succeed at their usual latency, and shrinks multiplicatively
+// when the target throttles or its latency climbs well above the usual.
+type ConcurrencyLimiter struct {
+	min int
+	max int
+
+	mu           sync.Mutex
+	cond         *sync.Cond
+	limit        float64
+	inFlight     int
+	latency      time.Duration
+	lastDecrease time.Time
+}
+
+// NewConcurrencyLimiter returns a limiter which keeps concurrency between min and max, starting from min
+func NewConcurrencyLimiter(min, max int) *ConcurrencyLimiter {
+	l := &ConcurrencyLimiter{
+		min:   min,
+		max:   max,
+		limit: float64(min),
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// Acquire blocks until fewer batches than the current limit are in flight
+func (l *ConcurrencyLimiter) Acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.inFlight >= l.currentLimit() {
+		l.cond.Wait()
+	}
+	l.inFlight++
+}
+
+// Release frees the slot taken by Acquire
+func (l *ConcurrencyLimiter) Release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight--
+	l.cond.Signal()
+}
+
+// Limit returns the current concurrency limit
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.currentLimit()
+}
+
+// Record adjusts the limit based on the outcome of a request.
+// started and latency may be zero when the request timing is unknown.
+func (l *ConcurrencyLimiter) Record(started time.Time, latency time.Duration, throttled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	overloaded := throttled ||
+		(latency > 0 && l.latency > 0 && float64(latency) > float64(l.latency)*limiterLatencyTolerance)
+
+	if latency > 0 {
+		if l.latency == 0 {
+			l.latency = latency
+		} else {
+			l.latency += time.Duration(limiterLatencySmoothing * float64(latency-l.latency))
+		}
+	}
+
+	switch {
+	case overloaded:
+		// Requests sent before the last decrease were made at the old limit, so they don't call for another one
+		if !started.IsZero() && started.Before(l.lastDecrease) {
+			return
+		}
+		l.limit = max(float64(l.min), l.limit*limiterBackoffRatio)
+		l.lastDecrease = time.Now()
+
+	case l.inFlight >= l.currentLimit():
+		// Only grow while the current limit is actually in use, roughly by one per limit's worth of requests
+		l.limit = min(float64(l.max), l.limit+1/l.limit)
+		l.cond.Broadcast()
+	}
+}
+
+func (l *ConcurrencyLimiter) currentLimit() int {
+	return int(l.limit)
+}
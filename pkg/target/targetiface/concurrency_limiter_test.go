@@ -0,0 +1,116 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcurrencyLimiter_GrowsWhileSaturated(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewConcurrencyLimiter(1, 4)
+	assert.Equal(1, l.Limit())
+
+	// Every slot in use and requests are fast, so the limit grows up to the maximum
+	for range 20 {
+		for range l.Limit() {
+			l.Acquire()
+		}
+		for range l.Limit() {
+			l.Record(time.Now(), 10*time.Millisecond, false)
+		}
+		for range l.inFlight {
+			l.Release()
+		}
+	}
+	assert.Equal(4, l.Limit())
+}
+
+func TestConcurrencyLimiter_DoesNotGrowWhenIdle(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewConcurrencyLimiter(2, 10)
+
+	// Only one of two slots in use, there's no point raising the limit
+	for range 20 {
+		l.Acquire()
+		l.Record(time.Now(), 10*time.Millisecond, false)
+		l.Release()
+	}
+	assert.Equal(2, l.Limit())
+}
+
+func TestConcurrencyLimiter_BacksOffOnThrottle(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewConcurrencyLimiter(1, 20)
+	l.limit = 10
+
+	l.Record(time.Now(), 10*time.Millisecond, true)
+	assert.Equal(7, l.Limit())
+
+	// A request sent before the last decrease doesn't decrease the limit again
+	l.Record(time.Now().Add(-time.Minute), 10*time.Millisecond, true)
+	assert.Equal(7, l.Limit())
+
+	// Never below the minimum
+	for range 20 {
+		l.Record(time.Time{}, 0, true)
+	}
+	assert.Equal(1, l.Limit())
+}
+
+func TestConcurrencyLimiter_BacksOffOnLatency(t *testing.T) {
+	assert := assert.New(t)
+
+	l := NewConcurrencyLimiter(1, 20)
+	l.limit = 10
+
+	// Establish the usual latency
+	for range 10 {
+		l.Record(time.Now(), 10*time.Millisecond, false)
+	}
+	assert.Equal(10, l.Limit())
+
+	// Well above the usual latency means the target is overloaded
+	l.Record(time.Now(), 50*time.Millisecond, false)
+	assert.Equal(7, l.Limit())
+}
+
+func TestConcurrencyLimiter_AcquireBlocksAtLimit(t *testing.T) {
+	l := NewConcurrencyLimiter(1, 4)
+	l.Acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		l.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired a slot above the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	l.Release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("slot was not handed over on release")
+	}
+}
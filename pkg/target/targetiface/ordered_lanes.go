@@ -0,0 +1,111 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"hash/fnv"
+	"sync"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// laneQueueDepth is how many batches a lane holds: the one in flight and one ready to go once it finishes.
+// Producing more batches for a busy lane blocks the caller.
+const laneQueueDepth = 2
+
+// Lanes shard messages by partition key, so that each lane has at most one batch in flight.
+// Messages with the same partition key are written in the order they were added,
+// and a batch which keeps failing only holds up its own lane.
+type Lanes struct {
+	mu    sync.Mutex
+	lanes []*lane
+}
+
+type lane struct {
+	batch CurrentBatch
+
+	// tail is closed once the most recently spawned write of the lane has finished
+	tail chan struct{}
+
+	// queued holds a token for every spawned write of the lane which hasn't finished yet
+	queued chan struct{}
+}
+
+// NewLanes returns count lanes, or nil if count is not positive
+func NewLanes(count int) *Lanes {
+	if count <= 0 {
+		return nil
+	}
+
+	l := &Lanes{lanes: make([]*lane, count)}
+	for i := range l.lanes {
+		l.lanes[i] = &lane{
+			batch:  CurrentBatch{Messages: []*models.Message{}},
+			queued: make(chan struct{}, laneQueueDepth),
+		}
+	}
+	return l
+}
+
+// Count returns the number of lanes
+func (l *Lanes) Count() int {
+	return len(l.lanes)
+}
+
+// forKey returns the lane of a partition key
+func (l *Lanes) forKey(partitionKey string) *lane {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(partitionKey))
+	return l.lanes[h.Sum32()%uint32(len(l.lanes))]
+}
+
+// enqueue reserves a place for a write in the lane of the batch, blocking while the lane is full.
+// It returns a channel to wait on before writing, and a function to call once the write has finished.
+func (l *Lanes) enqueue(batch []*models.Message) (previous <-chan struct{}, finish func()) {
+	lane := l.forKey(batch[0].PartitionKey)
+	lane.queued <- struct{}{}
+
+	done := make(chan struct{})
+	l.mu.Lock()
+	previous, lane.tail = lane.tail, done
+	l.mu.Unlock()
+
+	return previous, func() {
+		close(done)
+		<-lane.queued
+	}
+}
+
+// add adds a message to the current batch of its lane
+func (l *Lanes) add(message *models.Message, batcher func(CurrentBatch, *models.Message) ([]*models.Message, CurrentBatch, *models.Message)) (batchToSend []*models.Message, oversized *models.Message) {
+	lane := l.forKey(message.PartitionKey)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	batchToSend, lane.batch, oversized = batcher(lane.batch, message)
+	return batchToSend, oversized
+}
+
+// flush returns the current batches of all lanes which have messages, and resets them
+func (l *Lanes) flush() [][]*models.Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var batches [][]*models.Message
+	for _, lane := range l.lanes {
+		if len(lane.batch.Messages) > 0 {
+			batches = append(batches, lane.batch.Messages)
+			lane.batch = CurrentBatch{Messages: []*models.Message{}}
+		}
+	}
+	return batches
+}
@@ -0,0 +1,132 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// laneTestDriver batches with the default batcher, and is never written to
+type laneTestDriver struct {
+	TargetDriver
+	batchingConfig BatchingConfig
+}
+
+func (d *laneTestDriver) Batcher(currentBatch CurrentBatch, message *models.Message) ([]*models.Message, CurrentBatch, *models.Message) {
+	return DefaultBatcher(currentBatch, message, d.batchingConfig)
+}
+
+func newLaneTestTarget(lanes int) *Target {
+	ticker := time.NewTicker(time.Hour)
+	ticker.Stop()
+	return &Target{
+		TargetDriver: &laneTestDriver{batchingConfig: BatchingConfig{MaxBatchMessages: 2, MaxBatchBytes: 1000, MaxMessageBytes: 1000}},
+		Throttle:     make(chan struct{}, 10),
+		WaitGroup:    &sync.WaitGroup{},
+		Ticker:       ticker,
+		TickerPeriod: time.Hour,
+		Lanes:        NewLanes(lanes),
+	}
+}
+
+func TestNewLanes_Disabled(t *testing.T) {
+	assert.Nil(t, NewLanes(0))
+	assert.Equal(t, 4, NewLanes(4).Count())
+}
+
+func TestTarget_AddMessage_Lanes(t *testing.T) {
+	assert := assert.New(t)
+
+	target := newLaneTestTarget(16)
+	lanes := target.Lanes
+	// Find two keys which land in different lanes
+	keyA, keyB := "user-a", "user-b"
+	for lanes.forKey(keyA) == lanes.forKey(keyB) {
+		keyB = keyB + "x"
+	}
+
+	batch, oversized := target.AddMessage(&models.Message{Data: []byte("a1"), PartitionKey: keyA})
+	assert.Nil(batch)
+	assert.Nil(oversized)
+	batch, _ = target.AddMessage(&models.Message{Data: []byte("b1"), PartitionKey: keyB})
+	assert.Nil(batch)
+
+	// Filling the lane of keyA sends its batch, while keyB's lane keeps its message
+	batch, _ = target.AddMessage(&models.Message{Data: []byte("a2"), PartitionKey: keyA})
+	if assert.Len(batch, 2) {
+		assert.Equal("a1", string(batch[0].Data))
+		assert.Equal("a2", string(batch[1].Data))
+	}
+	batch, _ = target.AddMessage(&models.Message{Data: []byte("a3"), PartitionKey: keyA})
+	assert.Nil(batch)
+
+	// Flushing returns a batch per non-empty lane
+	flushed := target.FlushAll()
+	if assert.Len(flushed, 2) {
+		data := []string{string(flushed[0][0].Data), string(flushed[1][0].Data)}
+		assert.ElementsMatch([]string{"a3", "b1"}, data)
+	}
+	assert.Empty(target.FlushAll())
+}
+
+func TestTarget_SpawnThrottledAsyncWrite_Lanes(t *testing.T) {
+	assert := assert.New(t)
+
+	target := newLaneTestTarget(16)
+	lanes := target.Lanes
+	keyA, keyB := "user-a", "user-b"
+	for lanes.forKey(keyA) == lanes.forKey(keyB) {
+		keyB = keyB + "x"
+	}
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, name)
+	}
+
+	release := make(chan struct{})
+	target.SpawnThrottledAsyncWrite([]*models.Message{{PartitionKey: keyA}}, func() {
+		<-release
+		record("a1")
+	})
+	target.SpawnThrottledAsyncWrite([]*models.Message{{PartitionKey: keyA}}, func() {
+		record("a2")
+	})
+
+	// Another lane isn't held up by the blocked one
+	bDone := make(chan struct{})
+	target.SpawnThrottledAsyncWrite([]*models.Message{{PartitionKey: keyB}}, func() {
+		record("b1")
+		close(bDone)
+	})
+	select {
+	case <-bDone:
+	case <-time.After(time.Second):
+		t.Fatal("write to another lane was blocked")
+	}
+
+	close(release)
+	target.WaitGroup.Wait()
+
+	// The second batch of a lane is written only after the first one finished
+	assert.Equal([]string{"b1", "a1", "a2"}, order)
+	assert.Empty(target.Throttle)
+}
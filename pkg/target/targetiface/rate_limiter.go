@@ -0,0 +1,104 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"sync"
+	"time"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// RateLimitConfig caps the throughput of a target. A zero rate means no limit.
+type RateLimitConfig struct {
+	MessagesPerSecond float64 `hcl:"messages_per_second,optional"`
+	BytesPerSecond    float64 `hcl:"bytes_per_second,optional"`
+}
+
+// RateLimiter holds token buckets for the messages and bytes written to a target
+type RateLimiter struct {
+	messages *tokenBucket
+	bytes    *tokenBucket
+	sleep    func(time.Duration)
+}
+
+// NewRateLimiter returns a rate limiter for the config, or nil if it sets no limit
+func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
+	if config == nil || (config.MessagesPerSecond <= 0 && config.BytesPerSecond <= 0) {
+		return nil
+	}
+
+	return &RateLimiter{
+		messages: newTokenBucket(config.MessagesPerSecond),
+		bytes:    newTokenBucket(config.BytesPerSecond),
+		sleep:    time.Sleep,
+	}
+}
+
+// Wait blocks until the batch can be written without exceeding the rate limits
+func (r *RateLimiter) Wait(batch []*models.Message) {
+	dataBytes := 0
+	for _, msg := range batch {
+		dataBytes += len(msg.Data)
+	}
+
+	wait := max(r.messages.take(float64(len(batch))), r.bytes.take(float64(dataBytes)))
+	if wait > 0 {
+		r.sleep(wait)
+	}
+}
+
+// tokenBucket refills at a fixed rate up to one second's worth of tokens.
+// Taking more tokens than available puts the bucket into debt, which later takers wait for,
+// so batches larger than the bucket are still allowed through at the configured average rate.
+type tokenBucket struct {
+	rate float64
+	now  func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket, or nil if rate sets no limit
+func newTokenBucket(rate float64) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{
+		rate:   rate,
+		now:    time.Now,
+		tokens: rate,
+		last:   time.Now(),
+	}
+}
+
+// take removes n tokens from the bucket, and returns how long to wait before using them
+func (b *tokenBucket) take(n float64) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,102 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package targetiface
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+func newTestRateLimiter(config *RateLimitConfig) (*RateLimiter, *fakeClock, *[]time.Duration) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	waits := []time.Duration{}
+
+	r := NewRateLimiter(config)
+	for _, b := range []*tokenBucket{r.messages, r.bytes} {
+		if b != nil {
+			b.now = clock.Now
+			b.last = clock.Now()
+		}
+	}
+	r.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		clock.Advance(d)
+	}
+	return r, clock, &waits
+}
+
+func makeBatch(count, size int) []*models.Message {
+	batch := make([]*models.Message, count)
+	for i := range batch {
+		batch[i] = &models.Message{Data: make([]byte, size)}
+	}
+	return batch
+}
+
+func TestNewRateLimiter_NoLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(NewRateLimiter(nil))
+	assert.Nil(NewRateLimiter(&RateLimitConfig{}))
+}
+
+func TestRateLimiter_MessagesPerSecond(t *testing.T) {
+	assert := assert.New(t)
+
+	r, _, waits := newTestRateLimiter(&RateLimitConfig{MessagesPerSecond: 10})
+
+	// The first second's worth goes straight through
+	r.Wait(makeBatch(10, 1))
+	assert.Empty(*waits)
+
+	// Then batches wait for the bucket to refill
+	r.Wait(makeBatch(5, 1))
+	assert.Equal([]time.Duration{500 * time.Millisecond}, *waits)
+}
+
+func TestRateLimiter_BytesPerSecond(t *testing.T) {
+	assert := assert.New(t)
+
+	r, _, waits := newTestRateLimiter(&RateLimitConfig{BytesPerSecond: 1000})
+
+	// A batch larger than the bucket still goes through, and the debt is paid by the next one
+	r.Wait(makeBatch(3, 1000))
+	assert.Equal([]time.Duration{2 * time.Second}, *waits)
+
+	r.Wait(makeBatch(1, 500))
+	assert.Equal([]time.Duration{2 * time.Second, 500 * time.Millisecond}, *waits)
+}
+
+func TestRateLimiter_BothLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	r, _, waits := newTestRateLimiter(&RateLimitConfig{MessagesPerSecond: 100, BytesPerSecond: 1000})
+
+	// The byte limit is the tighter one here
+	r.Wait(makeBatch(10, 300))
+	assert.Equal([]time.Duration{2 * time.Second}, *waits)
+}
+
+func TestRateLimiter_RefillIsCapped(t *testing.T) {
+	assert := assert.New(t)
+
+	r, clock, waits := newTestRateLimiter(&RateLimitConfig{MessagesPerSecond: 10})
+
+	// Being idle for a long time doesn't allow for a bigger burst than a second's worth
+	clock.Advance(time.Hour)
+	r.Wait(makeBatch(20, 1))
+	assert.Equal([]time.Duration{time.Second}, *waits)
+}
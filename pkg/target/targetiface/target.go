@@ -53,21 +53,53 @@ type BatchingConfig struct {
 	MaxMessageBytes      int `hcl:"max_message_bytes,optional"`
 	MaxConcurrentBatches int `hcl:"max_concurrent_batches,optional"`
 	FlushPeriodMillis    int `hcl:"flush_period_millis,optional"`
+
+	// OrderedLanes shards messages into this many lanes by partition key, each with at most one batch in flight,
+	// so that messages with the same partition key reach the target in order. Disabled when 0.
+	OrderedLanes int `hcl:"ordered_lanes,optional"`
+
+	// AdaptiveConcurrency adjusts concurrency between MinConcurrentBatches and MaxConcurrentBatches
+	// based on request latency and throttling, instead of always allowing MaxConcurrentBatches
+	AdaptiveConcurrency  bool `hcl:"adaptive_concurrency,optional"`
+	MinConcurrentBatches int  `hcl:"min_concurrent_batches,optional"`
+
+	// RateLimit caps the messages and bytes per second written to the target
+	RateLimit *RateLimitConfig `hcl:"rate_limit,block"`
 }
 
 type Target struct {
 	TargetDriver
 
+	// Name identifies the target in logs and metrics
+	Name string
+
 	// Runtime state (managed by Router)
 	CurrentBatch CurrentBatch
 	Throttle     chan struct{}
 	WaitGroup    *sync.WaitGroup
 	Ticker       *time.Ticker
 	TickerPeriod time.Duration
+
+	// Breaker pauses writes while the target keeps failing, nil when disabled
+	Breaker *CircuitBreaker
+
+	// Limiter adapts concurrency below the Throttle capacity, nil when concurrency is fixed
+	Limiter *ConcurrencyLimiter
+
+	// RateLimiter holds writes back to the configured throughput, nil when there is no rate limit
+	RateLimiter *RateLimiter
+
+	// Lanes batch and write messages in order per partition key, nil when ordering is disabled.
+	// CurrentBatch is unused when set.
+	Lanes *Lanes
 }
 
 // AddMessages adds messages to the current batch and returns batches ready to send and oversized messages
 func (t *Target) AddMessage(message *models.Message) (batchToSend []*models.Message, oversized *models.Message) {
+	if t.Lanes != nil {
+		return t.Lanes.add(message, t.Batcher)
+	}
+
 	batchToSend, newCurrentBatch, oversized := t.Batcher(t.CurrentBatch, message)
 	t.CurrentBatch = newCurrentBatch
 	return batchToSend, oversized
@@ -84,10 +116,46 @@ func (t *Target) Flush() []*models.Message {
 	return messages
 }
 
-// SpawnThrottledAsyncWrite executes a write function with throttling and wait group management
-func (t *Target) SpawnThrottledAsyncWrite(write func()) {
-	// Acquire throttle
-	t.Throttle <- struct{}{}
+// FlushAll returns the current batches and resets them.
+// There is one batch per non-empty lane when ordering is enabled, and at most one otherwise.
+func (t *Target) FlushAll() [][]*models.Message {
+	if t.Lanes != nil {
+		return t.Lanes.flush()
+	}
+	if messages := t.Flush(); messages != nil {
+		return [][]*models.Message{messages}
+	}
+	return nil
+}
+
+// SpawnThrottledAsyncWrite executes a write function for the batch with throttling and wait group management.
+// It blocks while the target is at its concurrency or rate limit, which backpressures the caller.
+// With ordering enabled, it only blocks while the batch's lane is full, and the write waits for the lane's previous one.
+func (t *Target) SpawnThrottledAsyncWrite(batch []*models.Message, write func()) {
+	if t.Lanes != nil {
+		t.spawnOrderedWrite(batch, write)
+		return
+	}
+
+	t.AcquireWriteSlot(batch)
+	t.WaitGroup.Add(1)
+
+	// Reset the ticker when we call send
+	t.Ticker.Reset(t.TickerPeriod)
+
+	go func() {
+		defer func() {
+			t.WaitGroup.Done()
+			t.ReleaseWriteSlot()
+		}()
+
+		write()
+	}()
+}
+
+// spawnOrderedWrite queues the write behind the previous write of the batch's lane
+func (t *Target) spawnOrderedWrite(batch []*models.Message, write func()) {
+	previous, finish := t.Lanes.enqueue(batch)
 	t.WaitGroup.Add(1)
 
 	// Reset the ticker when we call send
@@ -95,14 +163,77 @@ func (t *Target) SpawnThrottledAsyncWrite(write func()) {
 
 	go func() {
 		defer func() {
+			finish()
 			t.WaitGroup.Done()
-			<-t.Throttle
 		}()
 
+		if previous != nil {
+			<-previous
+		}
+		t.AcquireWriteSlot(batch)
+		defer t.ReleaseWriteSlot()
+
 		write()
 	}()
 }
 
+// AcquireWriteSlot blocks until the batch may be written within the target's rate and concurrency limits
+func (t *Target) AcquireWriteSlot(batch []*models.Message) {
+	if t.RateLimiter != nil {
+		t.RateLimiter.Wait(batch)
+	}
+	t.Throttle <- struct{}{}
+	if t.Limiter != nil {
+		t.Limiter.Acquire()
+	}
+}
+
+// ReleaseWriteSlot frees the slot taken by AcquireWriteSlot
+func (t *Target) ReleaseWriteSlot() {
+	if t.Limiter != nil {
+		t.Limiter.Release()
+	}
+	<-t.Throttle
+}
+
+// GuardedWrite writes a batch once the circuit breaker, if any, lets it through,
+// and feeds the outcome of the write back to the circuit breaker and concurrency limiter
+func (t *Target) GuardedWrite(messages []*models.Message) (*models.TargetWriteResult, error) {
+	probe := false
+	if t.Breaker != nil {
+		probe = t.Breaker.Allow()
+	}
+
+	result, err := t.Write(messages)
+
+	if t.Breaker != nil {
+		t.Breaker.Record(probe, err == nil)
+	}
+	if t.Limiter != nil {
+		started, latency := requestTiming(result)
+		_, throttled := err.(models.ThrottleWriteError)
+		t.Limiter.Record(started, latency, throttled)
+	}
+	return result, err
+}
+
+// requestTiming returns when the request of a write started and how long it took,
+// based on the timestamps stamped on its messages by the driver
+func requestTiming(result *models.TargetWriteResult) (time.Time, time.Duration) {
+	if result == nil {
+		return time.Time{}, 0
+	}
+
+	for _, msgs := range [][]*models.Message{result.Sent, result.Failed, result.Invalid} {
+		for _, msg := range msgs {
+			if !msg.TimeRequestStarted.IsZero() && msg.TimeRequestFinished.After(msg.TimeRequestStarted) {
+				return msg.TimeRequestStarted, msg.TimeRequestFinished.Sub(msg.TimeRequestStarted)
+			}
+		}
+	}
+	return time.Time{}, 0
+}
+
 // Most targets will share the same logic for batching, so we can define a default here for shared use.
 // This can be called in a Driver's ChunkBatches function
 func DefaultBatcher(currentBatch CurrentBatch, message *models.Message, batchingConfig BatchingConfig) (batchToSend []*models.Message, newCurrentBatch CurrentBatch, oversized *models.Message) {
@@ -13,6 +13,7 @@ package targetiface
 
 import (
 	"testing"
+	"time"
 
 	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/stretchr/testify/assert"
@@ -213,3 +214,22 @@ func TestDefaultBatcher_FinalCheckSendsBatchAtByteCap(t *testing.T) {
 	assert.Equal(0, newCurrentBatch.DataBytes)
 	assert.Empty(oversized)
 }
+
+func TestRequestTiming(t *testing.T) {
+	assert := assert.New(t)
+
+	started := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := models.NewTargetWriteResult(
+		[]*models.Message{{Data: []byte("unstamped")}},
+		[]*models.Message{{Data: []byte("stamped"), TimeRequestStarted: started, TimeRequestFinished: started.Add(time.Second)}},
+		nil,
+	)
+
+	gotStarted, latency := requestTiming(result)
+	assert.Equal(started, gotStarted)
+	assert.Equal(time.Second, latency)
+
+	gotStarted, latency = requestTiming(nil)
+	assert.True(gotStarted.IsZero())
+	assert.Zero(latency)
+}
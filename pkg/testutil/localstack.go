@@ -19,6 +19,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -272,3 +273,33 @@ func PutProvidedDataIntoKinesis(kinesisClient common.KinesisV2API, streamName st
 	}
 	return nil
 }
+
+// --- Secrets Manager Testing
+
+// GetAWSLocalstackSecretsManagerClient returns a Secrets Manager client
+func GetAWSLocalstackSecretsManagerClient() common.SecretsManagerV2API {
+	cfg := GetAWSLocalstackConfig()
+	return secretsmanager.NewFromConfig(*cfg)
+}
+
+// CreateAWSLocalstackSecret creates a new secret holding value
+func CreateAWSLocalstackSecret(client common.SecretsManagerV2API, secretName string, value string) (*secretsmanager.CreateSecretOutput, error) {
+	return client.CreateSecret(
+		context.Background(),
+		&secretsmanager.CreateSecretInput{
+			Name:         aws.String(secretName),
+			SecretString: aws.String(value),
+		},
+	)
+}
+
+// DeleteAWSLocalstackSecret deletes an existing secret without recovery window
+func DeleteAWSLocalstackSecret(client common.SecretsManagerV2API, secretName string) (*secretsmanager.DeleteSecretOutput, error) {
+	return client.DeleteSecret(
+		context.Background(),
+		&secretsmanager.DeleteSecretInput{
+			SecretId:                   aws.String(secretName),
+			ForceDeleteWithoutRecovery: aws.Bool(true),
+		},
+	)
+}
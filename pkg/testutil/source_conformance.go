@@ -0,0 +1,97 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/source/sourceiface"
+)
+
+// SourceConformance describes how to run a source against a local stand-in of its upstream,
+// for RunSourceConformance to check that the source keeps to the contract of sourceiface.Source
+type SourceConformance struct {
+	// NewSource returns a source reading from the stand-in
+	NewSource func(t *testing.T) sourceiface.Source
+
+	// Send makes data available to the source through the stand-in, once the source has started
+	Send func(t *testing.T, data []string)
+
+	// Timeout bounds how long the source may take to read the data and to shut down, 10 seconds when zero
+	Timeout time.Duration
+}
+
+// RunSourceConformance checks that a source emits every message sent to its stand-in once, with its timestamps set,
+// and that it shuts down and closes its output channel once its context is cancelled
+func RunSourceConformance(t *testing.T, c SourceConformance) {
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	source := c.NewSource(t)
+	output := make(chan *models.Message)
+	source.SetChannels(output)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		source.Start(ctx)
+	}()
+
+	run := GenRandomString(8)
+	sent := make([]string, 20)
+	for i := range sent {
+		sent[i] = fmt.Sprintf("conformance-%s-%d", run, i)
+	}
+	c.Send(t, sent)
+
+	var received []string
+	deadline := time.After(timeout)
+	for len(received) < len(sent) {
+		select {
+		case message, ok := <-output:
+			require.True(t, ok, "output channel closed before every message was read")
+			received = append(received, string(message.Data))
+			assert.False(t, message.TimePulled.IsZero(), "message must record when it was pulled")
+			if message.AckFunc != nil {
+				message.AckFunc()
+			}
+		case <-deadline:
+			t.Fatalf("read %d of %d messages within %s", len(received), len(sent), timeout)
+		}
+	}
+	assert.ElementsMatch(t, sent, received, "every message must be emitted once")
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		t.Fatalf("source did not stop within %s of its context being cancelled", timeout)
+	}
+
+	select {
+	case message, ok := <-output:
+		assert.False(t, ok, "source must close its output channel when it stops, got %v", message)
+	case <-time.After(timeout):
+		t.Fatal("source did not close its output channel when it stopped")
+	}
+}
@@ -0,0 +1,303 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package testutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/target/targetiface"
+)
+
+// maxConformanceMessageBytes bounds the messages the batching check builds, limits above it are not checked
+const maxConformanceMessageBytes = 16 << 20
+
+// ErrorClass is how the router treats the outcome of a failed write
+type ErrorClass string
+
+const (
+	// ErrorClassTransient is a plain error, retried with the transient retry strategy
+	ErrorClassTransient ErrorClass = "transient"
+	// ErrorClassThrottle is a models.ThrottleWriteError, retried with the throttle retry strategy
+	ErrorClassThrottle ErrorClass = "throttle"
+	// ErrorClassSetup is a models.SetupWriteError, retried with the setup retry strategy and alerted on
+	ErrorClassSetup ErrorClass = "setup"
+	// ErrorClassFatal is a models.FatalWriteError, which shuts the app down
+	ErrorClassFatal ErrorClass = "fatal"
+	// ErrorClassInvalid is no error at all, with every message rejected as invalid
+	ErrorClassInvalid ErrorClass = "invalid"
+)
+
+// TargetConformance describes how to run a target driver against a local stand-in of its downstream,
+// for RunTargetConformance to check that the driver keeps to the contract of targetiface.TargetDriver
+type TargetConformance struct {
+	// NewDriver returns a driver initialised to write to the stand-in, the suite opens and closes it
+	NewDriver func(t *testing.T) targetiface.TargetDriver
+
+	// Payload wraps a unique id into data the stand-in accepts, the data is the id itself when nil
+	Payload func(id string) string
+
+	// Received returns everything the stand-in has received so far, in which the id of every sent message must be found.
+	// The delivery check is skipped when nil.
+	Received func() []string
+
+	// Failures are stand-ins whose writes fail, along with the class of error they must be reported as
+	Failures []TargetFailure
+}
+
+// TargetFailure is a stand-in whose writes fail
+type TargetFailure struct {
+	Name      string
+	NewDriver func(t *testing.T) targetiface.TargetDriver
+	Class     ErrorClass
+}
+
+// RunTargetConformance checks that a target driver accounts for every message it is given, batches within its limits,
+// sets oversized messages aside, classifies its errors the way the router expects them and can be closed more than once
+func RunTargetConformance(t *testing.T, c TargetConformance) {
+	t.Run("Configuration", func(t *testing.T) {
+		driver := c.NewDriver(t)
+
+		defaults := driver.GetDefaultConfiguration()
+		require.NotNil(t, defaults)
+		assert.Equal(t, reflect.Pointer, reflect.TypeOf(defaults).Kind(), "default configuration must be a pointer to a struct")
+
+		batching := driver.GetBatchingConfig()
+		assert.Positive(t, batching.MaxBatchMessages, "max_batch_messages")
+		assert.Positive(t, batching.MaxBatchBytes, "max_batch_bytes")
+		assert.Positive(t, batching.MaxMessageBytes, "max_message_bytes")
+		assert.Positive(t, batching.MaxConcurrentBatches, "max_concurrent_batches")
+	})
+
+	t.Run("Batching", func(t *testing.T) {
+		checkBatcher(t, c.NewDriver(t))
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		driver := c.NewDriver(t)
+		require.NoError(t, driver.Open())
+		defer driver.Close()
+
+		messages, acks := conformanceMessages(c.Payload, 10)
+		result, err := driver.Write(messages)
+		require.NoError(t, err)
+		checkAccounting(t, messages, result, err)
+		assert.Len(t, result.Sent, len(messages), "every message must be sent to a healthy stand-in")
+		for i, message := range messages {
+			assert.Equal(t, int64(1), acks[i].Load(), "sent message %s must be acked once", message.PartitionKey)
+		}
+
+		if c.Received != nil {
+			received := strings.Join(c.Received(), "\n")
+			for _, message := range messages {
+				assert.Contains(t, received, message.PartitionKey, "sent message must reach the stand-in")
+			}
+		}
+	})
+
+	for _, failure := range c.Failures {
+		t.Run("Failure/"+failure.Name, func(t *testing.T) {
+			driver := failure.NewDriver(t)
+			require.NoError(t, driver.Open())
+			defer driver.Close()
+
+			messages, acks := conformanceMessages(c.Payload, 10)
+			result, err := driver.Write(messages)
+			checkAccounting(t, messages, result, err)
+			assert.Empty(t, result.Sent, "no message must be reported as sent")
+			for i, message := range messages {
+				assert.Zero(t, acks[i].Load(), "message %s must not be acked", message.PartitionKey)
+			}
+
+			assert.Equal(t, failure.Class, classifyWriteError(err), "error %v", err)
+			if failure.Class == ErrorClassInvalid {
+				assert.Len(t, result.Invalid, len(messages), "every message must be invalid")
+			} else {
+				assert.NotEmpty(t, result.Failed, "failed messages must be returned for a retry")
+			}
+		})
+	}
+
+	t.Run("Close", func(t *testing.T) {
+		driver := c.NewDriver(t)
+		require.NoError(t, driver.Open())
+
+		assert.NotPanics(t, driver.Close)
+		assert.NotPanics(t, driver.Close, "closing twice must be harmless")
+	})
+}
+
+// conformanceMessages returns messages keyed by unique ids, along with the number of times each was acked.
+// The partition key holds the id, so that messages can be told apart whatever their data.
+func conformanceMessages(payload func(id string) string, count int) ([]*models.Message, []*atomic.Int64) {
+	run := GenRandomString(8)
+	messages := make([]*models.Message, count)
+	acks := make([]*atomic.Int64, count)
+	for i := range count {
+		id := fmt.Sprintf("conformance-%s-%d", run, i)
+		data := id
+		if payload != nil {
+			data = payload(id)
+		}
+
+		acked := &atomic.Int64{}
+		acks[i] = acked
+		messages[i] = &models.Message{
+			Data:         []byte(data),
+			PartitionKey: id,
+			AckFunc:      func() { acked.Add(1) },
+		}
+	}
+	return messages, acks
+}
+
+// checkAccounting checks that every message written comes back exactly once across Sent, Failed and Invalid
+func checkAccounting(t *testing.T, messages []*models.Message, result *models.TargetWriteResult, err error) {
+	t.Helper()
+	require.NotNil(t, result, "a write must always return a result, even along with an error")
+
+	seen := make(map[*models.Message]string, len(messages))
+	for outcome, returned := range map[string][]*models.Message{"sent": result.Sent, "failed": result.Failed, "invalid": result.Invalid} {
+		for _, message := range returned {
+			if previous, ok := seen[message]; ok {
+				t.Errorf("message %s is returned as both %s and %s", message.PartitionKey, previous, outcome)
+			}
+			seen[message] = outcome
+		}
+	}
+	for _, message := range messages {
+		if _, ok := seen[message]; !ok {
+			t.Errorf("message %s is neither sent, failed nor invalid", message.PartitionKey)
+		}
+	}
+	assert.Len(t, seen, len(messages), "a write must only return the messages it was given")
+
+	if len(result.Failed) > 0 {
+		assert.Error(t, err, "failed messages are only retried when the write returns an error")
+	}
+}
+
+// classifyWriteError mirrors how the router tells errors apart, which is by type assertion, so wrapped errors are transient
+func classifyWriteError(err error) ErrorClass {
+	switch err.(type) {
+	case nil:
+		return ErrorClassInvalid
+	case models.ThrottleWriteError:
+		return ErrorClassThrottle
+	case models.SetupWriteError:
+		return ErrorClassSetup
+	case models.FatalWriteError:
+		return ErrorClassFatal
+	default:
+		return ErrorClassTransient
+	}
+}
+
+// checkBatcher feeds the batcher small, large and oversized messages, and checks every batch is within the limits
+// and every message comes out once, either in a batch or as oversized
+func checkBatcher(t *testing.T, driver targetiface.TargetDriver) {
+	limits := driver.GetBatchingConfig()
+
+	var messages []*models.Message
+	var oversized []*models.Message
+	add := func(size int) *models.Message {
+		message := &models.Message{
+			Data:         []byte(strings.Repeat("x", size)),
+			PartitionKey: fmt.Sprintf("message-%d", len(messages)),
+		}
+		messages = append(messages, message)
+		return message
+	}
+
+	// Enough small messages to reach the message count limit twice over
+	for i := range 2*limits.MaxBatchMessages + 1 {
+		add(1 + i%16)
+	}
+	// Enough large messages to reach the byte limit twice over, with oversized ones in between
+	large := min(limits.MaxMessageBytes, limits.MaxBatchBytes/3+1)
+	withOversized := limits.MaxMessageBytes < maxConformanceMessageBytes
+	if large > maxConformanceMessageBytes {
+		t.Logf("max_batch_bytes %d is too large to be reached, only small messages are batched", limits.MaxBatchBytes)
+	} else {
+		for i := range 7 {
+			add(large)
+			if withOversized && i%3 == 0 {
+				oversized = append(oversized, add(limits.MaxMessageBytes+1))
+			}
+		}
+	}
+	if withOversized {
+		add(limits.MaxMessageBytes)
+	} else {
+		t.Logf("max_message_bytes %d is too large to be reached, no message is oversized", limits.MaxMessageBytes)
+	}
+
+	var batches [][]*models.Message
+	var returnedOversized []*models.Message
+	current := targetiface.CurrentBatch{Messages: []*models.Message{}}
+	for _, message := range messages {
+		var batch []*models.Message
+		var tooBig *models.Message
+		batch, current, tooBig = driver.Batcher(current, message)
+		if batch != nil {
+			batches = append(batches, batch)
+		}
+		if tooBig != nil {
+			returnedOversized = append(returnedOversized, tooBig)
+		}
+		checkBatchLimits(t, "current batch", current.Messages, limits)
+		assert.Equal(t, batchBytes(current.Messages), current.DataBytes, "current batch must count its bytes")
+	}
+	if len(current.Messages) > 0 {
+		batches = append(batches, current.Messages)
+	}
+
+	assert.ElementsMatch(t, oversized, returnedOversized, "only messages over max_message_bytes must be oversized")
+
+	counts := make(map[*models.Message]int, len(messages))
+	for _, batch := range batches {
+		checkBatchLimits(t, "batch", batch, limits)
+		for _, message := range batch {
+			counts[message]++
+		}
+	}
+	for _, message := range returnedOversized {
+		counts[message]++
+	}
+	for _, message := range messages {
+		assert.Equal(t, 1, counts[message], "message %s must come out of the batcher once", message.PartitionKey)
+	}
+}
+
+func checkBatchLimits(t *testing.T, name string, batch []*models.Message, limits targetiface.BatchingConfig) {
+	t.Helper()
+	assert.LessOrEqual(t, len(batch), limits.MaxBatchMessages, "%s exceeds max_batch_messages", name)
+	assert.LessOrEqual(t, batchBytes(batch), limits.MaxBatchBytes, "%s exceeds max_batch_bytes", name)
+	for _, message := range batch {
+		assert.LessOrEqual(t, len(message.Data), limits.MaxMessageBytes, "%s holds a message over max_message_bytes", name)
+	}
+}
+
+func batchBytes(batch []*models.Message) int {
+	total := 0
+	for _, message := range batch {
+		total += len(message.Data)
+	}
+	return total
+}
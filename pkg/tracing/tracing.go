@@ -0,0 +1,171 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+// TraceParentHeader is the W3C header carrying the trace context of an inbound message
+const TraceParentHeader = "traceparent"
+
+// Outcomes of a message, recorded on its trace
+const (
+	OutcomeSent     = "sent"
+	OutcomeFiltered = "filtered"
+	OutcomeInvalid  = "invalid"
+)
+
+// stopTimeout bounds how long Stop waits for buffered spans to be exported
+const stopTimeout = 5 * time.Second
+
+// Tracer records a trace for every message once it has settled, built from the timestamps the message collected on its way through the app.
+// A nil Tracer records nothing, so callers don't need to check whether tracing is enabled.
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// NewOTLPExporter creates an exporter sending spans to an OTLP collector over HTTP
+func NewOTLPExporter(endpoint string, headers map[string]string) (sdktrace.SpanExporter, error) {
+	exporter, err := otlptracehttp.New(context.Background(),
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(headers),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to create OTLP trace exporter")
+	}
+	return exporter, nil
+}
+
+// NewTracer creates a Tracer exporting to the given exporter.
+// Messages carrying a sampled trace parent are always traced, the others are sampled at sampleRatio.
+func NewTracer(exporter sdktrace.SpanExporter, serviceName string, serviceVersion string, sampleRatio float64) *Tracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.version", serviceVersion),
+		)),
+	)
+
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer("github.com/snowplow/snowbridge/v5/pkg/tracing"),
+	}
+}
+
+// Stop exports the spans which are still buffered
+func (t *Tracer) Stop() error {
+	if t == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return t.provider.Shutdown(ctx)
+}
+
+// Record emits the traces of messages which reached the given outcome on the named target
+func (t *Tracer) Record(targetName string, outcome string, messages []*models.Message) {
+	if t == nil {
+		return
+	}
+	for _, msg := range messages {
+		t.record(targetName, outcome, msg)
+	}
+}
+
+// record emits the trace of a single message: a root span covering its whole life in the app,
+// with children for the source pull, each transformation step, batching and the target request
+func (t *Tracer) record(targetName string, outcome string, msg *models.Message) {
+	parent := context.Background()
+	if msg.TraceParent != "" {
+		parent = propagation.TraceContext{}.Extract(parent, propagation.MapCarrier{TraceParentHeader: msg.TraceParent})
+	}
+
+	created := msg.TimePulled
+	if !msg.TimeCreated.IsZero() && msg.TimeCreated.Before(created) {
+		created = msg.TimeCreated
+	}
+	finished := msg.TimeRequestFinished
+	if finished.IsZero() {
+		finished = time.Now().UTC()
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("snowbridge.outcome", outcome),
+		attribute.String("snowbridge.partition_key", msg.PartitionKey),
+		attribute.Int("snowbridge.message_bytes", len(msg.Data)),
+	}
+	if targetName != "" {
+		attrs = append(attrs, attribute.String("snowbridge.target", targetName))
+	}
+
+	ctx, root := t.tracer.Start(parent, "message",
+		trace.WithTimestamp(created),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attrs...),
+	)
+	if !root.IsRecording() {
+		return
+	}
+
+	t.span(ctx, "source.pull", created, msg.TimePulled)
+
+	if !msg.TimeTransformationStarted.IsZero() {
+		transformFinished := msg.TimeTransformed
+		if transformFinished.IsZero() && len(msg.TransformationSteps) > 0 {
+			transformFinished = msg.TransformationSteps[len(msg.TransformationSteps)-1].Finished
+		}
+		transformCtx, transform := t.tracer.Start(ctx, "transform", trace.WithTimestamp(msg.TimeTransformationStarted))
+		for _, step := range msg.TransformationSteps {
+			t.span(transformCtx, "transform."+step.Name, step.Started, step.Finished)
+		}
+		transform.End(trace.WithTimestamp(transformFinished))
+	}
+
+	if !msg.TimeRequestStarted.IsZero() {
+		batched := msg.TimeTransformed
+		if batched.IsZero() {
+			batched = msg.TimePulled
+		}
+		t.span(ctx, "batch", batched, msg.TimeRequestStarted)
+		t.span(ctx, "target.request", msg.TimeRequestStarted, msg.TimeRequestFinished, trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	if err := msg.GetError(); err != nil {
+		root.SetStatus(codes.Error, err.Error())
+	}
+	root.End(trace.WithTimestamp(finished))
+}
+
+// span emits a child span between two timestamps, skipping the ones which were never recorded
+func (t *Tracer) span(ctx context.Context, name string, started time.Time, finished time.Time, opts ...trace.SpanStartOption) {
+	if started.IsZero() || finished.IsZero() {
+		return
+	}
+	_, s := t.tracer.Start(ctx, name, append(opts, trace.WithTimestamp(started))...)
+	s.End(trace.WithTimestamp(finished))
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+)
+
+func spansByName(spans tracetest.SpanStubs) map[string]tracetest.SpanStub {
+	byName := make(map[string]tracetest.SpanStub)
+	for _, s := range spans {
+		byName[s.Name] = s
+	}
+	return byName
+}
+
+func TestTracer_Record(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewTracer(exporter, "snowbridge", "1.0.0", 1)
+
+	pulled := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	msg := &models.Message{
+		Data:                      []byte("data"),
+		PartitionKey:              "pk",
+		TimeCreated:               pulled.Add(-time.Second),
+		TimePulled:                pulled,
+		TimeTransformationStarted: pulled.Add(10 * time.Millisecond),
+		TimeTransformed:           pulled.Add(30 * time.Millisecond),
+		TimeRequestStarted:        pulled.Add(100 * time.Millisecond),
+		TimeRequestFinished:       pulled.Add(150 * time.Millisecond),
+		TraceParent:               "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		TransformationSteps: []models.TransformationStep{
+			{Name: "jq", Started: pulled.Add(10 * time.Millisecond), Finished: pulled.Add(20 * time.Millisecond)},
+			{Name: "js", Started: pulled.Add(20 * time.Millisecond), Finished: pulled.Add(30 * time.Millisecond)},
+		},
+	}
+
+	tracer.Record("primary", OutcomeSent, []*models.Message{msg})
+	require.NoError(t, tracer.provider.ForceFlush(context.Background()))
+
+	spans := spansByName(exporter.GetSpans())
+	assert.Len(spans, 7)
+
+	root := spans["message"]
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", root.SpanContext.TraceID().String())
+	assert.Equal("00f067aa0ba902b7", root.Parent.SpanID().String())
+	assert.True(root.Parent.IsRemote())
+	assert.Equal(msg.TimeCreated, root.StartTime)
+	assert.Equal(msg.TimeRequestFinished, root.EndTime)
+	assert.Equal(trace.SpanKindConsumer, root.SpanKind)
+	assert.Equal(codes.Unset, root.Status.Code)
+
+	assert.Equal(root.SpanContext.SpanID(), spans["source.pull"].Parent.SpanID())
+	assert.Equal(msg.TimePulled, spans["source.pull"].EndTime)
+
+	transform := spans["transform"]
+	assert.Equal(root.SpanContext.SpanID(), transform.Parent.SpanID())
+	assert.Equal(msg.TimeTransformed, transform.EndTime)
+	assert.Equal(transform.SpanContext.SpanID(), spans["transform.jq"].Parent.SpanID())
+	assert.Equal(transform.SpanContext.SpanID(), spans["transform.js"].Parent.SpanID())
+	assert.Equal(msg.TransformationSteps[1].Finished, spans["transform.js"].EndTime)
+
+	assert.Equal(msg.TimeTransformed, spans["batch"].StartTime)
+	assert.Equal(msg.TimeRequestStarted, spans["batch"].EndTime)
+
+	request := spans["target.request"]
+	assert.Equal(trace.SpanKindClient, request.SpanKind)
+	assert.Equal(msg.TimeRequestStarted, request.StartTime)
+	assert.Equal(msg.TimeRequestFinished, request.EndTime)
+}
+
+func TestTracer_Record_WithoutParentOrTransformations(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewTracer(exporter, "snowbridge", "1.0.0", 1)
+
+	msg := &models.Message{
+		Data:       []byte("data"),
+		TimePulled: time.Now().UTC(),
+	}
+	msg.SetError(errors.New("failure"))
+
+	tracer.Record("", OutcomeInvalid, []*models.Message{msg})
+	require.NoError(t, tracer.provider.ForceFlush(context.Background()))
+
+	spans := spansByName(exporter.GetSpans())
+	assert.Len(spans, 2)
+	assert.False(spans["message"].Parent.IsValid())
+	assert.Equal(codes.Error, spans["message"].Status.Code)
+	assert.Equal("failure", spans["message"].Status.Description)
+	assert.Contains(spans, "source.pull")
+}
+
+func TestTracer_Record_Sampling(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewTracer(exporter, "snowbridge", "1.0.0", 0)
+
+	sampled := &models.Message{TimePulled: time.Now().UTC(), TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	unsampled := &models.Message{TimePulled: time.Now().UTC()}
+
+	tracer.Record("primary", OutcomeSent, []*models.Message{sampled, unsampled})
+	require.NoError(t, tracer.provider.ForceFlush(context.Background()))
+
+	// Only the message whose parent was sampled upstream is traced
+	spans := exporter.GetSpans()
+	assert.Len(spans, 2)
+	for _, s := range spans {
+		assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", s.SpanContext.TraceID().String())
+	}
+}
+
+func TestTracer_Stop(t *testing.T) {
+	assert := assert.New(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := NewTracer(exporter, "snowbridge", "1.0.0", 1)
+	assert.Nil(tracer.Stop())
+
+	// Spans are no longer recorded once stopped
+	tracer.Record("primary", OutcomeSent, []*models.Message{{TimePulled: time.Now().UTC()}})
+	assert.Empty(exporter.GetSpans())
+}
+
+func TestTracer_Nil(t *testing.T) {
+	var tracer *Tracer
+	tracer.Record("primary", OutcomeSent, []*models.Message{{TimePulled: time.Now().UTC()}})
+	assert.Nil(t, tracer.Stop())
+}
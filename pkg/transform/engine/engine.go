@@ -46,4 +46,5 @@ type engineProtocol struct {
 	PartitionKey string
 	Data         any
 	HTTPHeaders  map[string]string
+	Route        string
 }
@@ -39,7 +39,7 @@ type JSEngineConfig struct {
 	SpMode         bool   `hcl:"snowplow_mode,optional"`
 	JsonMode       bool   `hcl:"json_mode,optional"`
 	RemoveNulls    bool   `hcl:"remove_nulls,optional"`
-	HashSaltSecret string `hcl:"hash_salt_secret,optional"`
+	HashSaltSecret string `hcl:"hash_salt_secret,optional" sensitive:"true"`
 }
 
 // JSEngine handles the provision of a JavaScript runtime to run transformations.
@@ -272,6 +272,11 @@ func (e *JSEngine) MakeFunction(funcName string) transform.TransformationFunctio
 			message.HTTPHeaders = protocol.HTTPHeaders
 		}
 
+		// setting the target route if needed
+		if protocol.Route != "" {
+			message.Route = protocol.Route
+		}
+
 		return message, nil, nil, protocol
 	}
 }
@@ -433,6 +438,7 @@ func mkJSEngineInput(e *JSEngine, message *models.Message, interState any) (*eng
 	candidate := &engineProtocol{
 		Data:        string(message.Data),
 		HTTPHeaders: message.HTTPHeaders,
+		Route:       message.Route,
 	}
 
 	if e.JsonMode {
@@ -2746,6 +2746,70 @@ function main(x) {
 	}
 }
 
+func TestJSEngineMakeFunction_Route(t *testing.T) {
+	testCases := []struct {
+		Scenario string
+		Src      string
+		InputMsg *models.Message
+		Expected *models.Message
+	}{
+		{
+			Scenario: "route_set",
+			Src: `
+function main(x) {
+  x.Route = 'pageviews';
+  return x;
+}
+`,
+			InputMsg: &models.Message{
+				Data:         []byte("asdf"),
+				PartitionKey: "pk",
+			},
+			Expected: &models.Message{
+				Data:         []byte("asdf"),
+				PartitionKey: "pk",
+				Route:        "pageviews",
+			},
+		},
+		{
+			Scenario: "route_unset_keeps_previous",
+			Src: `
+function main(x) {
+  return x;
+}
+`,
+			InputMsg: &models.Message{
+				Data:         []byte("asdf"),
+				PartitionKey: "pk",
+				Route:        "events",
+			},
+			Expected: &models.Message{
+				Data:         []byte("asdf"),
+				PartitionKey: "pk",
+				Route:        "events",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Scenario, func(t *testing.T) {
+			assert := assert.New(t)
+
+			jsEngine, err := NewJSEngine(&JSEngineConfig{RunTimeout: 5}, tt.Src)
+			if err != nil {
+				t.Fatalf("function NewJSEngine failed with error: %q", err.Error())
+			}
+
+			transFunction := jsEngine.MakeFunction("main")
+			s, f, e, _ := transFunction(tt.InputMsg, nil)
+
+			assert.Nil(f)
+			assert.Nil(e)
+			assertMessagesCompareJs(t, s, tt.Expected, false)
+		})
+	}
+}
+
 func TestJSEngineSmokeTest(t *testing.T) {
 	testCases := []struct {
 		Src          string
@@ -2977,8 +3041,9 @@ func assertMessagesCompareJs(t *testing.T, act, exp *models.Message, isJSON bool
 		tTimeOk := reflect.DeepEqual(act.TimeTransformed, exp.TimeTransformed)
 		ackOk := reflect.DeepEqual(act.AckFunc, exp.AckFunc)
 		headersOk = reflect.DeepEqual(act.HTTPHeaders, exp.HTTPHeaders)
+		routeOk := act.Route == exp.Route
 
-		if pkOk && dataOk && cTimeOk && pTimeOk && tTimeOk && ackOk && headersOk {
+		if pkOk && dataOk && cTimeOk && pTimeOk && tTimeOk && ackOk && headersOk && routeOk {
 			ok = true
 		}
 	}
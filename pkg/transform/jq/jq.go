@@ -27,6 +27,7 @@ type JQMapperConfig struct {
 	JQCommand    string `hcl:"jq_command"`
 	RunTimeoutMs int    `hcl:"timeout_ms,optional"`
 	SpMode       bool   `hcl:"snowplow_mode,optional"`
+	RouteCommand string `hcl:"route_command,optional"`
 }
 
 // JQMapperConfigPair is a configuration pair for the jq mapper transformation
@@ -37,7 +38,29 @@ var JQMapperConfigPair = config.ConfigurationPair{
 
 // jqMapperConfigFunction returns a jq mapper transformation function from a JQMapperConfig
 func jqMapperConfigFunction(c *JQMapperConfig) (transform.TransformationFunction, error) {
-	return GojqTransformationFunction(c.JQCommand, c.RunTimeoutMs, c.SpMode, transformOutput)
+	mapper, err := GojqTransformationFunction(c.JQCommand, c.RunTimeoutMs, c.SpMode, transformOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RouteCommand == "" {
+		return mapper, nil
+	}
+
+	router, err := GojqTransformationFunction(c.RouteCommand, c.RunTimeoutMs, c.SpMode, routeOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	// The route is decided on the input data, before it gets mapped
+	return func(message *models.Message, interState any) (*models.Message, *models.Message, *models.Message, any) {
+		routed, _, failure, interState := router(message, interState)
+		if failure != nil {
+			return nil, nil, failure, nil
+		}
+
+		return mapper(routed, interState)
+	}, nil
 }
 
 func transformOutput(jqOutput JqCommandOutput) transform.TransformationFunction {
@@ -0,0 +1,87 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package transform
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/snowplow/snowbridge/v5/config"
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+
+	"github.com/snowplow/snowbridge/v5/pkg/transform"
+)
+
+// JQRouteConfig represents the configuration for the JQ route transformation
+type JQRouteConfig struct {
+	JQCommand    string `hcl:"jq_command"`
+	RunTimeoutMs int    `hcl:"timeout_ms,optional"`
+	SpMode       bool   `hcl:"snowplow_mode,optional"`
+}
+
+// JQRouteConfigPair is a configuration pair for the jq route transformation
+var JQRouteConfigPair = config.ConfigurationPair{
+	Name:   "jqRoute",
+	Handle: jqRouteAdapterGenerator(jqRouteConfigFunction),
+}
+
+// jqRouteConfigFunction returns a jq route transformation function from a JQRouteConfig
+func jqRouteConfigFunction(c *JQRouteConfig) (transform.TransformationFunction, error) {
+	return GojqTransformationFunction(c.JQCommand, c.RunTimeoutMs, c.SpMode, routeOutput)
+}
+
+// routeOutput sets the route of the message to the name of the target returned by the jq command.
+// A null output leaves the route untouched.
+func routeOutput(jqOutput JqCommandOutput) transform.TransformationFunction {
+	return func(message *models.Message, interState any) (*models.Message, *models.Message, *models.Message, any) {
+		switch route := jqOutput.(type) {
+		case nil:
+		case string:
+			message.Route = route
+		default:
+			message.SetError(&models.TransformationError{
+				SafeMessage: "jq route didn't return expected [string] value",
+				Err:         fmt.Errorf("%v", jqOutput),
+			})
+			return nil, nil, message, nil
+		}
+
+		return message, nil, nil, interState
+	}
+}
+
+// jqRouteAdapterGenerator returns a jqRouteAdapter
+func jqRouteAdapterGenerator(f func(c *JQRouteConfig) (transform.TransformationFunction, error)) jqRouteAdapter {
+	return func(i any) (any, error) {
+		cfg, ok := i.(*JQRouteConfig)
+		if !ok {
+			return nil, errors.New("invalid input, expected JQRouteConfig")
+		}
+
+		return f(cfg)
+	}
+}
+
+// jqRouteAdapter implements the Pluggable interface
+type jqRouteAdapter func(i any) (any, error)
+
+// ProvideDefault implements the ComponentConfigurable interface
+func (f jqRouteAdapter) ProvideDefault() (any, error) {
+	return &JQRouteConfig{
+		RunTimeoutMs: 100,
+	}, nil
+}
+
+// Create implements the ComponentCreator interface
+func (f jqRouteAdapter) Create(i any) (any, error) {
+	return f(i)
+}
@@ -0,0 +1,125 @@
+/**
+ * Copyright (c) 2020-present Snowplow Analytics Ltd.
+ * All rights reserved.
+ *
+ * This software is made available by Snowplow Analytics, Ltd.,
+ * under the terms of the Snowplow Limited Use License Agreement, Version 1.1
+ * located at https://docs.snowplow.io/limited-use-license-1.1
+ * BY INSTALLING, DOWNLOADING, ACCESSING, USING OR DISTRIBUTING ANY PORTION
+ * OF THE SOFTWARE, YOU AGREE TO THE TERMS OF SUCH LICENSE AGREEMENT.
+ */
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snowplow/snowbridge/v5/pkg/models"
+	"github.com/snowplow/snowbridge/v5/pkg/transform"
+)
+
+func TestJQRoute_SpMode_true(t *testing.T) {
+	assert := assert.New(t)
+	input := &models.Message{
+		Data:         transform.SnowplowTsv1,
+		PartitionKey: "some-key",
+	}
+
+	config := &JQRouteConfig{JQCommand: `.app_id`, RunTimeoutMs: 100, SpMode: true}
+	route := createRoute(t, config)
+
+	routed, dropped, invalid, _ := route(input, nil)
+	assert.Empty(dropped)
+	assert.Empty(invalid)
+	assert.Equal("test-data1", routed.Route)
+	assert.Equal(string(transform.SnowplowTsv1), string(routed.Data))
+}
+
+func TestJQRoute_SpMode_false(t *testing.T) {
+	assert := assert.New(t)
+	input := &models.Message{
+		Data:         transform.SnowplowJSON1,
+		PartitionKey: "some-key",
+	}
+
+	config := &JQRouteConfig{JQCommand: `if .app_id == "test-data1" then "first" else "second" end`, RunTimeoutMs: 100}
+	route := createRoute(t, config)
+
+	routed, dropped, invalid, _ := route(input, nil)
+	assert.Empty(dropped)
+	assert.Empty(invalid)
+	assert.Equal("first", routed.Route)
+}
+
+func TestJQRoute_null_output(t *testing.T) {
+	assert := assert.New(t)
+	input := &models.Message{
+		Data:         transform.SnowplowJSON1,
+		PartitionKey: "some-key",
+		Route:        "previous",
+	}
+
+	config := &JQRouteConfig{JQCommand: `.non_existent_key`, RunTimeoutMs: 100}
+	route := createRoute(t, config)
+
+	routed, dropped, invalid, _ := route(input, nil)
+	assert.Empty(dropped)
+	assert.Empty(invalid)
+	assert.Equal("previous", routed.Route)
+}
+
+func TestJQRoute_non_string_output(t *testing.T) {
+	assert := assert.New(t)
+	input := &models.Message{
+		Data:         transform.SnowplowTsv1,
+		PartitionKey: "some-key",
+	}
+
+	config := &JQRouteConfig{JQCommand: `.collector_tstamp | epoch`, RunTimeoutMs: 100, SpMode: true}
+	route := createRoute(t, config)
+
+	routed, dropped, invalid, _ := route(input, nil)
+	assert.Empty(routed)
+	assert.Empty(dropped)
+	assert.Equal("jq route didn't return expected [string] value: 1557499235", invalid.GetError().Error())
+}
+
+func TestJQRoute_invalid_jq_command(t *testing.T) {
+	assert := assert.New(t)
+
+	config := &JQRouteConfig{JQCommand: `blabla`, RunTimeoutMs: 100}
+	route, err := jqRouteConfigFunction(config)
+
+	assert.Nil(route)
+	assert.Equal("error compiling jq query: function not defined: blabla/0", err.Error())
+}
+
+func TestJQMapper_RouteCommand(t *testing.T) {
+	assert := assert.New(t)
+	input := &models.Message{
+		Data:         transform.SnowplowJSON1,
+		PartitionKey: "some-key",
+	}
+
+	config := &JQMapperConfig{JQCommand: `{ id: .app_id }`, RouteCommand: `.app_id`, RunTimeoutMs: 100}
+	mapper, err := jqMapperConfigFunction(config)
+	if err != nil {
+		t.Fatalf("failed to create transformation function with error: %q", err.Error())
+	}
+
+	mapped, dropped, invalid, _ := mapper(input, nil)
+	assert.Empty(dropped)
+	assert.Empty(invalid)
+	assert.Equal("test-data1", mapped.Route)
+	assert.JSONEq(`{"id":"test-data1"}`, string(mapped.Data))
+}
+
+func createRoute(t *testing.T, config *JQRouteConfig) transform.TransformationFunction {
+	route, err := jqRouteConfigFunction(config)
+	if err != nil {
+		t.Fatalf("failed to create transformation function with error: %q", err.Error())
+	}
+	return route
+}
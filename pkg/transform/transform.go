@@ -72,3 +72,21 @@ func NewTransformation(transformFunctions ...TransformationFunction) Transformat
 		return models.NewTransformationResult(transformedMsg, filteredMsg, failureMsg)
 	}
 }
+
+// WithStepTiming wraps a transformation so that it records when it started and finished on the message it returns, to trace each step.
+func WithStepTiming(name string, f TransformationFunction) TransformationFunction {
+	return func(message *models.Message, intermediateState any) (*models.Message, *models.Message, *models.Message, any) {
+		started := time.Now().UTC()
+		steps := message.TransformationSteps
+
+		transformed, filtered, failure, intermediate := f(message, intermediateState)
+
+		step := models.TransformationStep{Name: name, Started: started, Finished: time.Now().UTC()}
+		for _, out := range []*models.Message{transformed, filtered, failure} {
+			if out != nil {
+				out.TransformationSteps = append(steps[:len(steps):len(steps)], step)
+			}
+		}
+		return transformed, filtered, failure, intermediate
+	}
+}
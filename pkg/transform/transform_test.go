@@ -155,3 +155,33 @@ func Benchmark_Transform_Passthrough(b *testing.B) {
 		transformPassthrough(msg)
 	}
 }
+
+func TestWithStepTiming(t *testing.T) {
+	assert := assert.New(t)
+
+	failing := func(message *models.Message, intermediateState any) (*models.Message, *models.Message, *models.Message, any) {
+		return nil, nil, message, nil
+	}
+	transformation := transform.NewTransformation(
+		transform.WithStepTiming("first", testfunc),
+		transform.WithStepTiming("second", testfunc),
+	)
+
+	result := transformation(&models.Message{Data: []byte("data")})
+	assert.NotNil(result.Transformed)
+	steps := result.Transformed.TransformationSteps
+	if assert.Len(steps, 2) {
+		assert.Equal("first", steps[0].Name)
+		assert.Equal("second", steps[1].Name)
+		assert.False(steps[0].Started.After(steps[0].Finished))
+		assert.False(steps[0].Finished.After(steps[1].Started))
+		assert.False(steps[1].Finished.After(result.Transformed.TimeTransformed))
+	}
+
+	// The step which fails the message is recorded on it too
+	result = transform.NewTransformation(transform.WithStepTiming("failing", failing))(&models.Message{Data: []byte("data")})
+	assert.NotNil(result.Invalid)
+	if assert.Len(result.Invalid.TransformationSteps, 1) {
+		assert.Equal("failing", result.Invalid.TransformationSteps[0].Name)
+	}
+}
@@ -14,6 +14,8 @@ package transformconfig
 import (
 	"fmt"
 
+	"github.com/hashicorp/hcl/v2"
+
 	"github.com/snowplow/snowbridge/v5/config"
 	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/observer"
@@ -41,6 +43,7 @@ var SupportedTransformations = []config.ConfigurationPair{
 	base64.Base64EncodeConfigPair,
 	spgmtss.GTMSSPreviewConfigPair,
 	jq.JQMapperConfigPair,
+	jq.JQRouteConfigPair,
 	engine.JSConfigPair,
 }
 
@@ -50,7 +53,7 @@ func GetTransformations(c *config.Config, supportedTransformations []config.Conf
 	funcs := make([]transform.TransformationFunction, 0)
 
 	if c.Data.Metrics.E2ELatencyEnabled {
-		funcs = append(funcs, spcollector.CollectorTstampTransformation())
+		funcs = append(funcs, withTracing(c, "collectorTstamp", spcollector.CollectorTstampTransformation()))
 	}
 
 	if c.Data.Transform != nil {
@@ -76,13 +79,89 @@ func GetTransformations(c *config.Config, supportedTransformations []config.Conf
 			if !ok {
 				return nil, fmt.Errorf("could not interpret transformation configuration for %q", transformation.Name)
 			}
-			funcs = append(funcs, f)
+			funcs = append(funcs, withTracing(c, transformation.Name, f))
 		}
 	}
 
 	return transform.NewTransformation(funcs...), nil
 }
 
+// DecodeTransformationConfigs returns the decoded configuration of each configured transformation, with its defaults filled in
+func DecodeTransformationConfigs(c *config.Config, supportedTransformations []config.ConfigurationPair) ([]any, error) {
+	var configs []any
+	if c.Data.Transform == nil {
+		return configs, nil
+	}
+
+	for _, transformation := range c.Data.Transform.Transformations {
+		var decoded any
+		for _, pair := range supportedTransformations {
+			if pair.Name == transformation.Name {
+				var err error
+				decoded, err = c.DecodeComponent(pair.Handle, &config.DecoderOptions{Input: transformation.Body})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+		if decoded == nil {
+			return nil, fmt.Errorf("could not interpret transformation configuration for %q", transformation.Name)
+		}
+		configs = append(configs, decoded)
+	}
+	return configs, nil
+}
+
+// TransformationDefaults returns the default configuration of every supported transformation, by name
+func TransformationDefaults(supportedTransformations []config.ConfigurationPair) (map[string]any, error) {
+	defaults := make(map[string]any, len(supportedTransformations))
+	for _, pair := range supportedTransformations {
+		cfg, err := pair.Handle.ProvideDefault()
+		if err != nil {
+			return nil, err
+		}
+		defaults[pair.Name] = cfg
+	}
+	return defaults, nil
+}
+
+// scriptPathConfig decodes only the script path of a js transformation
+type scriptPathConfig struct {
+	ScriptPath string   `hcl:"script_path,optional"`
+	Remain     hcl.Body `hcl:",remain"`
+}
+
+// ScriptPaths returns the paths of the scripts loaded by the configured js transformations
+func ScriptPaths(c *config.Config) ([]string, error) {
+	var paths []string
+	if c.Data.Transform == nil {
+		return paths, nil
+	}
+
+	for _, transformation := range c.Data.Transform.Transformations {
+		if transformation.Name != engine.JSConfigPair.Name {
+			continue
+		}
+
+		var cfg scriptPathConfig
+		if err := c.Decoder.Decode(&config.DecoderOptions{Input: transformation.Body}, &cfg); err != nil {
+			return nil, err
+		}
+		if cfg.ScriptPath != "" {
+			paths = append(paths, cfg.ScriptPath)
+		}
+	}
+	return paths, nil
+}
+
+// withTracing records the timing of the transformation on each message when tracing is enabled
+func withTracing(c *config.Config, name string, f transform.TransformationFunction) transform.TransformationFunction {
+	if c.Data.Tracing == nil || !c.Data.Tracing.Enabled {
+		return f
+	}
+	return transform.WithStepTiming(name, f)
+}
+
 // GetTransformer builds and returns a complete Transformer with all channels configured, along with the output channel for the router to read from.
 func GetTransformer(
 	c *config.Config,
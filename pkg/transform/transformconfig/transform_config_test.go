@@ -70,6 +70,34 @@ func TestGetTransformations(t *testing.T) {
 	}
 }
 
+func TestScriptPaths(t *testing.T) {
+	configPath := filepath.Join(assets.AssetsRootDir, "test", "transformconfig", "TestGetTransformations", "configs")
+	jsScriptPath := filepath.Join(assets.AssetsRootDir, "test", "transformconfig", "TestGetTransformations", "scripts", "script.js")
+	t.Setenv("JS_SCRIPT_PATH", jsScriptPath)
+
+	testCases := []struct {
+		File     string
+		Expected []string
+	}{
+		{File: "js.hcl", Expected: []string{jsScriptPath}},
+		{File: "jq.hcl", Expected: nil},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.File, func(t *testing.T) {
+			assert := assert.New(t)
+			t.Setenv("SNOWBRIDGE_CONFIG_FILE", filepath.Join(configPath, tt.File))
+
+			c, err := config.NewConfig()
+			assert.Nil(err)
+
+			paths, err := ScriptPaths(c)
+			assert.Nil(err)
+			assert.Equal(tt.Expected, paths)
+		})
+	}
+}
+
 func TestEnginesAndTransformations(t *testing.T) {
 	var messageJSCompileErr = &models.Message{
 		Data:         snowplowJSON1,
@@ -609,3 +637,12 @@ var (
 	// snowplowJSON1 with sha1 salt hash transformations applied
 	snowplowJSON1Sha1SaltHashed = []byte(`{"app_id":"5841e55de6c4486fa092f044a5189570dec421cb06652829","collector_tstamp":"2019-05-10T14:40:35.972Z","contexts_nl_basjes_yauaa_context_1":[{"agentClass":"Special","agentName":"python-requests","agentNameVersion":"python-requests 2.21.0","agentNameVersionMajor":"python-requests 2","agentVersion":"2.21.0","agentVersionMajor":"2","deviceBrand":"Unknown","deviceClass":"Unknown","deviceName":"Unknown","layoutEngineClass":"Unknown","layoutEngineName":"Unknown","layoutEngineVersion":"??","layoutEngineVersionMajor":"??","operatingSystemClass":"Unknown","operatingSystemName":"Unknown","operatingSystemVersion":"??"}],"derived_tstamp":"2019-05-10T14:40:35.972Z","dvce_created_tstamp":"2019-05-10T14:40:35.551Z","dvce_sent_tstamp":"2019-05-10T14:40:35Z","etl_tstamp":"2019-05-10T14:40:37.436Z","event":"unstruct","event_format":"jsonschema","event_id":"e9234345-f042-46ad-b1aa-424464066a33","event_name":"add_to_cart","event_vendor":"com.snowplowanalytics.snowplow","event_version":"1-0-0","network_userid":"d26822f5-52cc-4292-8f77-14ef6b7a27e2","platform":"pc","unstruct_event_com_snowplowanalytics_snowplow_add_to_cart_1":{"currency":"GBP","quantity":2,"sku":"item41","unitPrice":32.4},"user_id":"user<built-in function input>","user_ipaddress":"18.194.133.57","useragent":"python-requests/2.21.0","v_collector":"ssc-0.15.0-googlepubsub","v_etl":"beam-enrich-0.2.0-common-0.36.0","v_tracker":"py-0.8.2"}`)
 )
+
+func TestTransformationDefaults(t *testing.T) {
+	assert := assert.New(t)
+
+	defaults, err := TransformationDefaults(SupportedTransformations)
+	assert.NoError(err)
+	assert.Len(defaults, len(SupportedTransformations))
+	assert.Contains(defaults, "jq")
+}
@@ -3,6 +3,7 @@ package transform
 import (
 	"runtime"
 	"sync"
+	"sync/atomic"
 
 	"github.com/snowplow/snowbridge/v5/pkg/models"
 	"github.com/snowplow/snowbridge/v5/pkg/observer"
@@ -11,7 +12,7 @@ import (
 )
 
 type Transformer struct {
-	transformFunction transform.TransformationApplyFunction
+	transformFunction atomic.Pointer[transform.TransformationApplyFunction]
 	input             <-chan *models.Message
 	output            chan<- *models.TransformationResult
 	observer          *observer.Observer
@@ -24,13 +25,20 @@ func NewTransformer(
 	output chan<- *models.TransformationResult,
 	observer *observer.Observer,
 	workerPool int) *Transformer {
-	return &Transformer{
-		transformFunction: transformFunction,
-		input:             input,
-		output:            output,
-		observer:          observer,
-		workerPool:        workerPool,
+	t := &Transformer{
+		input:      input,
+		output:     output,
+		observer:   observer,
+		workerPool: workerPool,
 	}
+	t.transformFunction.Store(&transformFunction)
+	return t
+}
+
+// SetTransformFunction swaps the transformations applied to the messages which are not transformed yet.
+// It is safe to call while the transformer is running.
+func (t *Transformer) SetTransformFunction(transformFunction transform.TransformationApplyFunction) {
+	t.transformFunction.Store(&transformFunction)
 }
 
 func (t *Transformer) Start() {
@@ -56,7 +64,7 @@ func (t *Transformer) Start() {
 			// Input channel is a way for transformer worker to backpressure/throttle source.
 			for msg := range t.input {
 				// Do some work...
-				transformed := t.transformFunction(msg)
+				transformed := (*t.transformFunction.Load())(msg)
 
 				// Send to output channel. This output channel is then later consumed by targets
 				// Output channel is a way for targets to backpressure/throttle transformer workers
@@ -138,6 +138,36 @@ func TestTransformer_MultipleWorkers(t *testing.T) {
 	assert.True(t, len(buffers) >= 1, "Observer should have flushed at least one metrics buffer")
 }
 
+// TestTransformer_SetTransformFunction verifies that messages pulled after a swap use the new function
+func TestTransformer_SetTransformFunction(t *testing.T) {
+	input := make(chan *models.Message)
+	output := make(chan *models.TransformationResult)
+
+	withData := func(data string) func(*models.Message) *models.TransformationResult {
+		return func(msg *models.Message) *models.TransformationResult {
+			msg.Data = []byte(data)
+			return models.NewTransformationResult(msg, nil, nil)
+		}
+	}
+
+	obs := observer.New(nil, 10*time.Second, nil)
+	transformer := NewTransformer(withData("first"), input, output, obs, 1)
+
+	var wg sync.WaitGroup
+	wg.Go(transformer.Start)
+
+	input <- &models.Message{Data: []byte("data"), TimePulled: time.Now()}
+	assert.Equal(t, "first", string((<-output).Transformed.Data))
+
+	transformer.SetTransformFunction(withData("second"))
+
+	input <- &models.Message{Data: []byte("data"), TimePulled: time.Now()}
+	assert.Equal(t, "second", string((<-output).Transformed.Data))
+
+	close(input)
+	assert.True(t, waitWithTimeout(&wg))
+}
+
 func waitWithTimeout(wg *sync.WaitGroup) bool {
 	done := make(chan struct{})
 	go func() {